@@ -6,7 +6,6 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 	"video-processor/services"
 	"video-processor/utils"
 )
@@ -24,18 +23,14 @@ func run() {
 		log.Println("✅ Arquivo .env carregado com sucesso")
 	}
 
-	// Configuração do processador de mensagens usando .env
-	config := services.MessageProcessorConfig{
-		SQSQueueURL:     utils.GetEnv("SQS_QUEUE_URL", "http://localhost:4566/000000000000/video-processing-queue"),
-		ResultsQueueURL: utils.GetEnv("RESULTS_QUEUE_URL", "http://localhost:4566/000000000000/video-results-queue"),
-		LocalStackURL:   utils.GetEnv("LOCALSTACK_URL", ""),
-		AWSRegion:       utils.GetEnv("AWS_REGION", "us-east-1"),
-		SourceBucket:    utils.GetEnv("SOURCE_BUCKET", "video-bucket"),
-		ResultsBucket:   utils.GetEnv("RESULTS_BUCKET", "video-results"),
-		PollingInterval: utils.GetEnvDuration("POLLING_INTERVAL_SECONDS", 5*time.Second),
-		MaxMessages:     int32(utils.GetEnvInt("MAX_MESSAGES", 10)),
+	// Diretórios de trabalho (UPLOADS_DIR, OUTPUTS_DIR, TEMP_DIR)
+	if err := services.InitScratchDirs(services.LoadScratchDirs()); err != nil {
+		log.Fatalf("❌ %v", err)
 	}
 
+	// Configuração do processador de mensagens usando .env
+	config := services.LoadMessageProcessorConfig()
+
 	// Criar processador
 	processor, err := services.NewMessageProcessor(config)
 	if err != nil {
@@ -46,6 +41,9 @@ func run() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Remover workspaces e arquivos órfãos de execuções interrompidas
+	services.StartWorkspaceJanitor(ctx, services.LoadWorkspaceJanitorConfig())
+
 	// Capturar sinais do sistema para shutdown graceful
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -58,7 +56,11 @@ func run() {
 	log.Println("🛑 Recebido sinal de shutdown, parando aplicação...")
 	cancel()
 
-	// Aguardar um tempo para finalização graceful
-	time.Sleep(2 * time.Second)
+	// Aguardar os jobs em andamento dentro do período de tolerância
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), config.ShutdownGracePeriod)
+	defer cancelShutdown()
+	if err := processor.Shutdown(shutdownCtx); err != nil {
+		log.Printf("⚠️ Encerrado com jobs pendentes: %v", err)
+	}
 	log.Println("👋 Aplicação finalizada")
 }
@@ -1,26 +1,84 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
-	"os"
-	"path/filepath"
+	"path"
+	"slices"
+	"strings"
+	"video-processor/services"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Armazenamento servido por /download (padrão: diretório local outputs/)
+var (
+	downloadStore  services.BlobStore = services.NewDirBlobStore("outputs")
+	downloadBucket                    = ""
+
+	// Com DOWNLOAD_BUCKET, /api/status lista o bucket do processador com URLs pré-assinadas
+	presignDownloads = false
+)
+
+// SetDownloadStore define o armazenamento e o bucket servidos por /download
+func SetDownloadStore(store services.BlobStore, bucket string) {
+	downloadStore = store
+	downloadBucket = bucket
+}
+
+// Chave do objeto pedido em /download/*filename; recusa caminhos absolutos e ".."
+func downloadKey(c *gin.Context) (string, bool) {
+	key := strings.TrimPrefix(c.Param("filename"), "/")
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) || slices.Contains(strings.Split(key, "/"), "..") {
+		return "", false
+	}
+	return key, true
+}
+
 func HandleDownload(c *gin.Context) {
-	filename := c.Param("filename")
-	filePath := filepath.Join("outputs", filename)
+	key, ok := downloadKey(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nome de arquivo inválido"})
+		return
+	}
 
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	body, info, err := downloadStore.Get(c, downloadBucket, key)
+	if errors.Is(err, services.ErrBlobNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Arquivo não encontrado"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Erro ao ler arquivo: " + err.Error()})
+		return
+	}
+	defer body.Close()
 
-	c.Header("Content-Description", "File Transfer")
-	c.Header("Content-Transfer-Encoding", "binary")
-	c.Header("Content-Disposition", "attachment; filename="+filename)
-	c.Header("Content-Type", "application/zip")
+	// Downloads de outputs/ definem a ordem de remoção da retenção
+	if !presignDownloads {
+		services.TouchOutput(key)
+	}
 
-	c.File(filePath)
+	// ZIPs enviados pelo processador já trazem tipo e nome de download
+	contentType := "application/zip"
+	if info.ContentType != "" {
+		contentType = info.ContentType
+	}
+	disposition := "attachment; filename=" + path.Base(key)
+	if info.ContentDisposition != "" {
+		disposition = info.ContentDisposition
+	}
+
+	c.DataFromReader(http.StatusOK, info.Size, contentType, body, map[string]string{
+		"Content-Description":       "File Transfer",
+		"Content-Transfer-Encoding": "binary",
+		"Content-Disposition":       disposition,
+	})
+}
+
+// TrackOutputDownload registra os downloads servidos pela rota estática /outputs
+func TrackOutputDownload(c *gin.Context) {
+	c.Next()
+	if c.Writer.Status() == http.StatusOK && c.Request.Method == http.MethodGet {
+		services.TouchOutput(c.Param("filepath"))
+	}
 }
@@ -1,10 +1,13 @@
 package controllers
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"video-processor/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -48,4 +51,103 @@ func TestHandleDownload_Sucesso(t *testing.T) {
 	if w.Body.Len() == 0 {
 		t.Error("Esperado corpo não vazio para download")
 	}
+	if _, ok := services.LastOutputDownload(filename); !ok {
+		t.Error("Esperado download registrado para a retenção")
+	}
+}
+
+func TestHandleDownload_ArmazenamentoConfigurado(t *testing.T) {
+	root := t.TempDir()
+	store := services.NewDirBlobStore(root)
+	store.Put(context.TODO(), "resultados", "proc-1_frames.zip", strings.NewReader("zip"), services.PutOptions{})
+	SetDownloadStore(store, "resultados")
+	defer SetDownloadStore(services.NewDirBlobStore("outputs"), "")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "filename", Value: "proc-1_frames.zip"}}
+	c.Request, _ = http.NewRequest("GET", "/download/proc-1_frames.zip", nil)
+
+	HandleDownload(c)
+
+	if w.Code != http.StatusOK || w.Body.String() != "zip" {
+		t.Errorf("Esperado 200 com o conteúdo do armazenamento, obtido %d '%s'", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleDownload_ChaveComPrefixo(t *testing.T) {
+	store := services.NewDirBlobStore(t.TempDir())
+	store.Put(context.TODO(), "resultados", "processed/proc-1.zip", strings.NewReader("zip"), services.PutOptions{})
+	SetDownloadStore(store, "resultados")
+	defer SetDownloadStore(services.NewDirBlobStore("outputs"), "")
+
+	r := gin.New()
+	r.GET("/download/*filename", HandleDownload)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/download/processed/proc-1.zip", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "zip" {
+		t.Errorf("Esperado 200 com o conteúdo de processed/proc-1.zip, obtido %d '%s'", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Disposition"); got != "attachment; filename=proc-1.zip" {
+		t.Errorf("Esperado nome do arquivo sem o prefixo, obtido '%s'", got)
+	}
+}
+
+func TestHandleDownload_ChaveInvalida(t *testing.T) {
+	for _, filename := range []string{"/../segredo.zip", "/processed/../../segredo.zip", "//etc/passwd", "/"} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = []gin.Param{{Key: "filename", Value: filename}}
+
+		HandleDownload(c)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Esperado status 400 para %q, obtido %d", filename, w.Code)
+		}
+	}
+}
+
+func TestHandleDownload_ContentDispositionDoObjeto(t *testing.T) {
+	store := services.NewDirBlobStore(t.TempDir())
+	opts := services.PutOptions{ContentType: "application/zip", ContentDisposition: `attachment; filename="aula-01.zip"`}
+	store.Put(context.TODO(), "resultados", "proc-1.zip", strings.NewReader("zip"), opts)
+	SetDownloadStore(store, "resultados")
+	defer SetDownloadStore(services.NewDirBlobStore("outputs"), "")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "filename", Value: "proc-1.zip"}}
+	c.Request, _ = http.NewRequest("GET", "/download/proc-1.zip", nil)
+
+	HandleDownload(c)
+
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="aula-01.zip"` {
+		t.Errorf("Esperado Content-Disposition do objeto, obtido '%s'", got)
+	}
+}
+
+func TestTrackOutputDownload_RotaEstatica(t *testing.T) {
+	filename := "arquivo_estatico.zip"
+	os.MkdirAll("outputs", 0755)
+	os.WriteFile("outputs/"+filename, []byte("conteudo"), 0644)
+	defer os.Remove("outputs/" + filename)
+
+	r := gin.New()
+	r.Group("/outputs", TrackOutputDownload).Static("/", "outputs")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/outputs/"+filename, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Esperado status 200, obtido %d", w.Code)
+	}
+	if _, ok := services.LastOutputDownload(filename); !ok {
+		t.Error("Esperado download pela rota estática registrado")
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/outputs/inexistente.zip", nil))
+	if _, ok := services.LastOutputDownload("inexistente.zip"); ok {
+		t.Error("Arquivo não encontrado não deveria contar como download")
+	}
 }
@@ -62,11 +62,17 @@ func GetHTMLForm() string {
                 showResult('Selecione um arquivo de vídeo!', 'error');
                 return;
             }
-            const formData = new FormData();
-            formData.append('video', file);
             showLoading(true);
             hideResult();
             try {
+                // Upload direto ao S3 quando disponível; senão, envio pelo servidor
+                const processId = await directUpload(file);
+                if (processId) {
+                    showResult('✅ Upload concluído! Processamento enfileirado (ProcessID: ' + processId + ').', 'success');
+                    return;
+                }
+                const formData = new FormData();
+                formData.append('video', file);
                 const response = await fetch('/upload', {
                     method: 'POST',
                     body: formData
@@ -84,6 +90,48 @@ func GetHTMLForm() string {
                 showLoading(false);
             }
         });
+        async function directUpload(file) {
+            const created = await fetch('/api/uploads', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ filename: file.name, contentType: file.type, size: file.size })
+            });
+            if (created.status === 501 || created.status === 503) {
+                return null;
+            }
+            const upload = await created.json();
+            if (!created.ok) {
+                throw new Error(upload.message);
+            }
+            const complete = { key: upload.key };
+            if (upload.method === 'MULTIPART') {
+                complete.uploadId = upload.uploadId;
+                complete.parts = [];
+                for (const part of upload.parts) {
+                    const start = (part.partNumber - 1) * upload.partSize;
+                    const sent = await fetch(part.url, { method: 'PUT', body: file.slice(start, start + upload.partSize) });
+                    if (!sent.ok) {
+                        throw new Error('falha no envio da parte ' + part.partNumber);
+                    }
+                    complete.parts.push({ partNumber: part.partNumber, etag: sent.headers.get('ETag') });
+                }
+            } else {
+                const sent = await fetch(upload.url, { method: 'PUT', headers: upload.headers || {}, body: file });
+                if (!sent.ok) {
+                    throw new Error('falha no envio ao S3 (' + sent.status + ')');
+                }
+            }
+            const confirmed = await fetch('/api/uploads/' + upload.processId + '/complete', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(complete)
+            });
+            const result = await confirmed.json();
+            if (!confirmed.ok) {
+                throw new Error(result.message);
+            }
+            return upload.processId;
+        }
         function showResult(message, type) {
             const result = document.getElementById('result');
             result.innerHTML = message;
@@ -102,7 +150,7 @@ func GetHTMLForm() string {
                 const data = await response.json();
                 const filesList = document.getElementById('filesList');
                 if (data.files && data.files.length > 0) {
-                    filesList.innerHTML = data.files.map(file => '<div class="file-item">' + '<span>' + file.filename + ' (' + formatFileSize(file.size) + ') - ' + file.created_at + '</span>' + '<a href="' + file.download_url + '" class="download-btn">⬇️ Download</a>' + '</div>').join('');
+                    filesList.innerHTML = data.files.map(file => '<div class="file-item">' + '<span>' + file.filename + ' (' + formatFileSize(file.size) + ') - ' + file.created_at + '</span>' + '<a href="' + (file.presigned_url || file.download_url) + '" class="download-btn">⬇️ Download</a>' + '</div>').join('');
                 } else {
                     filesList.innerHTML = '<p>Nenhum arquivo processado ainda.</p>';
                 }
@@ -74,3 +74,10 @@ func TestHandleHTML_FormCompleto(t *testing.T) {
 func containsHTML(s, substr string) bool {
 	return len(s) > 0 && len(substr) > 0 && (s == substr || len(s) > len(substr) && (s[0:len(substr)] == substr || containsHTML(s[1:], substr)))
 }
+
+func TestGetHTMLForm_UploadDireto(t *testing.T) {
+	html := GetHTMLForm()
+	if !containsHTML(html, "fetch('/api/uploads'") {
+		t.Error("Esperado upload direto via /api/uploads no HTML")
+	}
+}
@@ -2,7 +2,11 @@ package controllers
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 	"video-processor/services"
 	"video-processor/utils"
@@ -14,42 +18,52 @@ var messageProcessor MessageProcessorInterface
 
 type MessageProcessorInterface interface {
 	DownloadFromS3(ctx context.Context, bucket, key string) (string, error)
-	GetSourceBucket() string
+	ResolveTargets(videoMsg services.VideoProcessingMessage) (services.JobTargets, error)
 	StartProcessing(ctx context.Context)
 	UploadZipToS3(ctx context.Context, bucket, key, localZipPath string) error
 	SendProcessingResult(ctx context.Context, processID, zipKey, status string) error
+	Shutdown(ctx context.Context) error
+	QueueStats() []services.QueueStats
+	BlobStore() services.BlobStore
+	ReplayWebhooks(ctx context.Context) (delivered, failed int, err error)
+	CircuitBreakers() map[string]string
+	PresignDownload(ctx context.Context, bucket, key string) (string, time.Time, error)
+	CreateDirectUpload(ctx context.Context, req services.DirectUploadRequest) (services.DirectUpload, error)
+	CompleteDirectUpload(ctx context.Context, processID string, req services.CompleteUploadRequest) (bool, error)
 }
 
 // Inicializar o processador de mensagens (chamado no main.go)
-func InitMessageProcessor() error {
-	// Configuração para o processador de mensagens
-	config := services.MessageProcessorConfig{
-		SQSQueueURL:     utils.GetEnv("SQS_QUEUE_URL", "http://localhost:4566/000000000000/video-processing-queue"),
-		ResultsQueueURL: utils.GetEnv("RESULTS_QUEUE_URL", "http://localhost:4566/000000000000/video-results-queue"),
-		LocalStackURL:   utils.GetEnv("LOCALSTACK_URL", ""),
-		AWSRegion:       utils.GetEnv("AWS_REGION", "us-east-1"),
-		SourceBucket:    utils.GetEnv("SOURCE_BUCKET", "video-bucket"),
-		ResultsBucket:   utils.GetEnv("RESULTS_BUCKET", "video-results"),
-		PollingInterval: utils.GetEnvDuration("POLLING_INTERVAL_SECONDS", 5*time.Second),
-		MaxMessages:     int32(utils.GetEnvInt("MAX_MESSAGES", 10)),
-	}
-
-	var err error
+// O consumo é interrompido quando ctx é cancelado
+func InitMessageProcessor(ctx context.Context, config services.MessageProcessorConfig) error {
 	mp, err := services.NewMessageProcessor(config)
 	if err != nil {
 		return err
 	}
 	messageProcessor = mp
+	// Com DOWNLOAD_BUCKET, /download serve os ZIPs do armazenamento do processador
+	if bucket := utils.GetEnv("DOWNLOAD_BUCKET", ""); bucket != "" {
+		SetDownloadStore(mp.BlobStore(), bucket)
+		presignDownloads = true
+	}
 	// Iniciar processamento em background
-	go messageProcessor.StartProcessing(context.Background())
+	go messageProcessor.StartProcessing(ctx)
 	return nil
 }
 
+// Aguardar os jobs em andamento do processador de mensagens (chamado no shutdown)
+func ShutdownMessageProcessor(ctx context.Context) error {
+	if messageProcessor == nil {
+		return nil
+	}
+	return messageProcessor.Shutdown(ctx)
+}
+
 // Endpoint para processar uma mensagem específica (para testes)
 func HandleProcessMessage(c *gin.Context) {
 	var request struct {
-		FileID    string `json:"fileId" binding:"required"`
-		ProcessID string `json:"processId" binding:"required"`
+		FileID       string `json:"fileId" binding:"required"`
+		ProcessID    string `json:"processId" binding:"required"`
+		SourceBucket string `json:"sourceBucket"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -71,13 +85,30 @@ func HandleProcessMessage(c *gin.Context) {
 	// Simular mensagem SQS
 	// Criar mensagem para processamento
 	message := services.VideoProcessingMessage{
-		FileID:    request.FileID,
-		ProcessID: request.ProcessID,
+		FileID:       request.FileID,
+		ProcessID:    request.ProcessID,
+		SourceBucket: request.SourceBucket,
+	}
+
+	targets, err := messageProcessor.ResolveTargets(message)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
 	}
 
 	// Baixar e processar
 	ctx := context.Background()
-	localPath, err := messageProcessor.DownloadFromS3(ctx, messageProcessor.GetSourceBucket(), message.FileID)
+	localPath, err := messageProcessor.DownloadFromS3(ctx, targets.SourceBucket, message.FileID)
+	if errors.Is(err, services.ErrInsufficientDisk) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -86,8 +117,9 @@ func HandleProcessMessage(c *gin.Context) {
 		return
 	}
 
-	timestamp := time.Now().Format("20060102_150405")
-	result := services.ProcessVideo(localPath, timestamp)
+	defer os.Remove(localPath)
+
+	result := services.ProcessVideo(localPath, services.NewJobID())
 
 	c.JSON(http.StatusOK, result)
 }
@@ -100,6 +132,61 @@ func HandleMessageProcessorStatus(c *gin.Context) {
 		"localstack_url":   utils.GetEnv("LOCALSTACK_URL", "http://localhost:4566"),
 		"aws_region":       utils.GetEnv("AWS_REGION", "us-east-1"),
 	}
+	if messageProcessor != nil {
+		status["queues"] = messageProcessor.QueueStats()
+		status["circuit_breakers"] = messageProcessor.CircuitBreakers()
+	}
 
 	c.JSON(http.StatusOK, status)
 }
+
+// Endpoint para reenviar os webhooks que falharam definitivamente. Exige
+// Authorization: Bearer <WEBHOOK_REPLAY_TOKEN>; sem o token configurado, fica desabilitado
+func HandleReplayWebhooks(c *gin.Context) {
+	token := utils.GetEnv("WEBHOOK_REPLAY_TOKEN", "")
+	if token == "" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"message": "Reenvio de webhooks desabilitado: defina WEBHOOK_REPLAY_TOKEN",
+		})
+		return
+	}
+	given := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Token de reenvio inválido",
+		})
+		return
+	}
+
+	if messageProcessor == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"success": false,
+			"message": "Processador de mensagens não inicializado",
+		})
+		return
+	}
+
+	delivered, failed, err := messageProcessor.ReplayWebhooks(c.Request.Context())
+	if errors.Is(err, services.ErrReplayInProgress) {
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Erro ao reenviar webhooks: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   failed == 0,
+		"delivered": delivered,
+		"failed":    failed,
+	})
+}
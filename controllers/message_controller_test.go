@@ -3,9 +3,13 @@ package controllers
 import (
 	"bytes"
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+	"video-processor/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -38,6 +42,24 @@ func TestHandleProcessMessage_ServiceUnavailable(t *testing.T) {
 	}
 }
 
+func TestHandleProcessMessage_SemEspacoEmDisco(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/api/process-message", bytes.NewBuffer([]byte(`{"fileId":"abc","processId":"123"}`)))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	messageProcessor = &mockProcessor{downloadErr: &services.DiskSpaceError{Path: "uploads", Required: 2 << 30, Available: 1 << 30}}
+	defer func() { messageProcessor = nil }()
+	HandleProcessMessage(c)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Esperado status 503, obtido %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "espaço em disco insuficiente") {
+		t.Errorf("Esperado erro de espaço em disco, obtido %s", w.Body.String())
+	}
+}
+
 func TestHandleProcessMessage_DadosInvalidos(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -55,12 +77,25 @@ func TestHandleProcessMessage_DadosInvalidos(t *testing.T) {
 }
 
 // Mock para MessageProcessor
-type mockProcessor struct{}
+type mockProcessor struct {
+	circuits    map[string]string
+	downloadErr error
+	replayErr   error
+}
 
 func (m *mockProcessor) DownloadFromS3(ctx context.Context, bucket, key string) (string, error) {
+	if m.downloadErr != nil {
+		return "", m.downloadErr
+	}
 	return "video.mp4", nil
 }
 func (m *mockProcessor) GetSourceBucket() string             { return "bucket" }
+func (m *mockProcessor) ResolveTargets(videoMsg services.VideoProcessingMessage) (services.JobTargets, error) {
+	if videoMsg.SourceBucket != "" && videoMsg.SourceBucket != "bucket" {
+		return services.JobTargets{}, errors.New("bucket de origem não permitido")
+	}
+	return services.JobTargets{SourceBucket: "bucket"}, nil
+}
 func (m *mockProcessor) StartProcessing(ctx context.Context) {}
 func (m *mockProcessor) UploadZipToS3(ctx context.Context, bucket, key, localZipPath string) error {
 	return nil
@@ -68,6 +103,38 @@ func (m *mockProcessor) UploadZipToS3(ctx context.Context, bucket, key, localZip
 func (m *mockProcessor) SendProcessingResult(ctx context.Context, processID, zipKey, status string) error {
 	return nil
 }
+func (m *mockProcessor) Shutdown(ctx context.Context) error { return nil }
+func (m *mockProcessor) BlobStore() services.BlobStore      { return services.NewDirBlobStore(".") }
+func (m *mockProcessor) CircuitBreakers() map[string]string {
+	if m.circuits == nil {
+		return map[string]string{"s3": services.CircuitClosed, "sqs": services.CircuitClosed}
+	}
+	return m.circuits
+}
+func (m *mockProcessor) PresignDownload(ctx context.Context, bucket, key string) (string, time.Time, error) {
+	return "https://s3.exemplo.com/" + bucket + "/" + key + "?X-Amz-Signature=abc", time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), nil
+}
+func (m *mockProcessor) CreateDirectUpload(ctx context.Context, req services.DirectUploadRequest) (services.DirectUpload, error) {
+	if req.Size < 0 {
+		return services.DirectUpload{}, services.ErrInvalidUpload
+	}
+	return services.DirectUpload{ProcessID: "proc-1", Bucket: "bucket", Key: "uploads/proc-1/" + req.Filename, Method: "PUT", URL: "https://s3.exemplo.com/put"}, nil
+}
+func (m *mockProcessor) CompleteDirectUpload(ctx context.Context, processID string, req services.CompleteUploadRequest) (bool, error) {
+	if req.Key != "uploads/"+processID+"/video.mp4" {
+		return false, services.ErrInvalidUpload
+	}
+	return true, nil
+}
+func (m *mockProcessor) ReplayWebhooks(ctx context.Context) (int, int, error) {
+	if m.replayErr != nil {
+		return 0, 0, m.replayErr
+	}
+	return 2, 1, nil
+}
+func (m *mockProcessor) QueueStats() []services.QueueStats {
+	return []services.QueueStats{{Queue: "video-processing-queue", Weight: 1, Received: 3, Completed: 2}}
+}
 
 func TestHandleMessageProcessorStatus_Ativo(t *testing.T) {
 	w := httptest.NewRecorder()
@@ -83,6 +150,9 @@ func TestHandleMessageProcessorStatus_Ativo(t *testing.T) {
 	if !containsStatus(w.Body.String(), "processor_active") {
 		t.Error("Esperado campo processor_active na resposta")
 	}
+	if !containsStatus(w.Body.String(), "video-processing-queue") {
+		t.Error("Esperado estatísticas por fila na resposta")
+	}
 }
 func TestHandleProcessMessage_Sucesso(t *testing.T) {
 	w := httptest.NewRecorder()
@@ -101,3 +171,91 @@ func TestHandleProcessMessage_Sucesso(t *testing.T) {
 		t.Error("Esperado corpo não vazio para sucesso")
 	}
 }
+
+func TestShutdownMessageProcessor_NaoInicializado(t *testing.T) {
+	messageProcessor = nil
+	if err := ShutdownMessageProcessor(context.TODO()); err != nil {
+		t.Errorf("Esperado nil, obtido %v", err)
+	}
+}
+
+func TestHandleProcessMessage_BucketNaoPermitido(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/api/process-message", bytes.NewBuffer([]byte(`{"fileId":"video.mp4","processId":"proc-1","sourceBucket":"outro-tenant"}`)))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	messageProcessor = &mockProcessor{}
+
+	HandleProcessMessage(c)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Esperado status 403, obtido %d", w.Code)
+	}
+}
+
+// Requisição de reenvio com o token indicado em Authorization
+func replayRequest(t *testing.T, token string) (*httptest.ResponseRecorder, *gin.Context) {
+	t.Setenv("WEBHOOK_REPLAY_TOKEN", "segredo")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/api/webhooks/replay", nil)
+	if token != "" {
+		c.Request.Header.Set("Authorization", "Bearer "+token)
+	}
+	return w, c
+}
+
+func TestHandleReplayWebhooks(t *testing.T) {
+	w, c := replayRequest(t, "segredo")
+	messageProcessor = &mockProcessor{}
+	HandleReplayWebhooks(c)
+	if w.Code != http.StatusOK {
+		t.Errorf("Esperado status 200, obtido %d", w.Code)
+	}
+	if !containsStatus(w.Body.String(), `"delivered":2`) || !containsStatus(w.Body.String(), `"failed":1`) {
+		t.Errorf("Esperado contagem de reenvio na resposta, obtido %s", w.Body.String())
+	}
+}
+
+func TestHandleReplayWebhooks_NaoInicializado(t *testing.T) {
+	w, c := replayRequest(t, "segredo")
+	messageProcessor = nil
+	HandleReplayWebhooks(c)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Esperado status 503, obtido %d", w.Code)
+	}
+}
+
+func TestHandleReplayWebhooks_SemTokenConfigurado(t *testing.T) {
+	w, c := replayRequest(t, "segredo")
+	t.Setenv("WEBHOOK_REPLAY_TOKEN", "")
+	messageProcessor = &mockProcessor{}
+	defer func() { messageProcessor = nil }()
+	HandleReplayWebhooks(c)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Esperado status 403, obtido %d", w.Code)
+	}
+}
+
+func TestHandleReplayWebhooks_TokenInvalido(t *testing.T) {
+	for _, token := range []string{"", "outro"} {
+		w, c := replayRequest(t, token)
+		messageProcessor = &mockProcessor{}
+		HandleReplayWebhooks(c)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Esperado status 401 para token '%s', obtido %d", token, w.Code)
+		}
+	}
+	messageProcessor = nil
+}
+
+func TestHandleReplayWebhooks_EmAndamento(t *testing.T) {
+	w, c := replayRequest(t, "segredo")
+	messageProcessor = &mockProcessor{replayErr: services.ErrReplayInProgress}
+	defer func() { messageProcessor = nil }()
+	HandleReplayWebhooks(c)
+	if w.Code != http.StatusConflict {
+		t.Errorf("Esperado status 409, obtido %d", w.Code)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+	"video-processor/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,7 +16,12 @@ var globZipFiles = func(pattern string) ([]string, error) {
 }
 
 func HandleStatus(c *gin.Context) {
-	files, err := globZipFiles(filepath.Join("outputs", "*.zip"))
+	if presignDownloads && messageProcessor != nil {
+		handleStoreStatus(c)
+		return
+	}
+
+	files, err := globZipFiles(filepath.Join(services.Scratch().Outputs, "*.zip"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar arquivos"})
 		return
@@ -26,12 +33,73 @@ func HandleStatus(c *gin.Context) {
 		if err != nil {
 			continue
 		}
-		results = append(results, map[string]interface{}{
+		entry := map[string]interface{}{
 			"filename":     filepath.Base(file),
 			"size":         info.Size(),
 			"created_at":   info.ModTime().Format("2006-01-02 15:04:05"),
 			"download_url": "/download/" + filepath.Base(file),
-		})
+		}
+		if at, ok := services.LastOutputDownload(file); ok {
+			entry["last_downloaded_at"] = at.Format("2006-01-02 15:04:05")
+		}
+		results = append(results, entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"files":     results,
+		"total":     len(results),
+		"retention": retentionStatus(),
+	})
+}
+
+// Limites e estatísticas da retenção de outputs/
+func retentionStatus() gin.H {
+	stats := services.OutputRetentionStatus()
+	status := gin.H{
+		"enabled":       stats.Config.Enabled(),
+		"interval":      stats.Config.Interval.String(),
+		"max_age":       stats.Config.MaxAge.String(),
+		"max_bytes":     stats.Config.MaxBytes,
+		"max_files":     stats.Config.MaxFiles,
+		"runs":          stats.Runs,
+		"files":         stats.Files,
+		"bytes":         stats.Bytes,
+		"evicted_files": stats.EvictedFiles,
+		"evicted_bytes": stats.EvictedBytes,
+		"evicted_by":    stats.ByReason,
+	}
+	if stats.LastRunAt != nil {
+		status["last_run_at"] = stats.LastRunAt.Format(time.RFC3339)
+	}
+	return status
+}
+
+// Status dos ZIPs no bucket de download, cada um com URL pré-assinada para
+// baixar direto do S3
+func handleStoreStatus(c *gin.Context) {
+	objects, err := downloadStore.List(c, downloadBucket, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar arquivos"})
+		return
+	}
+
+	results := []map[string]interface{}{}
+	for _, object := range objects {
+		if !strings.HasSuffix(object.Key, ".zip") {
+			continue
+		}
+		entry := map[string]interface{}{
+			"filename":     object.Key,
+			"size":         object.Size,
+			"created_at":   object.LastModified.Format("2006-01-02 15:04:05"),
+			"download_url": "/download/" + object.Key,
+		}
+		url, expiresAt, err := messageProcessor.PresignDownload(c, downloadBucket, object.Key)
+		if err == nil {
+			entry["presigned_url"] = url
+			entry["presigned_url_expires_at"] = expiresAt.Format(time.RFC3339)
+		}
+		results = append(results, entry)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -40,12 +108,25 @@ func HandleStatus(c *gin.Context) {
 	})
 }
 
-// HandleHealth retorna o status de saúde da aplicação
+// HandleHealth retorna o status de saúde da aplicação. Com um circuit breaker
+// aberto (S3/SQS indisponível) o status é "degraded", mas a resposta continua
+// 200: o processo está vivo e volta a consumir quando o serviço se recuperar
 func HandleHealth(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
+	health := gin.H{
 		"status":    "healthy",
 		"message":   "Service is running",
 		"timestamp": time.Now().Format(time.RFC3339),
 		"version":   "1.0.0",
-	})
+	}
+	if messageProcessor != nil {
+		breakers := messageProcessor.CircuitBreakers()
+		health["circuit_breakers"] = breakers
+		for _, state := range breakers {
+			if state != services.CircuitClosed {
+				health["status"] = "degraded"
+				health["message"] = "Dependência AWS indisponível, recebimento pausado"
+			}
+		}
+	}
+	c.JSON(http.StatusOK, health)
 }
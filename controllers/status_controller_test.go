@@ -1,11 +1,15 @@
 package controllers
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"video-processor/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -41,6 +45,40 @@ func TestHandleStatus(t *testing.T) {
 	}
 }
 
+func TestHandleStatus_Retencao(t *testing.T) {
+	filename := "test_retencao.zip"
+	os.MkdirAll("outputs", 0755)
+	os.WriteFile("outputs/"+filename, []byte("conteudo"), 0644)
+	defer os.Remove("outputs/" + filename)
+	services.TouchOutput(filename)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	HandleStatus(c)
+
+	var body struct {
+		Files []struct {
+			Filename         string `json:"filename"`
+			LastDownloadedAt string `json:"last_downloaded_at"`
+		} `json:"files"`
+		Retention map[string]interface{} `json:"retention"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Resposta inválida: %v", err)
+	}
+	for _, key := range []string{"enabled", "max_age", "max_bytes", "max_files", "evicted_files", "evicted_bytes", "evicted_by"} {
+		if _, ok := body.Retention[key]; !ok {
+			t.Errorf("Esperado campo %s nas estatísticas de retenção", key)
+		}
+	}
+	for _, file := range body.Files {
+		if file.Filename == filename && file.LastDownloadedAt == "" {
+			t.Error("Esperado last_downloaded_at para ZIP baixado")
+		}
+	}
+}
+
 func TestHandleHealth(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -139,3 +177,60 @@ func TestHandleStatus_ArquivoComErroDeStat(t *testing.T) {
 		t.Error("Esperado campo 'files' na resposta mesmo com erro de Stat")
 	}
 }
+
+func TestHandleHealth_CircuitBreakerAberto(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	messageProcessor = &mockProcessor{circuits: map[string]string{"s3": services.CircuitClosed, "sqs": services.CircuitOpen}}
+	defer func() { messageProcessor = nil }()
+
+	HandleHealth(c)
+	if w.Code != http.StatusOK {
+		t.Errorf("Esperado status 200, obtido %d", w.Code)
+	}
+	if !containsStatus(w.Body.String(), "degraded") {
+		t.Errorf("Esperado status 'degraded', obtido %s", w.Body.String())
+	}
+	if !containsStatus(w.Body.String(), `"sqs":"open"`) {
+		t.Errorf("Esperado estado dos circuit breakers na resposta, obtido %s", w.Body.String())
+	}
+}
+
+func TestHandleStatus_BucketComURLPreAssinada(t *testing.T) {
+	store := services.NewDirBlobStore(t.TempDir())
+	store.Put(context.TODO(), "resultados", "processed/proc-1.zip", strings.NewReader("zip"), services.PutOptions{})
+	store.Put(context.TODO(), "resultados", "proc-2.zip", strings.NewReader("zip"), services.PutOptions{})
+	store.Put(context.TODO(), "resultados", "notas.txt", strings.NewReader("txt"), services.PutOptions{})
+	SetDownloadStore(store, "resultados")
+	presignDownloads = true
+	messageProcessor = &mockProcessor{}
+	defer func() {
+		SetDownloadStore(services.NewDirBlobStore("outputs"), "")
+		presignDownloads = false
+		messageProcessor = nil
+	}()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	HandleStatus(c)
+
+	var body struct {
+		Files []map[string]interface{} `json:"files"`
+		Total int                      `json:"total"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusOK || body.Total != 2 {
+		t.Fatalf("Esperado 200 com 2 ZIPs, obtido %d %s", w.Code, w.Body.String())
+	}
+	for _, file := range body.Files {
+		if file["presigned_url"] != "https://s3.exemplo.com/resultados/"+file["filename"].(string)+"?X-Amz-Signature=abc" {
+			t.Errorf("Esperado URL pré-assinada, obtido %v", file)
+		}
+		if file["presigned_url_expires_at"] != "2030-01-01T00:00:00Z" {
+			t.Errorf("Esperado expiração da URL, obtido %v", file["presigned_url_expires_at"])
+		}
+		if file["download_url"] != "/download/"+file["filename"].(string) {
+			t.Errorf("Esperado download_url com a chave completa, obtido %v", file)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"video-processor/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Endpoint que emite URLs para o cliente enviar o vídeo direto ao S3 (PUT
+// único ou multipart), junto com o ProcessID do job
+func HandleCreateDirectUpload(c *gin.Context) {
+	var request services.DirectUploadRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+	if !IsValidVideoFile(request.Filename) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Formato de arquivo não suportado. Use: mp4, avi, mov, mkv",
+		})
+		return
+	}
+
+	if messageProcessor == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"success": false,
+			"message": "Processador de mensagens não inicializado",
+		})
+		return
+	}
+
+	upload, err := messageProcessor.CreateDirectUpload(c.Request.Context(), request)
+	if err != nil {
+		c.JSON(directUploadStatus(err), gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, upload)
+}
+
+// Endpoint chamado pelo cliente ao terminar o upload: conclui o multipart e
+// enfileira o processamento (no modo event, só valida o objeto)
+func HandleCompleteDirectUpload(c *gin.Context) {
+	var request services.CompleteUploadRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	if messageProcessor == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"success": false,
+			"message": "Processador de mensagens não inicializado",
+		})
+		return
+	}
+
+	processID := c.Param("processId")
+	queued, err := messageProcessor.CompleteDirectUpload(c.Request.Context(), processID, request)
+	if err != nil {
+		c.JSON(directUploadStatus(err), gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success":   true,
+		"processId": processID,
+		"queued":    queued,
+	})
+}
+
+func directUploadStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidUpload):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrPresignUnavailable):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusBadGateway
+	}
+}
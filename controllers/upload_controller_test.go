@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"video-processor/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandleCreateDirectUpload(t *testing.T) {
+	messageProcessor = &mockProcessor{}
+	defer func() { messageProcessor = nil }()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/api/uploads", bytes.NewBufferString(`{"filename":"video.mp4","size":1024}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	HandleCreateDirectUpload(c)
+
+	var upload services.DirectUpload
+	json.Unmarshal(w.Body.Bytes(), &upload)
+	if w.Code != http.StatusCreated || upload.ProcessID != "proc-1" || upload.URL == "" {
+		t.Errorf("Esperado 201 com ProcessID e URL, obtido %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleCreateDirectUpload_FormatoInvalido(t *testing.T) {
+	messageProcessor = &mockProcessor{}
+	defer func() { messageProcessor = nil }()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/api/uploads", bytes.NewBufferString(`{"filename":"planilha.xlsx"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	HandleCreateDirectUpload(c)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Esperado status 400, obtido %d", w.Code)
+	}
+}
+
+func TestHandleCreateDirectUpload_PedidoInvalido(t *testing.T) {
+	messageProcessor = &mockProcessor{}
+	defer func() { messageProcessor = nil }()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/api/uploads", bytes.NewBufferString(`{"filename":"video.mp4","size":-1}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	HandleCreateDirectUpload(c)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Esperado status 400, obtido %d", w.Code)
+	}
+}
+
+func TestHandleCreateDirectUpload_NaoInicializado(t *testing.T) {
+	messageProcessor = nil
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/api/uploads", bytes.NewBufferString(`{"filename":"video.mp4"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	HandleCreateDirectUpload(c)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Esperado status 503, obtido %d", w.Code)
+	}
+}
+
+func TestHandleCompleteDirectUpload(t *testing.T) {
+	messageProcessor = &mockProcessor{}
+	defer func() { messageProcessor = nil }()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "processId", Value: "proc-1"}}
+	c.Request, _ = http.NewRequest("POST", "/api/uploads/proc-1/complete", bytes.NewBufferString(`{"key":"uploads/proc-1/video.mp4"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	HandleCompleteDirectUpload(c)
+
+	if w.Code != http.StatusAccepted || !containsStatus(w.Body.String(), `"queued":true`) {
+		t.Errorf("Esperado 202 com job enfileirado, obtido %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleCompleteDirectUpload_ChaveDeOutroProcesso(t *testing.T) {
+	messageProcessor = &mockProcessor{}
+	defer func() { messageProcessor = nil }()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "processId", Value: "proc-2"}}
+	c.Request, _ = http.NewRequest("POST", "/api/uploads/proc-2/complete", bytes.NewBufferString(`{"key":"uploads/proc-1/video.mp4"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	HandleCompleteDirectUpload(c)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Esperado status 400, obtido %d", w.Code)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
-	"time"
 	"video-processor/models"
 	"video-processor/services"
 
@@ -32,9 +31,10 @@ func HandleVideoUpload(c *gin.Context) {
 		return
 	}
 
-	timestamp := time.Now().Format("20060102_150405")
-	filename := fmt.Sprintf("%s_%s", timestamp, header.Filename)
-	videoPath := filepath.Join("uploads", filename)
+	// ID único do job: nomes do vídeo, dos frames e do ZIP não colidem entre jobs
+	jobID := services.NewJobID()
+	filename := fmt.Sprintf("%s_%s", jobID, filepath.Base(header.Filename))
+	videoPath := filepath.Join(services.Scratch().Uploads, filename)
 
 	out, err := os.Create(videoPath)
 	if err != nil {
@@ -45,6 +45,7 @@ func HandleVideoUpload(c *gin.Context) {
 		return
 	}
 	defer out.Close()
+	defer os.Remove(videoPath)
 
 	_, err = io.Copy(out, file)
 	if err != nil {
@@ -55,11 +56,7 @@ func HandleVideoUpload(c *gin.Context) {
 		return
 	}
 
-	result := services.ProcessVideo(videoPath, timestamp)
-
-	if result.Success {
-		os.Remove(videoPath)
-	}
+	result := services.ProcessVideo(videoPath, jobID)
 
 	c.JSON(http.StatusOK, result)
 }
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
-	"os"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 	"video-processor/controllers"
+	"video-processor/services"
 	"video-processor/utils"
 
 	"github.com/gin-gonic/gin"
@@ -18,10 +24,21 @@ func main() {
 		log.Println("✅ Arquivo .env carregado com sucesso")
 	}
 
-	createDirs()
+	dirs := createDirs()
+
+	// Contexto cancelado ao receber SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Remover workspaces e arquivos órfãos de execuções interrompidas
+	services.StartWorkspaceJanitor(ctx, services.LoadWorkspaceJanitorConfig())
+
+	// Retenção dos ZIPs gerados por /upload em outputs/
+	services.StartOutputRetention(ctx, services.LoadOutputRetentionConfig())
 
 	// Inicializar processador de mensagens
-	if err := controllers.InitMessageProcessor(); err != nil {
+	config := services.LoadMessageProcessorConfig()
+	if err := controllers.InitMessageProcessor(ctx, config); err != nil {
 		log.Printf("⚠️  Aviso: Não foi possível inicializar processador de mensagens: %v", err)
 		log.Println("🔄 Continuando apenas com upload HTTP...")
 	} else {
@@ -41,33 +58,74 @@ func main() {
 		c.Next()
 	})
 
-	r.Static("/uploads", "./uploads")
-	r.Static("/outputs", "./outputs")
+	r.Static("/uploads", dirs.Uploads)
+	r.Group("/outputs", controllers.TrackOutputDownload).Static("/", dirs.Outputs)
 
 	r.GET("/", controllers.HandleHTML)
 	r.GET("/health", controllers.HandleHealth)
 	r.POST("/upload", controllers.HandleVideoUpload)
-	r.GET("/download/:filename", controllers.HandleDownload)
+	r.GET("/download/*filename", controllers.HandleDownload)
 	r.GET("/api/status", controllers.HandleStatus)
 
 	// Rotas para processamento via mensageria
 	r.POST("/api/process-message", controllers.HandleProcessMessage)
 	r.GET("/api/message-processor/status", controllers.HandleMessageProcessorStatus)
+	r.POST("/api/webhooks/replay", controllers.HandleReplayWebhooks)
+
+	// Upload direto ao S3 com URLs pré-assinadas
+	r.POST("/api/uploads", controllers.HandleCreateDirectUpload)
+	r.POST("/api/uploads/:processId/complete", controllers.HandleCompleteDirectUpload)
 
 	// Endpoint para métricas Prometheus
 	r.GET("/metrics", controllers.HandleMetrics)
 
 	port := ":" + utils.GetEnv("PORT", "8080")
-	log.Printf("🎬 Servidor iniciado na porta %s", port[1:])
-	log.Printf("📂 Acesse: http://localhost%s", port)
-	log.Fatal(r.Run(port))
+	srv := &http.Server{
+		Addr:    port,
+		Handler: r,
+	}
+
+	go func() {
+		log.Printf("🎬 Servidor iniciado na porta %s", port[1:])
+		log.Printf("📂 Acesse: http://localhost%s", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("❌ Erro no servidor HTTP: %v", err)
+		}
+	}()
+
+	// Aguardar sinal de shutdown
+	<-ctx.Done()
+	stop()
+	log.Println("🛑 Recebido sinal de shutdown, parando aplicação...")
+
+	// HTTP e jobs são encerrados em paralelo, cada um com seu prazo: requisições
+	// lentas não consomem o período de tolerância dos jobs
+	httpDone := make(chan struct{})
+	go func() {
+		defer close(httpDone)
+		httpCtx, cancel := context.WithTimeout(context.Background(), utils.GetEnvDuration("HTTP_SHUTDOWN_TIMEOUT", 10*time.Second))
+		defer cancel()
+		if err := srv.Shutdown(httpCtx); err != nil {
+			log.Printf("⚠️ Erro ao encerrar servidor HTTP: %v", err)
+		}
+	}()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownGracePeriod)
+	defer cancel()
+	if err := controllers.ShutdownMessageProcessor(shutdownCtx); err != nil {
+		log.Printf("⚠️ Processador de mensagens encerrado com jobs pendentes: %v", err)
+	}
+	<-httpDone
+	log.Println("👋 Aplicação finalizada")
 }
 
-func createDirs() {
-	dirs := []string{"uploads", "outputs", "temp"}
-	for _, dir := range dirs {
-		// Usando a função padrão do pacote os
-		// Se quiser, pode mover para utils depois
-		_ = os.MkdirAll(dir, 0755)
+// Criar os diretórios de trabalho (UPLOADS_DIR, OUTPUTS_DIR, TEMP_DIR) e
+// servir /download a partir do diretório de saída
+func createDirs() services.ScratchDirs {
+	dirs := services.LoadScratchDirs()
+	if err := services.InitScratchDirs(dirs); err != nil {
+		log.Fatalf("❌ %v", err)
 	}
+	controllers.SetDownloadStore(services.NewDirBlobStore(dirs.Outputs), "")
+	return dirs
 }
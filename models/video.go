@@ -11,4 +11,9 @@ type ProcessingResult struct {
 	ZipPath    string   `json:"zip_path,omitempty"`
 	FrameCount int      `json:"frame_count,omitempty"`
 	Images     []string `json:"images,omitempty"`
+	// Duração do vídeo (segundos) e versão do ffmpeg, lidas da saída do ffmpeg
+	Duration    float64 `json:"duration_seconds,omitempty"`
+	ToolVersion string  `json:"tool_version,omitempty"`
+	// SHA-256 (hex) do ZIP, calculado durante a gravação
+	SHA256 string `json:"sha256,omitempty"`
 }
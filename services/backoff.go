@@ -0,0 +1,44 @@
+package services
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff exponencial com jitter: cada chamada a Next dobra o intervalo
+// base (limitado a max) e sorteia um valor entre metade e o intervalo cheio
+type backoff struct {
+	min     time.Duration
+	max     time.Duration
+	attempt int
+}
+
+func newBackoff(min, max time.Duration) *backoff {
+	if min <= 0 {
+		min = 100 * time.Millisecond
+	}
+	if max < min {
+		max = min
+	}
+	return &backoff{min: min, max: max}
+}
+
+// Próximo intervalo de espera
+func (b *backoff) Next() time.Duration {
+	d := b.min
+	for i := 0; i < b.attempt && d < b.max; i++ {
+		d *= 2
+	}
+	if d > b.max {
+		d = b.max
+	}
+	b.attempt++
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// Voltar ao intervalo mínimo
+func (b *backoff) Reset() {
+	b.attempt = 0
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff_CresceAteOMaximo(t *testing.T) {
+	b := newBackoff(100*time.Millisecond, time.Second)
+	var last time.Duration
+	for i := 0; i < 10; i++ {
+		last = b.Next()
+		if last > time.Second {
+			t.Fatalf("Esperado intervalo <= 1s, obtido %s", last)
+		}
+	}
+	if last < 500*time.Millisecond {
+		t.Errorf("Esperado intervalo próximo do máximo após várias tentativas, obtido %s", last)
+	}
+}
+
+func TestBackoff_Reset(t *testing.T) {
+	b := newBackoff(100*time.Millisecond, time.Second)
+	for i := 0; i < 5; i++ {
+		b.Next()
+	}
+	b.Reset()
+	if d := b.Next(); d > 100*time.Millisecond || d < 50*time.Millisecond {
+		t.Errorf("Esperado intervalo entre 50ms e 100ms após reset, obtido %s", d)
+	}
+}
+
+func TestBackoff_ValoresInvalidos(t *testing.T) {
+	b := newBackoff(0, 0)
+	if d := b.Next(); d <= 0 {
+		t.Errorf("Esperado intervalo positivo, obtido %s", d)
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Backends de armazenamento disponíveis (BLOB_BACKEND)
+const (
+	BlobBackendS3  = "s3"
+	BlobBackendDir = "dir"
+)
+
+// ErrBlobNotFound indica objeto inexistente, independente do backend
+var ErrBlobNotFound = errors.New("objeto não encontrado")
+
+// Informações de um objeto armazenado
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+	ETag         string
+	ContentType  string
+	// Nome sugerido para download (Content-Disposition)
+	ContentDisposition string
+	Metadata           map[string]string
+	// Criptografia, classe e checksum (base64) informados pelo armazenamento
+	Encryption        string
+	KMSKeyID          string
+	StorageClass      string
+	ChecksumAlgorithm string
+	Checksum          string
+}
+
+// Opções de gravação de um objeto
+type PutOptions struct {
+	ContentType        string
+	ContentDisposition string
+	Metadata           map[string]string
+	Tags               map[string]string
+	StorageOptions
+	// Checksum esperado (base64, do ChecksumAlgorithm); o armazenamento recusa
+	// o objeto se o conteúdo recebido não conferir
+	Checksum string
+}
+
+// BlobStore abstrai o armazenamento de vídeos e resultados. O bucket é o
+// bucket do S3 ou, no backend dir, um subdiretório da raiz configurada.
+type BlobStore interface {
+	Get(ctx context.Context, bucket, key string) (io.ReadCloser, ObjectInfo, error)
+	// Put grava o objeto e retorna o que o armazenamento informou (ETag, checksum, criptografia)
+	Put(ctx context.Context, bucket, key string, body io.Reader, opts PutOptions) (ObjectInfo, error)
+	Delete(ctx context.Context, bucket, key string) error
+	Head(ctx context.Context, bucket, key string) (ObjectInfo, error)
+	List(ctx context.Context, bucket, prefix string) ([]ObjectInfo, error)
+	// Copy copia o objeto com seus metadados (o destino é sobrescrito)
+	Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error
+	// Tag substitui as tags do objeto
+	Tag(ctx context.Context, bucket, key string, tags map[string]string) error
+	// Tags retorna as tags do objeto
+	Tags(ctx context.Context, bucket, key string) (map[string]string, error)
+}
+
+// Validar o backend de armazenamento configurado
+func (c MessageProcessorConfig) validateBlobBackend() error {
+	switch strings.ToLower(c.BlobBackend) {
+	case "", BlobBackendS3, BlobBackendDir:
+		return nil
+	default:
+		return fmt.Errorf("backend de armazenamento desconhecido: %s", c.BlobBackend)
+	}
+}
+
+// BlobStore retorna o armazenamento configurado, criado sob demanda
+func (mp *MessageProcessor) BlobStore() BlobStore {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if mp.blobs == nil {
+		if strings.ToLower(mp.config.BlobBackend) == BlobBackendDir {
+			mp.blobs = NewDirBlobStore(mp.config.BlobDir)
+		} else {
+			mp.blobs = NewS3BlobStore(mp.s3Client)
+		}
+	}
+	return mp.blobs
+}
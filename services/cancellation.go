@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Por quanto tempo um cancelamento recebido antes do job é lembrado
+const pendingCancellationTTL = 24 * time.Hour
+
+// Causa do cancelamento do ctx de um job a pedido do usuário
+// (diferente do cancelamento por fim do período de tolerância no shutdown)
+var errJobCancelled = errors.New("job cancelado por mensagem de controle")
+
+// Mensagem de controle, aceita apenas na fila de controle (CONTROL_QUEUE_URL)
+// Exemplo: { "action": "cancel", "processId": "proc-123" }
+type ControlMessage struct {
+	Action    string `json:"action"`
+	ProcessID string `json:"processId"`
+}
+
+// Jobs em execução e cancelamentos recebidos antes do início do job, em memória.
+// Cada cancelamento é consumido por uma única instância e só afeta os jobs dela:
+// com vários workers, a fila de controle deve receber as mensagens via fan-out
+// (ex: SNS → uma fila por worker), e um cancelamento pendente se perde no restart.
+type jobRegistry struct {
+	mu      sync.Mutex
+	running map[string]context.CancelCauseFunc
+	pending map[string]time.Time
+}
+
+// Identificar mensagem de controle pelo campo "action"
+func parseControlMessage(body string) (ControlMessage, bool) {
+	var msg ControlMessage
+	if err := json.Unmarshal([]byte(body), &msg); err != nil || msg.Action == "" {
+		return ControlMessage{}, false
+	}
+	msg.Action = strings.ToLower(msg.Action)
+	return msg, true
+}
+
+// Registrar job em execução e retornar o ctx cancelável por mensagem de controle
+func (r *jobRegistry) start(ctx context.Context, processID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	r.mu.Lock()
+	if r.running == nil {
+		r.running = make(map[string]context.CancelCauseFunc)
+	}
+	r.running[processID] = cancel
+	r.mu.Unlock()
+
+	return ctx, func() {
+		r.mu.Lock()
+		delete(r.running, processID)
+		r.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// Cancelar o job em execução ou lembrar o cancelamento para quando ele chegar.
+// Retorna true se havia um job em execução.
+func (r *jobRegistry) cancel(processID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cancel, ok := r.running[processID]; ok {
+		cancel(errJobCancelled)
+		return true
+	}
+
+	if r.pending == nil {
+		r.pending = make(map[string]time.Time)
+	}
+	now := time.Now()
+	for id, at := range r.pending {
+		if now.Sub(at) > pendingCancellationTTL {
+			delete(r.pending, id)
+		}
+	}
+	r.pending[processID] = now
+	return false
+}
+
+// Consumir cancelamento recebido antes do início do job
+func (r *jobRegistry) takePending(processID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	at, ok := r.pending[processID]
+	if !ok {
+		return false
+	}
+	delete(r.pending, processID)
+	return time.Since(at) <= pendingCancellationTTL
+}
+
+// Job interrompido por mensagem de controle
+func isJobCancelled(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errJobCancelled)
+}
+
+// Aplicar mensagem de controle
+func (mp *MessageProcessor) handleControlMessage(ctx context.Context, msg ControlMessage) {
+	switch msg.Action {
+	case "cancel":
+		if msg.ProcessID == "" {
+			logf(ctx, "⚠️ Mensagem de cancelamento sem processId ignorada")
+			return
+		}
+		if mp.jobs.cancel(msg.ProcessID) {
+			logf(ctx, "🛑 Cancelando job em execução (ProcessID: %s)", msg.ProcessID)
+		} else {
+			logf(ctx, "🛑 Job ainda não iniciado será ignorado ao chegar (ProcessID: %s)", msg.ProcessID)
+		}
+	default:
+		logf(ctx, "⚠️ Ação de controle desconhecida ignorada: %s", msg.Action)
+	}
+}
+
+// Finalizar job cancelado: remover o ZIP já enviado (se houver) e publicar CANCELLED.
+// O ctx do job já está cancelado, então a limpeza usa um ctx sem cancelamento.
+func (mp *MessageProcessor) finishCancelledJob(ctx context.Context, processID, bucket, zipKey string) bool {
+	ctx = context.WithoutCancel(ctx)
+	logf(ctx, "🛑 Job cancelado (ProcessID: %s)", processID)
+
+	if zipKey != "" {
+		if err := mp.BlobStore().Delete(ctx, bucket, zipKey); err != nil {
+			logf(ctx, "⚠️ Aviso: Erro ao remover ZIP do job cancelado: %v", err)
+		} else {
+			logf(ctx, "🗑️ ZIP do job cancelado removido: s3://%s/%s", bucket, zipKey)
+		}
+	}
+
+	if err := mp.SendProcessingResult(ctx, processID, "", "CANCELLED"); err != nil {
+		logf(ctx, "⚠️ Erro ao enviar notificação de cancelamento: %v", err)
+	}
+	return true
+}
+
+// Consumir a fila de controle dedicada, em paralelo ao processamento dos vídeos,
+// para que cancelamentos cheguem a jobs em execução
+func (mp *MessageProcessor) pollControlQueue(ctx context.Context, control Queue) {
+	log.Printf("🎛️  Fila de controle: %s", mp.config.ControlQueueURL)
+	wait := newBackoff(mp.config.PollBackoffMin, mp.config.PollingInterval)
+
+	for ctx.Err() == nil {
+		messages, err := control.Receive(ctx, 10, 20*time.Second)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("❌ Erro ao receber mensagens de controle: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait.Next()):
+			}
+			continue
+		}
+		wait.Reset()
+
+		for _, message := range messages {
+			msgCtx := withTrace(ctx, traceFromAttributes(message.Attributes))
+			if msg, ok := parseControlMessage(message.Body); ok {
+				mp.handleControlMessage(msgCtx, msg)
+			} else {
+				logf(msgCtx, "⚠️ Mensagem inválida na fila de controle ignorada")
+			}
+			if err := control.Ack(ctx, message); err != nil {
+				logf(msgCtx, "❌ Erro ao deletar mensagem de controle: %v", err)
+			}
+		}
+	}
+}
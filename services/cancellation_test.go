@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+func TestParseControlMessage(t *testing.T) {
+	msg, ok := parseControlMessage(`{"action":"CANCEL","processId":"proc-1"}`)
+	if !ok || msg.Action != "cancel" || msg.ProcessID != "proc-1" {
+		t.Errorf("Esperado cancelamento de proc-1, obtido %+v (%v)", msg, ok)
+	}
+	if _, ok := parseControlMessage(`{"fileId":"videos/a.mp4","processId":"proc-1"}`); ok {
+		t.Error("Esperado que mensagem de vídeo não seja de controle")
+	}
+}
+
+func TestJobRegistry_CancelaJobEmExecucao(t *testing.T) {
+	var jobs jobRegistry
+	ctx, done := jobs.start(context.Background(), "proc-1")
+	defer done()
+
+	if !jobs.cancel("proc-1") {
+		t.Fatal("Esperado job em execução")
+	}
+	if !isJobCancelled(ctx) {
+		t.Error("Esperado ctx do job cancelado por mensagem de controle")
+	}
+	if jobs.takePending("proc-1") {
+		t.Error("Esperado nenhum cancelamento pendente para job em execução")
+	}
+}
+
+func TestJobRegistry_CancelamentoAntesDoInicio(t *testing.T) {
+	var jobs jobRegistry
+	if jobs.cancel("proc-2") {
+		t.Error("Esperado job não iniciado")
+	}
+	if !jobs.takePending("proc-2") {
+		t.Error("Esperado cancelamento pendente")
+	}
+	if jobs.takePending("proc-2") {
+		t.Error("Esperado cancelamento consumido apenas uma vez")
+	}
+}
+
+func TestIsJobCancelled_ShutdownNaoEhCancelamento(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if isJobCancelled(ctx) {
+		t.Error("Esperado que cancelamento por shutdown não seja tratado como cancelamento do job")
+	}
+}
+
+// Mock SQSClient que registra os status publicados
+type mockSQSClientStatus struct {
+	mockSQSClient
+	statuses []string
+}
+
+func (m *mockSQSClientStatus) SendMessage(ctx context.Context, input *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	var result VideoProcessingResult
+	json.Unmarshal([]byte(aws.ToString(input.MessageBody)), &result)
+	m.statuses = append(m.statuses, result.Status)
+	return &sqs.SendMessageOutput{}, nil
+}
+
+// Mock S3Client que registra os objetos lidos
+type mockS3ClientGets struct {
+	mockS3Client
+	gets int
+}
+
+func (m *mockS3ClientGets) GetObject(ctx context.Context, input *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	m.gets++
+	return m.mockS3Client.GetObject(ctx, input, optFns...)
+}
+
+func TestProcessMessage_CancelamentoPulaJobNaoIniciado(t *testing.T) {
+	mockSQS := &mockSQSClientStatus{}
+	mockS3 := &mockS3ClientGets{}
+	mp := &MessageProcessor{
+		config:    MessageProcessorConfig{SQSQueueURL: "url", ResultsQueueURL: "results", SourceBucket: "bucket", ResultsBucket: "bucket"},
+		sqsClient: mockSQS,
+		s3Client:  mockS3,
+	}
+
+	mp.handleControlMessage(context.TODO(), ControlMessage{Action: "cancel", ProcessID: "proc-3"})
+
+	videoMsg := QueueMessage{ID: "v1", Handle: "rv1", Body: `{"fileId":"videos/a.mp4","processId":"proc-3"}`}
+	if !mp.processMessage(context.TODO(), videoMsg) {
+		t.Error("Esperado mensagem do job cancelado removida da fila")
+	}
+	if mockS3.gets != 0 {
+		t.Errorf("Esperado nenhum download para job cancelado, obtido %d", mockS3.gets)
+	}
+	if len(mockSQS.statuses) != 1 || mockSQS.statuses[0] != "CANCELLED" {
+		t.Errorf("Esperado apenas status CANCELLED, obtido %v", mockSQS.statuses)
+	}
+}
+
+func TestProcessMessage_ControleRecusadoNaFilaDeEntrada(t *testing.T) {
+	mp := &MessageProcessor{config: MessageProcessorConfig{SQSQueueURL: "url"}, sqsClient: &mockSQSClient{}}
+
+	cancelMsg := QueueMessage{ID: "c1", Handle: "rc1", Body: `{"action":"cancel","processId":"proc-5"}`}
+	if mp.processMessage(context.TODO(), cancelMsg) {
+		t.Error("Esperado mensagem de controle recusada na fila de entrada")
+	}
+	if mp.jobs.takePending("proc-5") {
+		t.Error("Cancelamento da fila de entrada não deveria ser registrado")
+	}
+}
+
+func TestPollControlQueue_CancelaJobEmExecucao(t *testing.T) {
+	mp := &MessageProcessor{config: MessageProcessorConfig{
+		QueueBackend:    QueueBackendMemory,
+		ControlQueueURL: "teste-controle-" + t.Name(),
+		PollBackoffMin:  time.Millisecond,
+		PollingInterval: time.Millisecond,
+	}}
+	control := mp.controlQueue()
+
+	jobCtx, done := mp.jobs.start(context.Background(), "proc-6")
+	defer done()
+
+	pollCtx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go mp.pollControlQueue(pollCtx, control)
+
+	// Cancelamento enviado com o job já em execução
+	control.Publish(context.TODO(), OutgoingMessage{Body: `{"action":"cancel","processId":"proc-6"}`})
+
+	select {
+	case <-jobCtx.Done():
+		if !isJobCancelled(jobCtx) {
+			t.Errorf("Esperado cancelamento pelo controle, obtido %v", context.Cause(jobCtx))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Esperado job em execução cancelado pela fila de controle")
+	}
+}
+
+// Mock S3Client que registra as chaves removidas
+type mockS3ClientDeletes struct {
+	mockS3Client
+	deleted []string
+}
+
+func (m *mockS3ClientDeletes) DeleteObject(ctx context.Context, input *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	m.deleted = append(m.deleted, aws.ToString(input.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func TestFinishCancelledJob_RemoveZipEnviado(t *testing.T) {
+	mockS3 := &mockS3ClientDeletes{}
+	mockSQS := &mockSQSClientStatus{}
+	mp := &MessageProcessor{config: MessageProcessorConfig{ResultsQueueURL: "results"}, sqsClient: mockSQS, s3Client: mockS3}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errJobCancelled)
+	mp.finishCancelledJob(ctx, "proc-4", "bucket", "processed/proc-4_frames.zip")
+
+	if len(mockS3.deleted) != 1 || mockS3.deleted[0] != "processed/proc-4_frames.zip" {
+		t.Errorf("Esperado ZIP removido, obtido %v", mockS3.deleted)
+	}
+	if len(mockSQS.statuses) != 1 || mockSQS.statuses[0] != "CANCELLED" {
+		t.Errorf("Esperado status CANCELLED mesmo com ctx cancelado, obtido %v", mockSQS.statuses)
+	}
+}
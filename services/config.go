@@ -0,0 +1,182 @@
+package services
+
+import (
+	"strings"
+	"time"
+	"video-processor/utils"
+)
+
+// Configuração do processador de mensagens
+type MessageProcessorConfig struct {
+	QueueBackend           string        // sqs (padrão), memory ou dir
+	QueueDir               string        // Diretório raiz das filas do backend dir
+	VisibilityTimeout      time.Duration // Visibilidade das mensagens em processamento (pedida no recebimento e renovada durante o job)
+	BlobBackend            string        // s3 (padrão) ou dir
+	BlobDir                string        // Raiz do backend dir: cada bucket é um subdiretório
+	SQSQueueURL            string
+	InputQueues            []InputQueue // Filas de entrada ponderadas; vazio = apenas SQSQueueURL
+	ControlQueueURL        string       // Fila dedicada de mensagens de controle (cancelamento); vazio = desabilitada
+	ResultsQueueURL        string
+	ResultsGroupID         string        // Template do MessageGroupId em filas FIFO ({processId}, {correlationId})
+	ResultsTopicARN        string        // Tópico SNS de resultados; vazio = desabilitado
+	ResultSinks            []string      // Destinos dos resultados (queue, sns, webhook, log); vazio = padrão
+	OutboxDir              string        // Journal dos resultados ainda não confirmados; vazio = desabilitado
+	OutboxRelayInterval    time.Duration // Intervalo do reenvio das entradas pendentes
+	OutboxMaxAttempts      int           // Tentativas antes do dead-letter (0 = sem limite)
+	LocalStackURL          string
+	AWSRegion              string
+	SourceBucket           string
+	ResultsBucket          string
+	AllowedBuckets         []string          // Buckets que as mensagens podem indicar, além dos dois acima
+	SourceRetention        string            // Política do vídeo original: delete, keep, tag ou archive
+	RetentionByTenant      map[string]string // Política por tenant, sobre a padrão
+	RetentionArchiveBucket string            // Bucket do arquivo de originais; vazio = bucket de origem
+	RetentionArchivePrefix string            // Prefixo das chaves arquivadas
+	ResultKeyTemplate      string            // Template da chave do ZIP (ver result_key.go)
+	ResultEncryption       string            // Criptografia dos ZIPs: AES256, aws:kms ou vazio (padrão do bucket)
+	ResultKMSKeyID         string            // Chave KMS (com aws:kms); vazio = chave gerenciada pela AWS
+	ResultStorageClass     string            // Classe de armazenamento dos ZIPs (ex: STANDARD_IA)
+	ResultChecksum         string            // Checksum conferido no upload: CRC32, CRC32C, SHA1 ou SHA256
+	PresignTTL             time.Duration     // Validade das URLs de download (0 = desabilitado)
+	PresignEndpoint        string            // Endpoint das URLs pré-assinadas (padrão: o do client S3)
+
+	// Upload direto ao bucket de origem (ver direct_upload.go)
+	UploadPrefix             string        // Prefixo das chaves: <prefixo><processId>/<arquivo>
+	UploadURLTTL             time.Duration // Validade das URLs de upload
+	UploadMultipartThreshold int64         // Tamanho a partir do qual o upload é multipart
+	UploadPartSize           int64         // Tamanho de cada parte (mínimo 5 MiB)
+	UploadConfirmMode        string        // confirm (cliente confirma) ou event (notificação do S3)
+	PollingInterval          time.Duration // Espera máxima entre polls após erros ou fila vazia
+	PollBackoffMin           time.Duration // Espera inicial do backoff
+	WaitTimeSeconds          int32         // Long polling do ReceiveMessage (0-20)
+	MaxMessages              int32
+	ShutdownGracePeriod      time.Duration
+
+	// Callbacks HTTP assinados com os resultados (ver webhookPublisher)
+	WebhookURL          string   // Callback global; mensagens podem indicar outro (callbackUrl)
+	WebhookSecret       string   // Chave do HMAC-SHA256
+	WebhookAllowedHosts []string // Hosts permitidos em callbacks por mensagem, além do host do WebhookURL
+	WebhookMaxAttempts  int
+	WebhookTimeout      time.Duration
+	WebhookFailedDir    string // Callbacks que falharam definitivamente, para reenvio
+
+	// Novas tentativas e circuit breaker das chamadas ao S3 e ao SQS
+	AWSMaxAttempts          int
+	AWSRetryBackoffMin      time.Duration
+	AWSRetryBackoffMax      time.Duration
+	AWSOperationTimeout     time.Duration // Prazo de cada tentativa (0 = sem prazo)
+	CircuitBreakerThreshold int           // Falhas seguidas para abrir o circuito (0 = desabilitado)
+	CircuitBreakerCooldown  time.Duration // Tempo aberto antes de testar o serviço novamente
+
+	// Lotes de DeleteMessage/SendMessage (tamanho <= 1 desabilita)
+	SQSBatchSize          int
+	SQSBatchFlushInterval time.Duration
+
+	// Espaço em disco exigido antes do download: tamanho do vídeo × fator + reserva
+	DiskExpansionFactor float64 // 0 desabilita a verificação
+	DiskReserveBytes    int64
+}
+
+// LoadMessageProcessorConfig monta a configuração a partir das variáveis de ambiente
+// Compartilhada entre o servidor HTTP (main.go) e o worker (cmd/message-processor)
+func LoadMessageProcessorConfig() MessageProcessorConfig {
+	return MessageProcessorConfig{
+		QueueBackend:           utils.GetEnv("QUEUE_BACKEND", QueueBackendSQS),
+		QueueDir:               utils.GetEnv("QUEUE_DIR", "queues"),
+		VisibilityTimeout:      utils.GetEnvDuration("QUEUE_VISIBILITY_TIMEOUT", defaultVisibilityTimeout),
+		BlobBackend:            utils.GetEnv("BLOB_BACKEND", BlobBackendS3),
+		BlobDir:                utils.GetEnv("BLOB_DIR", "storage"),
+		SQSQueueURL:            utils.GetEnv("SQS_QUEUE_URL", "http://localhost:4566/000000000000/video-processing-queue"),
+		InputQueues:            parseInputQueues(utils.GetEnv("SQS_QUEUE_URLS", "")),
+		ControlQueueURL:        utils.GetEnv("CONTROL_QUEUE_URL", ""),
+		ResultsQueueURL:        utils.GetEnv("RESULTS_QUEUE_URL", "http://localhost:4566/000000000000/video-results-queue"),
+		ResultsGroupID:         utils.GetEnv("RESULTS_MESSAGE_GROUP_ID", defaultResultsGroupID),
+		ResultsTopicARN:        utils.GetEnv("RESULTS_TOPIC_ARN", ""),
+		ResultSinks:            splitList(utils.GetEnv("RESULT_SINKS", "")),
+		OutboxDir:              utils.GetEnv("OUTBOX_DIR", ""),
+		OutboxRelayInterval:    utils.GetEnvDuration("OUTBOX_RELAY_INTERVAL", 10*time.Second),
+		OutboxMaxAttempts:      utils.GetEnvInt("OUTBOX_MAX_ATTEMPTS", 20),
+		LocalStackURL:          utils.GetEnv("LOCALSTACK_URL", ""),
+		AWSRegion:              utils.GetEnv("AWS_REGION", "us-east-1"),
+		SourceBucket:           utils.GetEnv("SOURCE_BUCKET", "video-bucket"),
+		ResultsBucket:          utils.GetEnv("RESULTS_BUCKET", "video-results"),
+		AllowedBuckets:         splitList(utils.GetEnv("ALLOWED_BUCKETS", "")),
+		SourceRetention:        utils.GetEnv("SOURCE_RETENTION", RetentionDelete),
+		RetentionByTenant:      parseKeyValues(utils.GetEnv("SOURCE_RETENTION_TENANTS", "")),
+		RetentionArchiveBucket: utils.GetEnv("RETENTION_ARCHIVE_BUCKET", ""),
+		RetentionArchivePrefix: utils.GetEnv("RETENTION_ARCHIVE_PREFIX", defaultArchivePrefix),
+		ResultKeyTemplate:      utils.GetEnv("RESULT_KEY_TEMPLATE", defaultResultKeyTemplate),
+		ResultEncryption:       utils.GetEnv("RESULT_SSE", ""),
+		ResultKMSKeyID:         utils.GetEnv("RESULT_KMS_KEY_ID", ""),
+		ResultStorageClass:     utils.GetEnv("RESULT_STORAGE_CLASS", ""),
+		ResultChecksum:         utils.GetEnv("RESULT_CHECKSUM_ALGORITHM", "SHA256"),
+		PresignTTL:             utils.GetEnvDuration("PRESIGN_URL_TTL", time.Hour),
+		PresignEndpoint:        utils.GetEnv("PRESIGN_ENDPOINT", ""),
+
+		UploadPrefix:             utils.GetEnv("UPLOAD_PREFIX", defaultUploadPrefix),
+		UploadURLTTL:             utils.GetEnvDuration("UPLOAD_URL_TTL", 15*time.Minute),
+		UploadMultipartThreshold: int64(utils.GetEnvInt("UPLOAD_MULTIPART_THRESHOLD", 100<<20)),
+		UploadPartSize:           int64(utils.GetEnvInt("UPLOAD_PART_SIZE", 64<<20)),
+		UploadConfirmMode:        utils.GetEnv("UPLOAD_CONFIRM_MODE", UploadConfirmClient),
+		PollingInterval:          utils.GetEnvDuration("POLLING_INTERVAL_SECONDS", 5*time.Second),
+		PollBackoffMin:           utils.GetEnvDuration("POLL_BACKOFF_MIN", 200*time.Millisecond),
+		WaitTimeSeconds:          int32(utils.GetEnvInt("SQS_WAIT_TIME_SECONDS", 20)),
+		MaxMessages:              int32(utils.GetEnvInt("MAX_MESSAGES", 10)),
+		ShutdownGracePeriod:      utils.GetEnvDuration("SHUTDOWN_GRACE_PERIOD_SECONDS", 30*time.Second),
+
+		WebhookURL:          utils.GetEnv("WEBHOOK_URL", ""),
+		WebhookSecret:       utils.GetEnv("WEBHOOK_SECRET", ""),
+		WebhookAllowedHosts: splitList(utils.GetEnv("WEBHOOK_ALLOWED_HOSTS", "")),
+		WebhookMaxAttempts:  utils.GetEnvInt("WEBHOOK_MAX_ATTEMPTS", 5),
+		WebhookTimeout:      utils.GetEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookFailedDir:    utils.GetEnv("WEBHOOK_FAILED_DIR", "webhooks/failed"),
+
+		AWSMaxAttempts:          utils.GetEnvInt("AWS_MAX_ATTEMPTS", 3),
+		AWSRetryBackoffMin:      utils.GetEnvDuration("AWS_RETRY_BACKOFF_MIN", 200*time.Millisecond),
+		AWSRetryBackoffMax:      utils.GetEnvDuration("AWS_RETRY_BACKOFF_MAX", 5*time.Second),
+		AWSOperationTimeout:     utils.GetEnvDuration("AWS_OPERATION_TIMEOUT", 30*time.Second),
+		CircuitBreakerThreshold: utils.GetEnvInt("CIRCUIT_BREAKER_THRESHOLD", 5),
+		CircuitBreakerCooldown:  utils.GetEnvDuration("CIRCUIT_BREAKER_COOLDOWN", 30*time.Second),
+
+		SQSBatchSize:          utils.GetEnvInt("SQS_BATCH_SIZE", 10),
+		SQSBatchFlushInterval: utils.GetEnvDuration("SQS_BATCH_FLUSH_INTERVAL", 500*time.Millisecond),
+
+		DiskExpansionFactor: utils.GetEnvFloat("DISK_EXPANSION_FACTOR", 3),
+		DiskReserveBytes:    int64(utils.GetEnvInt("DISK_RESERVE_BYTES", 512<<20)),
+	}
+}
+
+// Buckets configurados são sempre permitidos; os demais precisam estar na allow-list
+func (c MessageProcessorConfig) isBucketAllowed(bucket string) bool {
+	if bucket == c.SourceBucket || bucket == c.ResultsBucket {
+		return true
+	}
+	for _, allowed := range c.AllowedBuckets {
+		if bucket == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Separar pares chave=valor por vírgula (ex: "tenant-a=keep,tenant-b=archive")
+func parseKeyValues(value string) map[string]string {
+	pairs := make(map[string]string)
+	for _, item := range splitList(value) {
+		if key, val, ok := strings.Cut(item, "="); ok && strings.TrimSpace(key) != "" {
+			pairs[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		}
+	}
+	return pairs
+}
+
+// Separar lista de valores por vírgula, ignorando itens vazios
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadMessageProcessorConfig_Padroes(t *testing.T) {
+	os.Unsetenv("SHUTDOWN_GRACE_PERIOD_SECONDS")
+	os.Unsetenv("MAX_MESSAGES")
+	config := LoadMessageProcessorConfig()
+	if config.ShutdownGracePeriod != 30*time.Second {
+		t.Errorf("Esperado 30s, obtido %s", config.ShutdownGracePeriod)
+	}
+	if config.MaxMessages != 10 {
+		t.Errorf("Esperado 10, obtido %d", config.MaxMessages)
+	}
+}
+
+func TestLoadMessageProcessorConfig_Variaveis(t *testing.T) {
+	os.Setenv("SHUTDOWN_GRACE_PERIOD_SECONDS", "45")
+	os.Setenv("SOURCE_BUCKET", "bucket-env")
+	defer os.Unsetenv("SHUTDOWN_GRACE_PERIOD_SECONDS")
+	defer os.Unsetenv("SOURCE_BUCKET")
+
+	config := LoadMessageProcessorConfig()
+	if config.ShutdownGracePeriod != 45*time.Second {
+		t.Errorf("Esperado 45s, obtido %s", config.ShutdownGracePeriod)
+	}
+	if config.SourceBucket != "bucket-env" {
+		t.Errorf("Esperado 'bucket-env', obtido '%s'", config.SourceBucket)
+	}
+}
+
+func TestLoadMessageProcessorConfig_AllowedBuckets(t *testing.T) {
+	os.Setenv("ALLOWED_BUCKETS", "tenant-a, tenant-b,,")
+	defer os.Unsetenv("ALLOWED_BUCKETS")
+
+	config := LoadMessageProcessorConfig()
+	if len(config.AllowedBuckets) != 2 || config.AllowedBuckets[1] != "tenant-b" {
+		t.Errorf("Esperado [tenant-a tenant-b], obtido %v", config.AllowedBuckets)
+	}
+	if !config.isBucketAllowed(config.SourceBucket) {
+		t.Error("Esperado bucket de origem configurado sempre permitido")
+	}
+}
+
+func TestLoadMessageProcessorConfig_RetencaoPorTenant(t *testing.T) {
+	os.Setenv("SOURCE_RETENTION_TENANTS", "acme=archive, beta = tag,invalido")
+	defer os.Unsetenv("SOURCE_RETENTION_TENANTS")
+
+	config := LoadMessageProcessorConfig()
+	if len(config.RetentionByTenant) != 2 || config.RetentionByTenant["acme"] != "archive" || config.RetentionByTenant["beta"] != "tag" {
+		t.Errorf("Esperado map[acme:archive beta:tag], obtido %v", config.RetentionByTenant)
+	}
+	if config.SourceRetention != RetentionDelete {
+		t.Errorf("Esperado retenção padrão 'delete', obtido '%s'", config.SourceRetention)
+	}
+}
+
+func TestLoadMessageProcessorConfig_ArmazenamentoDoResultado(t *testing.T) {
+	os.Setenv("RESULT_SSE", "sse-kms")
+	os.Setenv("RESULT_KMS_KEY_ID", "alias/zips")
+	defer os.Unsetenv("RESULT_SSE")
+	defer os.Unsetenv("RESULT_KMS_KEY_ID")
+
+	config := LoadMessageProcessorConfig()
+	if config.ResultChecksum != "SHA256" {
+		t.Errorf("Esperado checksum padrão 'SHA256', obtido '%s'", config.ResultChecksum)
+	}
+	if err := config.validateResultStorage(); err != nil {
+		t.Errorf("Erro inesperado: %v", err)
+	}
+	config.ResultEncryption = "AES256"
+	if err := config.validateResultStorage(); err == nil {
+		t.Error("Esperado erro para chave KMS sem aws:kms")
+	}
+}
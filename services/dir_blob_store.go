@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Diretório (dentro da raiz) com os metadados dos objetos gravados por Put
+const blobMetaDir = ".meta"
+
+// Armazenamento em diretório local: o objeto bucket/key fica em <raiz>/<bucket>/<key>
+// (com bucket vazio, direto em <raiz>/<key>).
+// Tipo de conteúdo, metadados e ETag (MD5) ficam em <raiz>/.meta/<bucket>/<key>.json.
+type dirBlobStore struct {
+	root string
+}
+
+// Metadados gravados junto ao objeto
+type blobSidecar struct {
+	ETag               string            `json:"etag,omitempty"`
+	ContentType        string            `json:"contentType,omitempty"`
+	ContentDisposition string            `json:"contentDisposition,omitempty"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
+	Tags               map[string]string `json:"tags,omitempty"`
+	Encryption         string            `json:"encryption,omitempty"`
+	KMSKeyID           string            `json:"kmsKeyId,omitempty"`
+	StorageClass       string            `json:"storageClass,omitempty"`
+	ChecksumAlgorithm  string            `json:"checksumAlgorithm,omitempty"`
+	Checksum           string            `json:"checksum,omitempty"`
+}
+
+func NewDirBlobStore(root string) BlobStore {
+	return &dirBlobStore{root: root}
+}
+
+// Caminho local do objeto, recusando chaves que escapem do bucket
+func (s *dirBlobStore) path(bucket, key string) (string, error) {
+	if strings.Contains(bucket, "/") || strings.Contains(bucket, `\`) || bucket == ".." || bucket == blobMetaDir {
+		return "", fmt.Errorf("bucket inválido: %q", bucket)
+	}
+	clean := filepath.Clean("/" + key)
+	if key == "" || clean == "/" || clean != "/"+strings.TrimPrefix(key, "/") || (bucket == "" && isBlobMetaKey(clean)) {
+		return "", fmt.Errorf("chave inválida: %q", key)
+	}
+	return filepath.Join(s.root, bucket, filepath.FromSlash(clean)), nil
+}
+
+// Chave dentro do diretório de metadados (só alcançável com bucket vazio)
+func isBlobMetaKey(clean string) bool {
+	return clean == "/"+blobMetaDir || strings.HasPrefix(clean, "/"+blobMetaDir+"/")
+}
+
+func (s *dirBlobStore) sidecarPath(bucket, key string) string {
+	return filepath.Join(s.root, blobMetaDir, bucket, filepath.FromSlash(filepath.Clean("/"+key))+".json")
+}
+
+func (s *dirBlobStore) Get(ctx context.Context, bucket, key string) (io.ReadCloser, ObjectInfo, error) {
+	info, err := s.Head(ctx, bucket, key)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	path, _ := s.path(bucket, key)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, ObjectInfo{}, notFoundError(err)
+	}
+	return file, info, nil
+}
+
+func (s *dirBlobStore) Put(ctx context.Context, bucket, key string, body io.Reader, opts PutOptions) (ObjectInfo, error) {
+	path, err := s.path(bucket, key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return ObjectInfo{}, fmt.Errorf("erro ao criar diretório: %w", err)
+	}
+
+	// Gravar em arquivo temporário e renomear: leitores nunca veem objetos incompletos
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".put-*")
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("erro ao criar arquivo: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	// Como no S3, o checksum do algoritmo pedido é calculado na gravação
+	hash := md5.New()
+	writers := []io.Writer{tmp, hash}
+	checksum := newChecksumHash(opts.ChecksumAlgorithm)
+	if checksum != nil {
+		writers = append(writers, checksum)
+	}
+	size, err := io.Copy(io.MultiWriter(writers...), body)
+	if err != nil {
+		tmp.Close()
+		return ObjectInfo{}, fmt.Errorf("erro ao gravar objeto: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return ObjectInfo{}, fmt.Errorf("erro ao gravar objeto: %w", err)
+	}
+
+	sidecar := blobSidecar{
+		ETag:               `"` + hex.EncodeToString(hash.Sum(nil)) + `"`,
+		ContentType:        opts.ContentType,
+		ContentDisposition: opts.ContentDisposition,
+		Metadata:           opts.Metadata,
+		Tags:               opts.Tags,
+		Encryption:         opts.Encryption,
+		KMSKeyID:           opts.KMSKeyID,
+		StorageClass:       opts.StorageClass,
+		ChecksumAlgorithm:  opts.ChecksumAlgorithm,
+	}
+	if checksum != nil {
+		sidecar.Checksum = encodeChecksum(checksum)
+		if opts.Checksum != "" && opts.Checksum != sidecar.Checksum {
+			return ObjectInfo{}, fmt.Errorf("checksum %s divergente: esperado %s, calculado %s", opts.ChecksumAlgorithm, opts.Checksum, sidecar.Checksum)
+		}
+	}
+	data, _ := json.Marshal(sidecar)
+	sidecarPath := s.sidecarPath(bucket, key)
+	os.MkdirAll(filepath.Dir(sidecarPath), 0755)
+	if err := os.WriteFile(sidecarPath, data, 0644); err != nil {
+		return ObjectInfo{}, fmt.Errorf("erro ao gravar metadados: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return ObjectInfo{}, fmt.Errorf("erro ao gravar objeto: %w", err)
+	}
+	return sidecar.objectInfo(key, size), nil
+}
+
+func (s *dirBlobStore) Delete(ctx context.Context, bucket, key string) error {
+	path, err := s.path(bucket, key)
+	if err != nil {
+		return err
+	}
+	// Como no S3, remover objeto inexistente não é erro
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("erro ao remover objeto: %w", err)
+	}
+	os.Remove(s.sidecarPath(bucket, key))
+	return nil
+}
+
+func (s *dirBlobStore) Head(ctx context.Context, bucket, key string) (ObjectInfo, error) {
+	path, err := s.path(bucket, key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return ObjectInfo{}, notFoundError(err)
+	}
+	if stat.IsDir() {
+		return ObjectInfo{}, fmt.Errorf("%w: %s/%s", ErrBlobNotFound, bucket, key)
+	}
+
+	info := ObjectInfo{Key: key, Size: stat.Size()}
+	if sidecar, err := s.readSidecar(bucket, key); err == nil {
+		info = sidecar.objectInfo(key, stat.Size())
+	}
+	info.LastModified = stat.ModTime()
+	return info, nil
+}
+
+func (s *dirBlobStore) List(ctx context.Context, bucket, prefix string) ([]ObjectInfo, error) {
+	base := filepath.Join(s.root, bucket)
+	var objects []ObjectInfo
+	err := filepath.WalkDir(base, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == base {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() && bucket == "" && filepath.Dir(path) == filepath.Clean(base) && d.Name() == blobMetaDir {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".put-") {
+			return nil
+		}
+		rel, _ := filepath.Rel(base, path)
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		objects = append(objects, ObjectInfo{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar %s: %w", bucket, err)
+	}
+	return objects, nil
+}
+
+func (s *dirBlobStore) Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error {
+	body, info, err := s.Get(ctx, srcBucket, srcKey)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	// Como no CopyObject, as tags acompanham o objeto
+	sidecar, _ := s.readSidecar(srcBucket, srcKey)
+	_, err = s.Put(ctx, dstBucket, dstKey, body, PutOptions{
+		ContentType:        info.ContentType,
+		ContentDisposition: info.ContentDisposition,
+		Metadata:           info.Metadata,
+		Tags:               sidecar.Tags,
+	})
+	return err
+}
+
+func (s *dirBlobStore) Tag(ctx context.Context, bucket, key string, tags map[string]string) error {
+	if _, err := s.Head(ctx, bucket, key); err != nil {
+		return err
+	}
+	sidecar, _ := s.readSidecar(bucket, key)
+	sidecar.Tags = tags
+	data, _ := json.Marshal(sidecar)
+	sidecarPath := s.sidecarPath(bucket, key)
+	os.MkdirAll(filepath.Dir(sidecarPath), 0755)
+	if err := os.WriteFile(sidecarPath, data, 0644); err != nil {
+		return fmt.Errorf("erro ao gravar tags: %w", err)
+	}
+	return nil
+}
+
+func (s *dirBlobStore) Tags(ctx context.Context, bucket, key string) (map[string]string, error) {
+	if _, err := s.Head(ctx, bucket, key); err != nil {
+		return nil, err
+	}
+	sidecar, _ := s.readSidecar(bucket, key)
+	if sidecar.Tags == nil {
+		return map[string]string{}, nil
+	}
+	return sidecar.Tags, nil
+}
+
+func (s *dirBlobStore) readSidecar(bucket, key string) (blobSidecar, error) {
+	var sidecar blobSidecar
+	data, err := os.ReadFile(s.sidecarPath(bucket, key))
+	if err != nil {
+		return sidecar, err
+	}
+	err = json.Unmarshal(data, &sidecar)
+	return sidecar, err
+}
+
+// Informações do objeto a partir dos metadados gravados
+func (sc blobSidecar) objectInfo(key string, size int64) ObjectInfo {
+	return ObjectInfo{
+		Key:                key,
+		Size:               size,
+		ETag:               sc.ETag,
+		ContentType:        sc.ContentType,
+		ContentDisposition: sc.ContentDisposition,
+		Metadata:           sc.Metadata,
+		Encryption:         sc.Encryption,
+		KMSKeyID:           sc.KMSKeyID,
+		StorageClass:       sc.StorageClass,
+		ChecksumAlgorithm:  sc.ChecksumAlgorithm,
+		Checksum:           sc.Checksum,
+	}
+}
+
+func notFoundError(err error) error {
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%w: %v", ErrBlobNotFound, err)
+	}
+	return err
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirBlobStore_PutGetHead(t *testing.T) {
+	store := NewDirBlobStore(t.TempDir())
+	opts := PutOptions{ContentType: "application/zip", Metadata: map[string]string{"correlation-id": "c1"}}
+	if _, err := store.Put(context.TODO(), "resultados", "processed/a.zip", strings.NewReader("conteudo"), opts); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	body, info, err := store.Get(context.TODO(), "resultados", "processed/a.zip")
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "conteudo" || info.Size != 8 {
+		t.Errorf("Esperado 'conteudo' (8 bytes), obtido '%s' (%d)", data, info.Size)
+	}
+	if info.ContentType != "application/zip" || info.Metadata["correlation-id"] != "c1" {
+		t.Errorf("Esperado metadados preservados, obtido %+v", info)
+	}
+	if len(info.ETag) != 34 {
+		t.Errorf("Esperado ETag MD5 entre aspas, obtido %s", info.ETag)
+	}
+}
+
+func TestDirBlobStore_ObjetoInexistente(t *testing.T) {
+	store := NewDirBlobStore(t.TempDir())
+	if _, err := store.Head(context.TODO(), "bucket", "nada.mp4"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Esperado ErrBlobNotFound, obtido %v", err)
+	}
+	if _, _, err := store.Get(context.TODO(), "bucket", "nada.mp4"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Esperado ErrBlobNotFound, obtido %v", err)
+	}
+	if err := store.Delete(context.TODO(), "bucket", "nada.mp4"); err != nil {
+		t.Errorf("Esperado remoção idempotente, obtido %v", err)
+	}
+}
+
+func TestDirBlobStore_RecusaChaveForaDoBucket(t *testing.T) {
+	store := NewDirBlobStore(t.TempDir())
+	for _, key := range []string{"../fora.txt", "a/../../fora.txt", ""} {
+		if _, err := store.Put(context.TODO(), "bucket", key, strings.NewReader("x"), PutOptions{}); err == nil {
+			t.Errorf("Esperado erro para chave %q", key)
+		}
+	}
+	if _, err := store.Put(context.TODO(), "..", "a.txt", strings.NewReader("x"), PutOptions{}); err == nil {
+		t.Error("Esperado erro para bucket inválido")
+	}
+}
+
+func TestDirBlobStore_BucketVazioUsaARaiz(t *testing.T) {
+	root := t.TempDir()
+	store := NewDirBlobStore(root)
+	if _, err := store.Put(context.TODO(), "", "processed/a.zip", strings.NewReader("a"), PutOptions{ContentType: "application/zip"}); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "processed", "a.zip")); err != nil {
+		t.Errorf("Esperado objeto direto na raiz, obtido %v", err)
+	}
+	if _, err := store.Put(context.TODO(), "", blobMetaDir+"/x.json", strings.NewReader("x"), PutOptions{}); err == nil {
+		t.Error("Esperado erro para chave nos metadados")
+	}
+
+	objects, err := store.List(context.TODO(), "", "")
+	if err != nil || len(objects) != 1 || objects[0].Key != "processed/a.zip" {
+		t.Errorf("Esperado só processed/a.zip (sem metadados), obtido %v (%v)", objects, err)
+	}
+}
+
+func TestDirBlobStore_ListEDelete(t *testing.T) {
+	store := NewDirBlobStore(t.TempDir())
+	store.Put(context.TODO(), "bucket", "videos/a.mp4", strings.NewReader("a"), PutOptions{})
+	store.Put(context.TODO(), "bucket", "videos/b.mp4", strings.NewReader("b"), PutOptions{})
+	store.Put(context.TODO(), "bucket", "outros/c.mp4", strings.NewReader("c"), PutOptions{})
+
+	objects, err := store.List(context.TODO(), "bucket", "videos/")
+	if err != nil || len(objects) != 2 {
+		t.Fatalf("Esperado 2 objetos, obtido %v (%v)", objects, err)
+	}
+
+	store.Delete(context.TODO(), "bucket", "videos/a.mp4")
+	objects, _ = store.List(context.TODO(), "bucket", "videos/")
+	if len(objects) != 1 || objects[0].Key != "videos/b.mp4" {
+		t.Errorf("Esperado apenas videos/b.mp4, obtido %v", objects)
+	}
+
+	if objects, err := store.List(context.TODO(), "inexistente", ""); err != nil || len(objects) != 0 {
+		t.Errorf("Esperado lista vazia para bucket inexistente, obtido %v (%v)", objects, err)
+	}
+}
+
+func TestMessageProcessor_BackendDir(t *testing.T) {
+	root := t.TempDir()
+	mp := &MessageProcessor{config: MessageProcessorConfig{BlobBackend: BlobBackendDir, BlobDir: root}}
+	NewDirBlobStore(root).Put(context.TODO(), "videos", "a.mp4", strings.NewReader("video"), PutOptions{})
+
+	localPath, err := mp.DownloadFromS3(context.TODO(), "videos", "a.mp4")
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	defer os.Remove(localPath)
+	if data, _ := os.ReadFile(localPath); string(data) != "video" {
+		t.Errorf("Esperado conteúdo baixado do diretório, obtido '%s'", data)
+	}
+}
+
+func TestDirBlobStore_CopyPreservaTags(t *testing.T) {
+	store := NewDirBlobStore(t.TempDir())
+	opts := PutOptions{ContentType: "video/mp4", Metadata: map[string]string{"origem": "upload"}}
+	store.Put(context.TODO(), "videos", "a.mp4", strings.NewReader("video"), opts)
+	if err := store.Tag(context.TODO(), "videos", "a.mp4", map[string]string{"processed": "true"}); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if err := store.Copy(context.TODO(), "videos", "a.mp4", "arquivo", "archive/a.mp4"); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	info, err := store.Head(context.TODO(), "arquivo", "archive/a.mp4")
+	if err != nil || info.Size != 5 || info.ContentType != "video/mp4" || info.Metadata["origem"] != "upload" {
+		t.Errorf("Esperado cópia com metadados preservados, obtido %+v (%v)", info, err)
+	}
+	sidecar, _ := store.(*dirBlobStore).readSidecar("arquivo", "archive/a.mp4")
+	if sidecar.Tags["processed"] != "true" {
+		t.Errorf("Esperado tags copiadas, obtido %v", sidecar.Tags)
+	}
+}
+
+func TestDirBlobStore_TagObjetoInexistente(t *testing.T) {
+	store := NewDirBlobStore(t.TempDir())
+	if err := store.Tag(context.TODO(), "videos", "nada.mp4", map[string]string{"a": "b"}); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Esperado ErrBlobNotFound, obtido %v", err)
+	}
+}
+
+func TestDirBlobStore_PutContentDispositionETags(t *testing.T) {
+	store := NewDirBlobStore(t.TempDir())
+	opts := PutOptions{ContentDisposition: `attachment; filename="a.zip"`, Tags: map[string]string{"frame-count": "3"}}
+	store.Put(context.TODO(), "resultados", "a.zip", strings.NewReader("zip"), opts)
+
+	info, err := store.Head(context.TODO(), "resultados", "a.zip")
+	if err != nil || info.ContentDisposition != `attachment; filename="a.zip"` {
+		t.Errorf("Esperado Content-Disposition preservado, obtido %+v (%v)", info, err)
+	}
+	sidecar, _ := store.(*dirBlobStore).readSidecar("resultados", "a.zip")
+	if sidecar.Tags["frame-count"] != "3" {
+		t.Errorf("Esperado tags gravadas, obtido %v", sidecar.Tags)
+	}
+}
+
+func TestDirBlobStore_PutChecksum(t *testing.T) {
+	store := NewDirBlobStore(t.TempDir())
+	opts := PutOptions{StorageOptions: StorageOptions{Encryption: EncryptionS3, ChecksumAlgorithm: "CRC32"}}
+	info, err := store.Put(context.TODO(), "bucket", "a.zip", strings.NewReader("abc"), opts)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if info.Checksum != "NSRBwg==" || info.Size != 3 {
+		t.Errorf("Esperado CRC32 'NSRBwg==' e tamanho 3, obtido %+v", info)
+	}
+	head, _ := store.Head(context.TODO(), "bucket", "a.zip")
+	if head.Checksum != info.Checksum || head.Encryption != EncryptionS3 {
+		t.Errorf("Esperado checksum e criptografia no Head, obtido %+v", head)
+	}
+
+	opts.Checksum = "AAAAAA=="
+	if _, err := store.Put(context.TODO(), "bucket", "b.zip", strings.NewReader("abc"), opts); err == nil {
+		t.Error("Esperado erro para checksum divergente")
+	}
+	if _, err := store.Head(context.TODO(), "bucket", "b.zip"); err == nil {
+		t.Error("Objeto com checksum divergente não deveria ser gravado")
+	}
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Intervalo entre leituras do diretório durante o long polling
+const dirQueuePollInterval = 250 * time.Millisecond
+
+// Separador entre o nome do arquivo e o token do recebimento em inflight/
+const dirQueueTokenSep = "~"
+
+// Fila baseada em diretório: cada mensagem é um arquivo JSON. Mensagens
+// recebidas são movidas para inflight/, com o prazo de visibilidade gravado
+// no mtime do arquivo; o rename garante que só um worker recebe cada arquivo.
+// O nome em inflight/ leva um token por recebimento (<arquivo>~<token>), que é
+// o Handle da mensagem: depois que a visibilidade expira e outro worker recebe
+// o arquivo, Ack/Nack/ExtendVisibility com o Handle antigo não o afetam.
+//
+// Arquivos colocados manualmente no diretório podem conter diretamente o
+// corpo da mensagem (ex: {"fileId": "...", "processId": "..."}).
+type dirQueue struct {
+	dir        string
+	inFlight   string
+	visibility time.Duration
+}
+
+// Conteúdo do arquivo de uma mensagem publicada
+type dirQueueFile struct {
+	ID         string            `json:"id"`
+	Body       string            `json:"body"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+func newDirQueue(dir string, visibility time.Duration) *dirQueue {
+	q := &dirQueue{dir: dir, inFlight: filepath.Join(dir, "inflight"), visibility: visibility}
+	// Garantir que os diretórios existem; falhas aparecem no Receive/Publish
+	os.MkdirAll(q.inFlight, 0755)
+	return q
+}
+
+func (q *dirQueue) Receive(ctx context.Context, max int32, wait time.Duration) ([]QueueMessage, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		q.requeueExpired()
+		messages, err := q.claim(int(max))
+		if err != nil || len(messages) > 0 || !time.Now().Before(deadline) {
+			return messages, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(dirQueuePollInterval):
+		}
+	}
+}
+
+// Mover até max arquivos (em ordem de nome) para inflight/
+func (q *dirQueue) claim(max int) ([]QueueMessage, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar fila %s: %w", q.dir, err)
+	}
+	if max <= 0 {
+		max = 1
+	}
+
+	var messages []QueueMessage
+	deadline := time.Now().Add(q.visibility)
+	for _, entry := range entries {
+		if len(messages) >= max {
+			break
+		}
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".json" {
+			continue
+		}
+
+		path := filepath.Join(q.dir, name)
+		handle := name + dirQueueTokenSep + randomHex(8)
+		claimed := filepath.Join(q.inFlight, handle)
+		// O prazo é gravado antes do rename para que outro worker não o considere expirado
+		os.Chtimes(path, deadline, deadline)
+		if err := os.Rename(path, claimed); err != nil {
+			continue // Recebido por outro worker
+		}
+
+		data, err := os.ReadFile(claimed)
+		if err != nil {
+			os.Rename(claimed, path)
+			continue
+		}
+		message := decodeDirQueueFile(name, data)
+		message.Handle = handle
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+func decodeDirQueueFile(name string, data []byte) QueueMessage {
+	var file dirQueueFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Body == "" {
+		file = dirQueueFile{Body: string(data)}
+	}
+	if file.ID == "" {
+		file.ID = strings.TrimSuffix(name, ".json")
+	}
+	return QueueMessage{ID: file.ID, Body: file.Body, Attributes: file.Attributes, Handle: name}
+}
+
+// Devolver para a fila os arquivos com visibility timeout expirado
+func (q *dirQueue) requeueExpired() {
+	entries, err := os.ReadDir(q.inFlight)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(now) {
+			continue
+		}
+		os.Rename(filepath.Join(q.inFlight, entry.Name()), filepath.Join(q.dir, claimedName(entry.Name())))
+	}
+}
+
+// Nome original do arquivo recebido, sem o token do recebimento
+func claimedName(handle string) string {
+	if i := strings.LastIndex(handle, dirQueueTokenSep); i > 0 {
+		return handle[:i]
+	}
+	return handle
+}
+
+// Erro de um Handle cujo recebimento expirou (o arquivo voltou para a fila ou
+// foi recebido de novo, com outro token)
+func staleHandleError(action string, msg QueueMessage, err error) error {
+	if os.IsNotExist(err) {
+		return fmt.Errorf("erro ao %s mensagem %s: recebimento expirado", action, msg.ID)
+	}
+	return fmt.Errorf("erro ao %s mensagem %s: %w", action, msg.ID, err)
+}
+
+func (q *dirQueue) Ack(ctx context.Context, msg QueueMessage) error {
+	if err := os.Remove(filepath.Join(q.inFlight, filepath.Base(msg.Handle))); err != nil {
+		return staleHandleError("remover", msg, err)
+	}
+	return nil
+}
+
+func (q *dirQueue) Nack(ctx context.Context, msg QueueMessage) error {
+	handle := filepath.Base(msg.Handle)
+	if err := os.Rename(filepath.Join(q.inFlight, handle), filepath.Join(q.dir, claimedName(handle))); err != nil {
+		return staleHandleError("devolver", msg, err)
+	}
+	return nil
+}
+
+func (q *dirQueue) ExtendVisibility(ctx context.Context, msg QueueMessage, timeout time.Duration) error {
+	if timeout <= 0 {
+		return q.Nack(ctx, msg)
+	}
+	deadline := time.Now().Add(timeout)
+	if err := os.Chtimes(filepath.Join(q.inFlight, filepath.Base(msg.Handle)), deadline, deadline); err != nil {
+		return staleHandleError("estender visibilidade da", msg, err)
+	}
+	return nil
+}
+
+func (q *dirQueue) Publish(ctx context.Context, msg OutgoingMessage) error {
+	file := dirQueueFile{ID: newUUID(), Body: msg.Body, Attributes: msg.Attributes}
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar mensagem: %w", err)
+	}
+
+	// Gravar em arquivo oculto e renomear, para que receptores nunca leiam arquivos incompletos
+	name := fmt.Sprintf("%020d_%s.json", time.Now().UnixNano(), file.ID)
+	tmp := filepath.Join(q.dir, "."+name+".tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("erro ao gravar mensagem: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(q.dir, name)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("erro ao publicar mensagem: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDirQueue_PublicaRecebeConfirma(t *testing.T) {
+	dir := t.TempDir()
+	q := newDirQueue(dir, time.Minute)
+
+	q.Publish(context.TODO(), OutgoingMessage{Body: `{"fileId":"a.mp4"}`, Attributes: map[string]string{"traceparent": "tp"}})
+	messages, err := q.Receive(context.TODO(), 10, 0)
+	if err != nil || len(messages) != 1 {
+		t.Fatalf("Esperado 1 mensagem, obtido %d (%v)", len(messages), err)
+	}
+	if messages[0].Body != `{"fileId":"a.mp4"}` || messages[0].Attributes["traceparent"] != "tp" {
+		t.Errorf("Mensagem inesperada: %+v", messages[0])
+	}
+
+	if again, _ := q.Receive(context.TODO(), 10, 0); len(again) != 0 {
+		t.Errorf("Esperado mensagem invisível enquanto em processamento, obtido %d", len(again))
+	}
+	if err := q.Ack(context.TODO(), messages[0]); err != nil {
+		t.Errorf("Esperado nil, obtido %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, "inflight")); len(entries) != 0 {
+		t.Errorf("Esperado inflight/ vazio após Ack, obtido %d arquivo(s)", len(entries))
+	}
+}
+
+func TestDirQueue_ArquivoComCorpoDireto(t *testing.T) {
+	dir := t.TempDir()
+	q := newDirQueue(dir, time.Minute)
+	os.WriteFile(filepath.Join(dir, "job1.json"), []byte(`{"fileId":"a.mp4","processId":"p1"}`), 0644)
+
+	messages, _ := q.Receive(context.TODO(), 1, 0)
+	if len(messages) != 1 || messages[0].ID != "job1" || messages[0].Body != `{"fileId":"a.mp4","processId":"p1"}` {
+		t.Errorf("Esperado arquivo lido como corpo da mensagem, obtido %+v", messages)
+	}
+}
+
+func TestDirQueue_NackEVisibilidadeExpirada(t *testing.T) {
+	dir := t.TempDir()
+	q := newDirQueue(dir, time.Minute)
+	q.Publish(context.TODO(), OutgoingMessage{Body: "a"})
+
+	messages, _ := q.Receive(context.TODO(), 1, 0)
+	q.Nack(context.TODO(), messages[0])
+	messages, _ = q.Receive(context.TODO(), 1, 0)
+	if len(messages) != 1 {
+		t.Fatalf("Esperado mensagem devolvida pelo Nack, obtido %d", len(messages))
+	}
+
+	// Prazo de visibilidade no passado: a mensagem volta para a fila
+	past := time.Now().Add(-time.Second)
+	os.Chtimes(filepath.Join(dir, "inflight", messages[0].Handle), past, past)
+	if again, _ := q.Receive(context.TODO(), 1, 0); len(again) != 1 {
+		t.Errorf("Esperado mensagem entregue novamente após expirar a visibilidade, obtido %d", len(again))
+	}
+}
+
+func TestDirQueue_HandleExpiradoNaoAfetaNovoRecebimento(t *testing.T) {
+	dir := t.TempDir()
+	first := newDirQueue(dir, time.Minute)
+	second := newDirQueue(dir, time.Minute)
+	first.Publish(context.TODO(), OutgoingMessage{Body: "a"})
+
+	stale, _ := first.Receive(context.TODO(), 1, 0)
+	past := time.Now().Add(-time.Second)
+	os.Chtimes(filepath.Join(dir, "inflight", stale[0].Handle), past, past)
+
+	// Visibilidade expirada: outro worker recebe o mesmo arquivo
+	current, _ := second.Receive(context.TODO(), 1, 0)
+	if len(current) != 1 || current[0].Handle == stale[0].Handle {
+		t.Fatalf("Esperado novo recebimento com outro Handle, obtido %+v", current)
+	}
+
+	if err := first.Ack(context.TODO(), stale[0]); err == nil {
+		t.Error("Esperado erro no Ack com Handle expirado")
+	}
+	if err := first.Nack(context.TODO(), stale[0]); err == nil {
+		t.Error("Esperado erro no Nack com Handle expirado")
+	}
+	if err := first.ExtendVisibility(context.TODO(), stale[0], time.Minute); err == nil {
+		t.Error("Esperado erro ao estender visibilidade com Handle expirado")
+	}
+	if again, _ := first.Receive(context.TODO(), 1, 0); len(again) != 0 {
+		t.Error("Recebimento atual não deveria ser devolvido para a fila")
+	}
+	if err := second.Ack(context.TODO(), current[0]); err != nil {
+		t.Errorf("Esperado Ack do recebimento atual, obtido %v", err)
+	}
+}
@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Quem enfileira o processamento de um upload direto (UPLOAD_CONFIRM_MODE)
+const (
+	UploadConfirmClient = "confirm" // O cliente chama /api/uploads/:processId/complete (padrão)
+	UploadConfirmEvent  = "event"   // A notificação do S3 para o bucket de origem
+)
+
+// Prefixo padrão das chaves de upload direto
+const defaultUploadPrefix = "uploads/"
+
+// Limites do S3 (o PUT simples vai até o tamanho máximo de uma parte)
+const (
+	minUploadPartSize = 5 << 20
+	maxUploadPartSize = 5 << 30
+	maxUploadParts    = 10000
+	maxUploadSize     = 5 << 40
+)
+
+// Tag do vídeo com upload confirmado (a mesma que a retenção "tag" grava)
+const uploadConfirmedTag = "processId"
+
+// ErrInvalidUpload indica pedido de upload ou confirmação inválido (erro do cliente)
+var ErrInvalidUpload = errors.New("upload inválido")
+
+// Pedido de URL de upload direto para o bucket de origem
+type DirectUploadRequest struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType,omitempty"`
+	Size        int64  `json:"size,omitempty"`      // Com tamanho acima de UPLOAD_MULTIPART_THRESHOLD, usa multipart
+	Multipart   bool   `json:"multipart,omitempty"` // Força multipart
+}
+
+// URL de uma parte do multipart upload
+type UploadPartURL struct {
+	PartNumber int32  `json:"partNumber"`
+	URL        string `json:"url"`
+}
+
+// Upload direto emitido: uma URL PUT ou as URLs das partes do multipart
+type DirectUpload struct {
+	ProcessID string            `json:"processId"`
+	Bucket    string            `json:"bucket"`
+	Key       string            `json:"key"`
+	Method    string            `json:"method"` // PUT ou MULTIPART
+	URL       string            `json:"url,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"` // Cabeçalhos que o PUT deve enviar
+	UploadID  string            `json:"uploadId,omitempty"`
+	PartSize  int64             `json:"partSize,omitempty"`
+	Parts     []UploadPartURL   `json:"parts,omitempty"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+// Parte enviada, com o ETag devolvido pelo S3 no PUT da parte
+type CompletedUploadPart struct {
+	PartNumber int32  `json:"partNumber"`
+	ETag       string `json:"etag"`
+}
+
+// Confirmação do upload pelo cliente
+type CompleteUploadRequest struct {
+	Key      string                `json:"key"`
+	UploadID string                `json:"uploadId,omitempty"`
+	Parts    []CompletedUploadPart `json:"parts,omitempty"`
+}
+
+// Validar o modo de confirmação e os tamanhos configurados
+func (c MessageProcessorConfig) validateDirectUploads() error {
+	switch c.UploadConfirmMode {
+	case "", UploadConfirmClient, UploadConfirmEvent:
+	default:
+		return fmt.Errorf("UPLOAD_CONFIRM_MODE desconhecido: %s", c.UploadConfirmMode)
+	}
+	if c.UploadPartSize != 0 && (c.UploadPartSize < minUploadPartSize || c.UploadPartSize > maxUploadPartSize) {
+		return fmt.Errorf("UPLOAD_PART_SIZE deve estar entre %d e %d bytes", minUploadPartSize, maxUploadPartSize)
+	}
+	return nil
+}
+
+// Prefixo das chaves de upload direto: <UPLOAD_PREFIX><processId>/<arquivo>
+func (c MessageProcessorConfig) uploadPrefix() string {
+	prefix := strings.Trim(c.UploadPrefix, "/")
+	if prefix == "" {
+		return defaultUploadPrefix
+	}
+	return prefix + "/"
+}
+
+// ProcessID de uma chave de upload direto ("" se a chave não for de upload direto)
+func (c MessageProcessorConfig) uploadProcessID(key string) string {
+	rest, ok := strings.CutPrefix(key, c.uploadPrefix())
+	if !ok {
+		return ""
+	}
+	processID, filename, ok := strings.Cut(rest, "/")
+	if !ok || processID == "" || filename == "" || strings.Contains(filename, "/") {
+		return ""
+	}
+	return processID
+}
+
+// CreateDirectUpload gera um novo ProcessID e as URLs para o cliente enviar o
+// vídeo direto ao bucket de origem, sem passar pelo pod
+func (mp *MessageProcessor) CreateDirectUpload(ctx context.Context, req DirectUploadRequest) (DirectUpload, error) {
+	if mp.presigner == nil || strings.ToLower(mp.config.BlobBackend) == BlobBackendDir {
+		return DirectUpload{}, ErrPresignUnavailable
+	}
+	filename := path.Base(strings.ReplaceAll(req.Filename, `\`, "/"))
+	if req.Filename == "" || filename == "." || filename == "/" {
+		return DirectUpload{}, fmt.Errorf("%w: nome de arquivo obrigatório", ErrInvalidUpload)
+	}
+	if req.Size < 0 || req.Size > maxUploadSize {
+		return DirectUpload{}, fmt.Errorf("%w: tamanho deve estar entre 0 e %d bytes (limite do S3)", ErrInvalidUpload, int64(maxUploadSize))
+	}
+
+	ttl := mp.config.UploadURLTTL
+	if ttl <= 0 {
+		ttl = 15 * time.Minute
+	}
+	upload := DirectUpload{
+		ProcessID: newUUID(),
+		Bucket:    mp.config.SourceBucket,
+		ExpiresAt: time.Now().Add(ttl).UTC(),
+	}
+	upload.Key = mp.config.uploadPrefix() + upload.ProcessID + "/" + filename
+
+	// Acima do tamanho máximo de um PUT, só o multipart é aceito pelo S3
+	threshold := mp.config.UploadMultipartThreshold
+	if req.Multipart || (threshold > 0 && req.Size > threshold) || req.Size > maxUploadPartSize {
+		return mp.createMultipartUpload(ctx, req, upload, ttl)
+	}
+
+	input := &s3.PutObjectInput{Bucket: aws.String(upload.Bucket), Key: aws.String(upload.Key)}
+	if req.ContentType != "" {
+		input.ContentType = aws.String(req.ContentType)
+		upload.Headers = map[string]string{"Content-Type": req.ContentType}
+	}
+	signed, err := mp.presigner.PresignPutObject(ctx, input, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return DirectUpload{}, fmt.Errorf("erro ao gerar URL de upload: %w", err)
+	}
+	upload.Method = "PUT"
+	upload.URL = signed.URL
+	logf(ctx, "🔗 Upload direto emitido: s3://%s/%s (ProcessID: %s)", upload.Bucket, upload.Key, upload.ProcessID)
+	return upload, nil
+}
+
+func (mp *MessageProcessor) createMultipartUpload(ctx context.Context, req DirectUploadRequest, upload DirectUpload, ttl time.Duration) (DirectUpload, error) {
+	if req.Size <= 0 {
+		return DirectUpload{}, fmt.Errorf("%w: tamanho obrigatório para multipart", ErrInvalidUpload)
+	}
+	// Partes maiores que o configurado se o arquivo não couber em 10.000 partes
+	partSize := max(mp.config.UploadPartSize, minUploadPartSize, (req.Size+maxUploadParts-1)/maxUploadParts)
+	partCount := (req.Size + partSize - 1) / partSize
+
+	input := &s3.CreateMultipartUploadInput{Bucket: aws.String(upload.Bucket), Key: aws.String(upload.Key)}
+	if req.ContentType != "" {
+		input.ContentType = aws.String(req.ContentType)
+	}
+	created, err := mp.s3Client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		return DirectUpload{}, fmt.Errorf("erro ao iniciar multipart upload: %w", err)
+	}
+
+	upload.Method = "MULTIPART"
+	upload.UploadID = aws.ToString(created.UploadId)
+	upload.PartSize = partSize
+	for part := int32(1); part <= int32(partCount); part++ {
+		signed, err := mp.presigner.PresignUploadPart(ctx, &s3.UploadPartInput{
+			Bucket:     aws.String(upload.Bucket),
+			Key:        aws.String(upload.Key),
+			UploadId:   created.UploadId,
+			PartNumber: aws.Int32(part),
+		}, s3.WithPresignExpires(ttl))
+		if err != nil {
+			mp.abortMultipartUpload(ctx, upload)
+			return DirectUpload{}, fmt.Errorf("erro ao gerar URL da parte %d: %w", part, err)
+		}
+		upload.Parts = append(upload.Parts, UploadPartURL{PartNumber: part, URL: signed.URL})
+	}
+	logf(ctx, "🔗 Multipart upload emitido: s3://%s/%s (%d partes, ProcessID: %s)", upload.Bucket, upload.Key, partCount, upload.ProcessID)
+	return upload, nil
+}
+
+func (mp *MessageProcessor) abortMultipartUpload(ctx context.Context, upload DirectUpload) {
+	_, err := mp.s3Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(upload.Bucket),
+		Key:      aws.String(upload.Key),
+		UploadId: aws.String(upload.UploadID),
+	})
+	if err != nil {
+		logf(ctx, "⚠️ Erro ao abortar multipart upload %s: %v", upload.UploadID, err)
+	}
+}
+
+// CompleteDirectUpload finaliza o multipart (se houver), confirma que o objeto
+// existe e, no modo confirm, enfileira o processamento. Retorna se o job foi
+// enfileirado (no modo event a notificação do S3 é que enfileira). Repetir a
+// confirmação retorna false; confirmações simultâneas podem enfileirar em dobro
+func (mp *MessageProcessor) CompleteDirectUpload(ctx context.Context, processID string, req CompleteUploadRequest) (bool, error) {
+	if mp.config.uploadProcessID(req.Key) != processID {
+		return false, fmt.Errorf("%w: chave %q não pertence ao processo %s", ErrInvalidUpload, req.Key, processID)
+	}
+	bucket := mp.config.SourceBucket
+	store := mp.BlobStore()
+
+	tags, err := store.Tags(ctx, bucket, req.Key)
+	if err != nil && !errors.Is(err, ErrBlobNotFound) {
+		return false, err
+	}
+	if tags[uploadConfirmedTag] == processID {
+		logf(ctx, "📥 Upload %s já confirmado anteriormente, nada a enfileirar", processID)
+		return false, nil
+	}
+
+	if req.UploadID != "" {
+		if len(req.Parts) == 0 {
+			return false, fmt.Errorf("%w: partes obrigatórias para concluir o multipart", ErrInvalidUpload)
+		}
+		parts := make([]types.CompletedPart, len(req.Parts))
+		for i, part := range req.Parts {
+			parts[i] = types.CompletedPart{PartNumber: aws.Int32(part.PartNumber), ETag: aws.String(part.ETag)}
+		}
+		_, err := mp.s3Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(bucket),
+			Key:             aws.String(req.Key),
+			UploadId:        aws.String(req.UploadID),
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		})
+		if err != nil {
+			return false, fmt.Errorf("erro ao concluir multipart upload: %w", err)
+		}
+	}
+
+	if _, err := store.Head(ctx, bucket, req.Key); err != nil {
+		if errors.Is(err, ErrBlobNotFound) {
+			return false, fmt.Errorf("%w: objeto s3://%s/%s não encontrado", ErrInvalidUpload, bucket, req.Key)
+		}
+		return false, err
+	}
+
+	queued := mp.config.UploadConfirmMode != UploadConfirmEvent
+	if queued {
+		if err := mp.EnqueueVideo(ctx, VideoProcessingMessage{FileID: req.Key, ProcessID: processID}); err != nil {
+			return false, err
+		}
+	} else {
+		logf(ctx, "📥 Upload %s confirmado, aguardando evento do S3", processID)
+	}
+	// Marca gravada só após o envio confirmado, para um retry não perder o job
+	if err := store.Tag(ctx, bucket, req.Key, map[string]string{uploadConfirmedTag: processID}); err != nil {
+		logf(ctx, "⚠️ Erro ao marcar upload %s como confirmado: %v", processID, err)
+	}
+	return queued, nil
+}
+
+// EnqueueVideo publica a mensagem de processamento na fila de entrada (a
+// primeira, quando houver várias)
+func (mp *MessageProcessor) EnqueueVideo(ctx context.Context, videoMsg VideoProcessingMessage) error {
+	queues := mp.getQueues()
+	if len(queues) == 0 {
+		return fmt.Errorf("fila de entrada não configurada")
+	}
+	body, err := json.Marshal(videoMsg)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar mensagem: %w", err)
+	}
+
+	msg := OutgoingMessage{
+		Body:            string(body),
+		GroupID:         videoMsg.ProcessID,
+		DeduplicationID: videoMsg.ProcessID,
+	}
+	if tc, ok := traceFrom(ctx); ok {
+		msg.Attributes = tc.messageAttributes()
+	}
+	// Aguardar o envio mesmo com lotes, para que falhas cheguem ao chamador
+	publish := queues[0].backend.Publish
+	if confirming, ok := queues[0].backend.(confirmingQueue); ok {
+		publish = confirming.PublishConfirmed
+	}
+	if err := publish(ctx, msg); err != nil {
+		return fmt.Errorf("erro ao enfileirar processamento: %w", err)
+	}
+	logf(ctx, "📨 Processamento enfileirado: %s (ProcessID: %s)", videoMsg.FileID, videoMsg.ProcessID)
+	return nil
+}
+
+// Eventos do S3 para chaves de upload direto usam o ProcessID do upload. No
+// modo confirm são ignorados: o cliente é quem enfileira, evitando duplicar o job
+func (mp *MessageProcessor) directUploadEvent(videoMsg VideoProcessingMessage) (VideoProcessingMessage, bool) {
+	if !videoMsg.fromEvent {
+		return videoMsg, true
+	}
+	bucket := videoMsg.SourceBucket
+	if bucket != "" && bucket != mp.config.SourceBucket {
+		return videoMsg, true
+	}
+	processID := mp.config.uploadProcessID(videoMsg.FileID)
+	if processID == "" {
+		return videoMsg, true
+	}
+	if mp.config.UploadConfirmMode != UploadConfirmEvent {
+		return videoMsg, false
+	}
+	videoMsg.ProcessID = processID
+	return videoMsg, true
+}
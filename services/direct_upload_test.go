@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Mock S3Client que captura a conclusão do multipart upload
+type mockS3ClientMultipart struct {
+	mockS3Client
+	completed *s3.CompleteMultipartUploadInput
+	aborted   bool
+}
+
+func (m *mockS3ClientMultipart) CompleteMultipartUpload(ctx context.Context, input *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	m.completed = input
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (m *mockS3ClientMultipart) AbortMultipartUpload(ctx context.Context, input *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	m.aborted = true
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+// Processador com presign no LocalStack, fila de entrada em memória e
+// armazenamento em diretório simulando o bucket de origem
+func newDirectUploadProcessor(t *testing.T, queueName string) (*MessageProcessor, *mockS3ClientMultipart, *MemoryQueue) {
+	mockS3 := &mockS3ClientMultipart{}
+	mp := &MessageProcessor{
+		config: MessageProcessorConfig{
+			QueueBackend:             QueueBackendMemory,
+			SQSQueueURL:              queueName,
+			SourceBucket:             "video-bucket",
+			UploadPrefix:             "uploads/",
+			UploadURLTTL:             10 * time.Minute,
+			UploadMultipartThreshold: 100 << 20,
+			UploadPartSize:           64 << 20,
+		},
+		s3Client:  mockS3,
+		presigner: newS3Presigner(newLocalStackS3Client(), ""),
+		blobs:     NewDirBlobStore(t.TempDir()),
+	}
+	return mp, mockS3, MemoryQueueNamed(queueName, 0)
+}
+
+func TestCreateDirectUpload_PUT(t *testing.T) {
+	mp, _, _ := newDirectUploadProcessor(t, "teste-upload-put")
+	upload, err := mp.CreateDirectUpload(context.TODO(), DirectUploadRequest{Filename: `C:\videos\aula.mp4`, ContentType: "video/mp4", Size: 1 << 20})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if upload.Method != "PUT" || upload.Key != "uploads/"+upload.ProcessID+"/aula.mp4" || upload.Bucket != "video-bucket" {
+		t.Errorf("Upload inesperado: %+v", upload)
+	}
+	parsed, _ := url.Parse(upload.URL)
+	if parsed.Host != "localhost:4566" || parsed.Query().Get("X-Amz-Expires") != "600" {
+		t.Errorf("Esperado URL PUT no LocalStack com validade de 600s, obtido %s", upload.URL)
+	}
+	if upload.Headers["Content-Type"] != "video/mp4" {
+		t.Errorf("Esperado Content-Type nos cabeçalhos do PUT, obtido %v", upload.Headers)
+	}
+}
+
+func TestCreateDirectUpload_Multipart(t *testing.T) {
+	mp, _, _ := newDirectUploadProcessor(t, "teste-upload-multipart")
+	upload, err := mp.CreateDirectUpload(context.TODO(), DirectUploadRequest{Filename: "grande.mkv", Size: 150 << 20})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if upload.Method != "MULTIPART" || upload.UploadID != "upload-1" || upload.PartSize != 64<<20 || len(upload.Parts) != 3 {
+		t.Fatalf("Esperado multipart com 3 partes de 64 MiB, obtido %+v", upload)
+	}
+	parsed, _ := url.Parse(upload.Parts[2].URL)
+	if parsed.Query().Get("partNumber") != "3" || parsed.Query().Get("uploadId") != "upload-1" {
+		t.Errorf("URL da parte inesperada: %s", upload.Parts[2].URL)
+	}
+}
+
+func TestCreateDirectUpload_PartesAjustadasAoLimite(t *testing.T) {
+	mp, _, _ := newDirectUploadProcessor(t, "teste-upload-limite")
+	mp.config.UploadPartSize = minUploadPartSize
+
+	// 100 GiB em partes de 5 MiB passaria de 10.000 partes
+	size := int64(100 << 30)
+	upload, err := mp.CreateDirectUpload(context.TODO(), DirectUploadRequest{Filename: "longo.mp4", Size: size})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(upload.Parts) > maxUploadParts || upload.PartSize*int64(len(upload.Parts)) < size {
+		t.Errorf("Esperado até 10.000 partes cobrindo o arquivo, obtido %d de %d bytes", len(upload.Parts), upload.PartSize)
+	}
+
+	if _, err := mp.CreateDirectUpload(context.TODO(), DirectUploadRequest{Filename: "a.mp4", Multipart: true}); !errors.Is(err, ErrInvalidUpload) {
+		t.Errorf("Esperado ErrInvalidUpload sem tamanho, obtido %v", err)
+	}
+}
+
+func TestCreateDirectUpload_BackendDir(t *testing.T) {
+	mp, _, _ := newDirectUploadProcessor(t, "teste-upload-dir")
+	mp.config.BlobBackend = BlobBackendDir
+	if _, err := mp.CreateDirectUpload(context.TODO(), DirectUploadRequest{Filename: "a.mp4"}); !errors.Is(err, ErrPresignUnavailable) {
+		t.Errorf("Esperado ErrPresignUnavailable, obtido %v", err)
+	}
+}
+
+func TestCompleteDirectUpload_EnfileiraProcessamento(t *testing.T) {
+	mp, mockS3, queue := newDirectUploadProcessor(t, "teste-upload-confirmacao")
+	key := "uploads/proc-1/aula.mp4"
+	mp.blobs.Put(context.TODO(), "video-bucket", key, strings.NewReader("video"), PutOptions{})
+
+	queued, err := mp.CompleteDirectUpload(context.TODO(), "proc-1", CompleteUploadRequest{
+		Key:      key,
+		UploadID: "upload-1",
+		Parts:    []CompletedUploadPart{{PartNumber: 1, ETag: `"a"`}, {PartNumber: 2, ETag: `"b"`}},
+	})
+	if err != nil || !queued {
+		t.Fatalf("Esperado processamento enfileirado, obtido %v (%v)", queued, err)
+	}
+	if parts := mockS3.completed.MultipartUpload.Parts; len(parts) != 2 || aws.ToString(parts[1].ETag) != `"b"` {
+		t.Errorf("Partes inesperadas na conclusão: %+v", parts)
+	}
+
+	messages, _ := queue.Receive(context.TODO(), 10, time.Millisecond)
+	if len(messages) != 1 {
+		t.Fatalf("Esperado 1 mensagem na fila de entrada, obtido %d", len(messages))
+	}
+	var videoMsg VideoProcessingMessage
+	json.Unmarshal([]byte(messages[0].Body), &videoMsg)
+	if videoMsg.FileID != key || videoMsg.ProcessID != "proc-1" {
+		t.Errorf("Mensagem inesperada: %+v", videoMsg)
+	}
+}
+
+func TestCompleteDirectUpload_RepetidaNaoEnfileiraDeNovo(t *testing.T) {
+	mp, mockS3, queue := newDirectUploadProcessor(t, "teste-upload-repetido")
+	key := "uploads/proc-1/aula.mp4"
+	mp.blobs.Put(context.TODO(), "video-bucket", key, strings.NewReader("video"), PutOptions{})
+	req := CompleteUploadRequest{Key: key, UploadID: "upload-1", Parts: []CompletedUploadPart{{PartNumber: 1, ETag: `"a"`}}}
+
+	if queued, err := mp.CompleteDirectUpload(context.TODO(), "proc-1", req); err != nil || !queued {
+		t.Fatalf("Esperado processamento enfileirado, obtido %v (%v)", queued, err)
+	}
+	mockS3.completed = nil
+	queued, err := mp.CompleteDirectUpload(context.TODO(), "proc-1", req)
+	if err != nil || queued {
+		t.Errorf("Esperado confirmação repetida sem enfileirar e sem erro, obtido %v (%v)", queued, err)
+	}
+	if mockS3.completed != nil {
+		t.Error("Multipart já concluído não deveria ser concluído de novo")
+	}
+	if ready, _ := queue.Len(); ready != 1 {
+		t.Errorf("Esperado 1 mensagem na fila de entrada, obtido %d", ready)
+	}
+}
+
+// Fila cujo Publish só enfileira no lote e cujo envio confirmado falha
+type failingBatchQueue struct{ *MemoryQueue }
+
+func (q failingBatchQueue) PublishConfirmed(ctx context.Context, msg OutgoingMessage) error {
+	return errors.New("lote recusado")
+}
+
+func TestCompleteDirectUpload_FalhaNoEnvioNaoMarcaConfirmado(t *testing.T) {
+	mp, _, queue := newDirectUploadProcessor(t, "teste-upload-falha-envio")
+	key := "uploads/proc-1/aula.mp4"
+	mp.blobs.Put(context.TODO(), "video-bucket", key, strings.NewReader("video"), PutOptions{})
+	mp.queues = []*inputQueue{{url: "teste-upload-falha-envio", name: "teste-upload-falha-envio", weight: 1, backend: failingBatchQueue{queue}}}
+
+	if _, err := mp.CompleteDirectUpload(context.TODO(), "proc-1", CompleteUploadRequest{Key: key}); err == nil {
+		t.Fatal("Esperado erro com o envio recusado")
+	}
+	if tags, _ := mp.blobs.Tags(context.TODO(), "video-bucket", key); tags[uploadConfirmedTag] != "" {
+		t.Errorf("Upload não enfileirado não deveria ser marcado, obtido %v", tags)
+	}
+
+	mp.queues = nil
+	if queued, err := mp.CompleteDirectUpload(context.TODO(), "proc-1", CompleteUploadRequest{Key: key}); err != nil || !queued {
+		t.Errorf("Esperado retry enfileirado, obtido %v (%v)", queued, err)
+	}
+}
+
+func TestCreateDirectUpload_LimitesDoS3(t *testing.T) {
+	mp, _, _ := newDirectUploadProcessor(t, "teste-upload-limites-s3")
+	if _, err := mp.CreateDirectUpload(context.TODO(), DirectUploadRequest{Filename: "a.mp4", Size: maxUploadSize + 1}); !errors.Is(err, ErrInvalidUpload) {
+		t.Errorf("Esperado ErrInvalidUpload acima de 5 TiB, obtido %v", err)
+	}
+
+	// Sem limite de multipart configurado, acima de 5 GiB o PUT simples seria recusado pelo S3
+	mp.config.UploadMultipartThreshold = 0
+	upload, err := mp.CreateDirectUpload(context.TODO(), DirectUploadRequest{Filename: "a.mp4", Size: 6 << 30})
+	if err != nil || upload.Method != "MULTIPART" {
+		t.Errorf("Esperado multipart acima de 5 GiB, obtido %s (%v)", upload.Method, err)
+	}
+
+	if err := (MessageProcessorConfig{UploadPartSize: 6 << 30}).validateDirectUploads(); err == nil {
+		t.Error("Esperado erro para UPLOAD_PART_SIZE acima de 5 GiB")
+	}
+}
+
+func TestCompleteDirectUpload_ChaveDeOutroProcesso(t *testing.T) {
+	mp, _, _ := newDirectUploadProcessor(t, "teste-upload-outro")
+	for _, key := range []string{"uploads/proc-2/a.mp4", "videos/a.mp4", "uploads/proc-1/sub/a.mp4"} {
+		if _, err := mp.CompleteDirectUpload(context.TODO(), "proc-1", CompleteUploadRequest{Key: key}); !errors.Is(err, ErrInvalidUpload) {
+			t.Errorf("Esperado ErrInvalidUpload para %s, obtido %v", key, err)
+		}
+	}
+}
+
+func TestCompleteDirectUpload_ObjetoInexistente(t *testing.T) {
+	mp, _, queue := newDirectUploadProcessor(t, "teste-upload-inexistente")
+	if _, err := mp.CompleteDirectUpload(context.TODO(), "proc-1", CompleteUploadRequest{Key: "uploads/proc-1/a.mp4"}); !errors.Is(err, ErrInvalidUpload) {
+		t.Errorf("Esperado ErrInvalidUpload, obtido %v", err)
+	}
+	if ready, _ := queue.Len(); ready != 0 {
+		t.Errorf("Esperado nenhuma mensagem enfileirada, obtido %d", ready)
+	}
+}
+
+func TestCompleteDirectUpload_ModoEvento(t *testing.T) {
+	mp, _, queue := newDirectUploadProcessor(t, "teste-upload-modo-evento")
+	mp.config.UploadConfirmMode = UploadConfirmEvent
+	mp.blobs.Put(context.TODO(), "video-bucket", "uploads/proc-1/a.mp4", strings.NewReader("video"), PutOptions{})
+
+	queued, err := mp.CompleteDirectUpload(context.TODO(), "proc-1", CompleteUploadRequest{Key: "uploads/proc-1/a.mp4"})
+	if err != nil || queued {
+		t.Errorf("Esperado confirmação sem enfileirar, obtido %v (%v)", queued, err)
+	}
+	if ready, _ := queue.Len(); ready != 0 {
+		t.Errorf("Esperado nenhuma mensagem enfileirada, obtido %d", ready)
+	}
+}
+
+func TestDirectUploadEvent(t *testing.T) {
+	mp := &MessageProcessor{config: MessageProcessorConfig{SourceBucket: "video-bucket", UploadPrefix: "uploads/"}}
+	event := VideoProcessingMessage{FileID: "uploads/proc-1/a.mp4", SourceBucket: "video-bucket", ProcessID: "s3-abc", fromEvent: true}
+
+	// Modo confirm: o evento é ignorado, o cliente enfileira
+	if _, ok := mp.directUploadEvent(event); ok {
+		t.Error("Esperado evento ignorado no modo confirm")
+	}
+
+	// Modo event: o evento usa o ProcessID do upload
+	mp.config.UploadConfirmMode = UploadConfirmEvent
+	if msg, ok := mp.directUploadEvent(event); !ok || msg.ProcessID != "proc-1" {
+		t.Errorf("Esperado ProcessID do upload, obtido %+v (%v)", msg, ok)
+	}
+
+	// Outros objetos e mensagens diretas não são afetados
+	other := VideoProcessingMessage{FileID: "videos/a.mp4", ProcessID: "s3-abc", fromEvent: true}
+	direct := VideoProcessingMessage{FileID: "uploads/proc-1/a.mp4", ProcessID: "proc-9"}
+	for _, msg := range []VideoProcessingMessage{other, direct} {
+		if got, ok := mp.directUploadEvent(msg); !ok || got.ProcessID != msg.ProcessID {
+			t.Errorf("Esperado mensagem inalterada, obtido %+v (%v)", got, ok)
+		}
+	}
+}
+
+func TestParseVideoMessages_EventoMarcado(t *testing.T) {
+	body := `{"Records":[{"eventSource":"aws:s3","eventName":"ObjectCreated:Put","s3":{"bucket":{"name":"video-bucket"},"object":{"key":"uploads/proc-1/a.mp4"}}}]}`
+	msgs, err := parseVideoMessages(body)
+	if err != nil || len(msgs) != 1 || !msgs[0].fromEvent {
+		t.Errorf("Esperado mensagem marcada como evento, obtido %+v (%v)", msgs, err)
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// ErrInsufficientDisk indica que o job não cabe no espaço livre dos diretórios
+// de trabalho. É transitório: o job é adiado até outros jobs liberarem espaço
+var ErrInsufficientDisk = errors.New("espaço em disco insuficiente")
+
+// Espaço necessário para um job e o disponível no diretório mais cheio
+type DiskSpaceError struct {
+	Path      string
+	Required  uint64
+	Available uint64
+	Total     uint64
+}
+
+func (e *DiskSpaceError) Error() string {
+	return fmt.Sprintf("%v em %s: necessários %s, disponíveis %s", ErrInsufficientDisk, e.Path, formatBytes(e.Required), formatBytes(e.Available))
+}
+
+func (e *DiskSpaceError) Unwrap() error {
+	return ErrInsufficientDisk
+}
+
+// Permanent indica que o job não caberia nem com o disco vazio
+func (e *DiskSpaceError) Permanent() bool {
+	return e.Total > 0 && e.Required > e.Total
+}
+
+// Espaço livre e total do sistema de arquivos do caminho (ver disk_space_unix.go)
+var diskUsage = statDiskUsage
+
+// Conferir se um vídeo de size bytes cabe nos diretórios de trabalho: o vídeo,
+// os frames e o ZIP ocupam até size × factor, além da reserva mínima. Nos
+// diretórios de estado (outbox, webhooks que falharam) só os registros do job
+// são gravados, então basta a reserva
+func checkDiskSpace(size int64, factor float64, reserve int64, stateDirs ...string) error {
+	if factor <= 0 {
+		return nil
+	}
+	reserve = max(reserve, 0)
+	required := uint64(float64(size)*factor) + uint64(reserve)
+	for _, root := range Scratch().roots() {
+		if err := checkFree(root, required); err != nil {
+			return err
+		}
+	}
+	for _, dir := range stateDirs {
+		if err := checkFree(existingParent(dir), uint64(reserve)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkFree(path string, required uint64) error {
+	free, total, err := diskUsage(path)
+	if err != nil {
+		// Sem como medir (ex: plataforma sem statfs), o job segue
+		log.Printf("⚠️ Não foi possível verificar o espaço livre em %s: %v", path, err)
+		return nil
+	}
+	if free < required {
+		return &DiskSpaceError{Path: path, Required: required, Available: free, Total: total}
+	}
+	return nil
+}
+
+// Diretório mais próximo que já existe (os de estado só são criados na primeira gravação)
+func existingParent(dir string) string {
+	for {
+		if _, err := os.Stat(dir); err == nil || filepath.Dir(dir) == dir {
+			return dir
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// Tamanho legível para logs e erros
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
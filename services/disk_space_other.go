@@ -0,0 +1,9 @@
+//go:build !unix
+
+package services
+
+import "errors"
+
+func statDiskUsage(path string) (free, total uint64, err error) {
+	return 0, 0, errors.ErrUnsupported
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Substituir a medição do disco durante o teste
+func stubDiskUsage(t *testing.T, free, total uint64) {
+	original := diskUsage
+	diskUsage = func(path string) (uint64, uint64, error) { return free, total, nil }
+	t.Cleanup(func() { diskUsage = original })
+}
+
+func TestCheckDiskSpace_Suficiente(t *testing.T) {
+	stubDiskUsage(t, 1000, 10000)
+	if err := checkDiskSpace(100, 3, 500); err != nil {
+		t.Errorf("Erro inesperado: %v", err)
+	}
+}
+
+func TestCheckDiskSpace_Insuficiente(t *testing.T) {
+	stubDiskUsage(t, 1000, 10000)
+	err := checkDiskSpace(300, 3, 200)
+
+	var diskErr *DiskSpaceError
+	if !errors.As(err, &diskErr) || !errors.Is(err, ErrInsufficientDisk) {
+		t.Fatalf("Esperado DiskSpaceError, obtido %v", err)
+	}
+	if diskErr.Required != 1100 || diskErr.Available != 1000 || diskErr.Permanent() {
+		t.Errorf("Esperado 1100 necessários, 1000 disponíveis e erro transitório, obtido %+v", diskErr)
+	}
+}
+
+func TestCheckDiskSpace_NaoCabeNoDisco(t *testing.T) {
+	stubDiskUsage(t, 1000, 10000)
+	var diskErr *DiskSpaceError
+	if err := checkDiskSpace(5000, 3, 0); !errors.As(err, &diskErr) || !diskErr.Permanent() {
+		t.Errorf("Esperado erro permanente, obtido %v", err)
+	}
+}
+
+func TestCheckDiskSpace_Desabilitado(t *testing.T) {
+	stubDiskUsage(t, 0, 0)
+	if err := checkDiskSpace(1<<40, 0, 0); err != nil {
+		t.Errorf("Esperado verificação desabilitada com fator 0, obtido %v", err)
+	}
+}
+
+func TestCheckDiskSpace_MedicaoIndisponivel(t *testing.T) {
+	original := diskUsage
+	diskUsage = func(path string) (uint64, uint64, error) { return 0, 0, errors.ErrUnsupported }
+	defer func() { diskUsage = original }()
+	if err := checkDiskSpace(100, 3, 0); err != nil {
+		t.Errorf("Esperado job liberado sem medição, obtido %v", err)
+	}
+}
+
+func TestStatDiskUsage(t *testing.T) {
+	free, total, err := statDiskUsage(os.TempDir())
+	if errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("statfs indisponível nesta plataforma")
+	}
+	if err != nil || total == 0 || free > total {
+		t.Errorf("Esperado espaço livre <= total, obtido %d/%d (%v)", free, total, err)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	cases := map[uint64]string{512: "512 B", 1536: "1.5 KiB", 3 << 30: "3.0 GiB"}
+	for n, expected := range cases {
+		if got := formatBytes(n); got != expected {
+			t.Errorf("Esperado '%s', obtido '%s'", expected, got)
+		}
+	}
+}
+
+func TestDownloadFromS3_SemEspacoEmDisco(t *testing.T) {
+	root := t.TempDir()
+	mp := &MessageProcessor{config: MessageProcessorConfig{BlobBackend: BlobBackendDir, BlobDir: root, DiskExpansionFactor: 3}}
+	NewDirBlobStore(root).Put(context.TODO(), "videos", "a.mp4", strings.NewReader("video"), PutOptions{})
+	stubDiskUsage(t, 10, 1000)
+
+	localPath, err := mp.DownloadFromS3(context.TODO(), "videos", "a.mp4")
+	if !errors.Is(err, ErrInsufficientDisk) {
+		os.Remove(localPath)
+		t.Errorf("Esperado ErrInsufficientDisk, obtido %v", err)
+	}
+}
+
+func TestProcessVideoMessage_SemEspacoNaoNotificaInicio(t *testing.T) {
+	useScratchDirs(t)
+	root := t.TempDir()
+	mp := &MessageProcessor{config: MessageProcessorConfig{BlobBackend: BlobBackendDir, BlobDir: root, SourceBucket: "videos", ResultsBucket: "videos", DiskExpansionFactor: 3}}
+	sink := &recordingSink{name: "queue"}
+	mp.publisher = newCompositeResultPublisher(sink)
+	NewDirBlobStore(root).Put(context.TODO(), "videos", "a.mp4", strings.NewReader("video"), PutOptions{})
+	stubDiskUsage(t, 10, 1000)
+
+	if mp.processVideoMessage(context.TODO(), VideoProcessingMessage{FileID: "a.mp4", ProcessID: "proc-1"}) {
+		t.Error("Esperado job adiado, com a mensagem mantida na fila")
+	}
+	if len(sink.events) != 0 {
+		t.Errorf("Esperado nenhum evento para job adiado, obtido %d (%s)", len(sink.events), sink.events[0].Result.Status)
+	}
+}
+
+func TestCheckDiskSpace_DiretorioDeEstadoSoExigeReserva(t *testing.T) {
+	useScratchDirs(t)
+	state := filepath.Join(t.TempDir(), "outbox")
+	original := diskUsage
+	diskUsage = func(path string) (uint64, uint64, error) {
+		if strings.HasPrefix(state, path) {
+			return 100, 10000, nil
+		}
+		return 5000, 10000, nil
+	}
+	t.Cleanup(func() { diskUsage = original })
+
+	if err := checkDiskSpace(1000, 3, 50, state); err != nil {
+		t.Errorf("Esperado job liberado com a reserva livre no estado, obtido %v", err)
+	}
+	var diskErr *DiskSpaceError
+	if err := checkDiskSpace(1000, 3, 200, state); !errors.As(err, &diskErr) || diskErr.Required != 200 || !strings.HasPrefix(state, diskErr.Path) {
+		t.Errorf("Esperado falta de espaço no diretório de estado, obtido %v", err)
+	}
+}
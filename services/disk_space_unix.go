@@ -0,0 +1,14 @@
+//go:build unix
+
+package services
+
+import "syscall"
+
+func statDiskUsage(path string) (free, total uint64, err error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	// Bavail: blocos disponíveis para usuários sem privilégio
+	return stat.Bavail * uint64(stat.Bsize), stat.Blocks * uint64(stat.Bsize), nil
+}
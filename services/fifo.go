@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// MessageGroupId padrão: um grupo por job, mantendo a ordem dos eventos de um
+// mesmo vídeo sem serializar os resultados de vídeos diferentes
+const defaultResultsGroupID = "{processId}"
+
+// Tamanho máximo do MessageGroupId no SQS
+const maxGroupIDLength = 128
+
+type resultSequenceKey struct{}
+
+// Inicia a contagem de eventos de resultado de um job
+func withResultSequence(ctx context.Context) context.Context {
+	return context.WithValue(ctx, resultSequenceKey{}, new(atomic.Int64))
+}
+
+// Próximo número de sequência do job (0 fora de um job)
+func nextResultSequence(ctx context.Context) int64 {
+	if seq, ok := ctx.Value(resultSequenceKey{}).(*atomic.Int64); ok {
+		return seq.Add(1)
+	}
+	return 0
+}
+
+// Fila FIFO identificada pelo sufixo .fifo da URL
+func isFIFOQueue(queueURL string) bool {
+	return strings.HasSuffix(queueURL, ".fifo")
+}
+
+// MessageGroupId a partir do template configurado. Placeholders aceitos:
+// {processId} e {correlationId}; qualquer outro texto é usado literalmente.
+// Acima de 128 caracteres, o fim é trocado pelo SHA-256 do grupo completo
+func resultsGroupID(ctx context.Context, template, processID string) string {
+	if template == "" {
+		template = defaultResultsGroupID
+	}
+	correlationID := ""
+	if tc, ok := traceFrom(ctx); ok {
+		correlationID = tc.CorrelationID
+	}
+	groupID := strings.NewReplacer(
+		"{processId}", processID,
+		"{correlationId}", correlationID,
+	).Replace(template)
+	if groupID == "" {
+		return "default"
+	}
+	if len(groupID) > maxGroupIDLength {
+		sum := sha256.Sum256([]byte(groupID))
+		hash := hex.EncodeToString(sum[:])
+		groupID = groupID[:maxGroupIDLength-len(hash)-1] + "-" + hash
+	}
+	return groupID
+}
+
+// MessageDeduplicationId determinístico: o reenvio do mesmo evento de um job
+// (ex: nova tentativa após falha) é descartado pelo SQS na janela de deduplicação
+func resultDeduplicationID(processID, status string, sequence int64) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d", processID, status, sequence)))
+	return hex.EncodeToString(sum[:])
+}
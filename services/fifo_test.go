@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestResultsGroupID_PadraoPorProcessID(t *testing.T) {
+	if got := resultsGroupID(context.TODO(), "", "proc-1"); got != "proc-1" {
+		t.Errorf("Esperado 'proc-1', obtido '%s'", got)
+	}
+}
+
+func TestResultsGroupID_Template(t *testing.T) {
+	tc := TraceContext{CorrelationID: "corr-1"}
+	ctx := withTrace(context.TODO(), tc)
+	if got := resultsGroupID(ctx, "tenant-{correlationId}", "proc-1"); got != "tenant-corr-1" {
+		t.Errorf("Esperado 'tenant-corr-1', obtido '%s'", got)
+	}
+	if got := resultsGroupID(ctx, "soat-fiap-x-group", "proc-1"); got != "soat-fiap-x-group" {
+		t.Errorf("Esperado grupo fixo, obtido '%s'", got)
+	}
+}
+
+func TestResultsGroupID_LimitadoA128Caracteres(t *testing.T) {
+	long := strings.Repeat("a", 200)
+	got := resultsGroupID(context.TODO(), "", long)
+	if len(got) != maxGroupIDLength || !strings.HasPrefix(got, strings.Repeat("a", 63)+"-") {
+		t.Errorf("Esperado prefixo + hash com %d caracteres, obtido '%s' (%d)", maxGroupIDLength, got, len(got))
+	}
+	if other := resultsGroupID(context.TODO(), "", long+"b"); other == got {
+		t.Error("Esperado grupos distintos para processIds longos distintos")
+	}
+	if again := resultsGroupID(context.TODO(), "", long); again != got {
+		t.Error("Esperado mesmo grupo para o mesmo processId")
+	}
+}
+
+func TestResultDeduplicationID_Deterministico(t *testing.T) {
+	first := resultDeduplicationID("proc-1", "COMPLETED", 2)
+	if first != resultDeduplicationID("proc-1", "COMPLETED", 2) {
+		t.Error("Esperado mesmo ID para o mesmo evento")
+	}
+	if first == resultDeduplicationID("proc-1", "FAILED", 2) {
+		t.Error("Esperado IDs diferentes para status diferentes")
+	}
+	if first == resultDeduplicationID("proc-1", "COMPLETED", 3) {
+		t.Error("Esperado IDs diferentes para sequências diferentes")
+	}
+	if len(first) > 128 {
+		t.Errorf("MessageDeduplicationId excede 128 caracteres: %d", len(first))
+	}
+}
+
+func TestSendProcessingResult_FilaFIFO(t *testing.T) {
+	mockSQS := &mockSQSClientCaptura{}
+	mp := &MessageProcessor{config: MessageProcessorConfig{ResultsQueueURL: "https://sqs/123/results.fifo"}, sqsClient: mockSQS}
+	ctx := withResultSequence(context.TODO())
+
+	mp.SendProcessingResult(ctx, "proc-1", "", "IN_PROGRESS")
+	first := aws.ToString(mockSQS.sent.MessageDeduplicationId)
+	if aws.ToString(mockSQS.sent.MessageGroupId) != "proc-1" {
+		t.Errorf("Esperado MessageGroupId 'proc-1', obtido '%s'", aws.ToString(mockSQS.sent.MessageGroupId))
+	}
+
+	mp.SendProcessingResult(ctx, "proc-1", "zip", "COMPLETED")
+	if aws.ToString(mockSQS.sent.MessageDeduplicationId) != resultDeduplicationID("proc-1", "COMPLETED", 2) {
+		t.Error("Esperado MessageDeduplicationId derivado da sequência do job")
+	}
+	if first == aws.ToString(mockSQS.sent.MessageDeduplicationId) {
+		t.Error("Esperado MessageDeduplicationId diferente por evento")
+	}
+}
+
+func TestSendProcessingResult_FilaPadraoSemGrupo(t *testing.T) {
+	mockSQS := &mockSQSClientCaptura{}
+	mp := &MessageProcessor{config: MessageProcessorConfig{ResultsQueueURL: "https://sqs/123/results"}, sqsClient: mockSQS}
+	mp.SendProcessingResult(context.TODO(), "proc-1", "", "IN_PROGRESS")
+	if mockSQS.sent.MessageGroupId != nil || mockSQS.sent.MessageDeduplicationId != nil {
+		t.Error("Esperado envio sem MessageGroupId/MessageDeduplicationId em fila padrão")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package services
+
+import (
+	"errors"
+	"os"
+)
+
+// Sem flock: os workspaces não têm lock e a limpeza de órfãos considera apenas
+// a idade (JANITOR_MIN_AGE), que precisa ser maior que a duração de um job
+func lockFile(f *os.File) error {
+	return errors.ErrUnsupported
+}
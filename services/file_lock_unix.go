@@ -0,0 +1,19 @@
+//go:build unix
+
+package services
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// Lock exclusivo e não bloqueante (flock), liberado ao fechar o arquivo ou
+// quando o processo termina, inclusive por SIGKILL
+func lockFile(f *os.File) error {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errWorkspaceLocked
+	}
+	return err
+}
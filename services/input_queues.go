@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Fila de entrada com peso de prioridade
+type InputQueue struct {
+	URL    string
+	Weight int
+}
+
+// Estatísticas de vazão de uma fila de entrada
+type QueueStats struct {
+	Queue            string  `json:"queue"`
+	URL              string  `json:"url"`
+	Weight           int     `json:"weight"`
+	Received         int64   `json:"received"`
+	Completed        int64   `json:"completed"`
+	Failed           int64   `json:"failed"`
+	CompletedPerMin  float64 `json:"completed_per_minute"`
+	LastReceivedUnix int64   `json:"last_received_unix,omitempty"`
+}
+
+// Estado de uma fila de entrada em tempo de execução
+type inputQueue struct {
+	url     string
+	name    string
+	weight  int
+	current int // Estado do round-robin ponderado
+
+	backend Queue
+
+	started      time.Time
+	received     atomic.Int64
+	completed    atomic.Int64
+	failed       atomic.Int64
+	lastReceived atomic.Int64
+}
+
+func newInputQueue(q InputQueue) *inputQueue {
+	weight := q.Weight
+	if weight <= 0 {
+		weight = 1
+	}
+	return &inputQueue{url: q.URL, name: queueName(q.URL), weight: weight, started: time.Now()}
+}
+
+// Nome da fila (último segmento da URL), usado em logs e métricas
+func queueName(url string) string {
+	return url[strings.LastIndex(url, "/")+1:]
+}
+
+func (q *inputQueue) recordReceived(n int) {
+	q.received.Add(int64(n))
+	q.lastReceived.Store(time.Now().Unix())
+	queueMessagesTotal.WithLabelValues(q.name, "received").Add(float64(n))
+}
+
+func (q *inputQueue) recordOutcome(completed bool) {
+	if completed {
+		q.completed.Add(1)
+		queueMessagesTotal.WithLabelValues(q.name, "completed").Inc()
+		return
+	}
+	q.failed.Add(1)
+	queueMessagesTotal.WithLabelValues(q.name, "failed").Inc()
+}
+
+func (q *inputQueue) stats() QueueStats {
+	completed := q.completed.Load()
+	perMin := 0.0
+	if elapsed := time.Since(q.started).Minutes(); elapsed > 0 {
+		perMin = float64(completed) / elapsed
+	}
+	return QueueStats{
+		Queue:            q.name,
+		URL:              q.url,
+		Weight:           q.weight,
+		Received:         q.received.Load(),
+		Completed:        completed,
+		Failed:           q.failed.Load(),
+		CompletedPerMin:  perMin,
+		LastReceivedUnix: q.lastReceived.Load(),
+	}
+}
+
+// Filas configuradas; sem SQS_QUEUE_URLS, apenas SQSQueueURL com peso 1
+func (c MessageProcessorConfig) inputQueues() []InputQueue {
+	if len(c.InputQueues) > 0 {
+		return c.InputQueues
+	}
+	return []InputQueue{{URL: c.SQSQueueURL, Weight: 1}}
+}
+
+// Filas do processador, criadas sob demanda a partir da configuração
+func (mp *MessageProcessor) getQueues() []*inputQueue {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if mp.queues == nil {
+		for _, q := range mp.config.inputQueues() {
+			queue := newInputQueue(q)
+			queue.backend = mp.newQueue(queue.url)
+			mp.queues = append(mp.queues, queue)
+		}
+	}
+	return mp.queues
+}
+
+// Próxima fila a consultar por round-robin ponderado suave: filas de maior peso
+// são consultadas proporcionalmente mais vezes, mas todas são consultadas em
+// cada ciclo, então as de menor prioridade nunca ficam sem atendimento
+func (mp *MessageProcessor) nextQueue() *inputQueue {
+	queues := mp.getQueues()
+
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	total := 0
+	var best *inputQueue
+	for _, q := range queues {
+		q.current += q.weight
+		total += q.weight
+		if best == nil || q.current > best.current {
+			best = q
+		}
+	}
+	best.current -= total
+	return best
+}
+
+// Ordem de consulta das filas: a escolhida pelo round-robin ponderado e depois
+// as demais, das de maior peso para as de menor
+func (mp *MessageProcessor) queueOrder() []*inputQueue {
+	first := mp.nextQueue()
+	order := []*inputQueue{first}
+	rest := slices.Clone(mp.getQueues())
+	slices.SortStableFunc(rest, func(a, b *inputQueue) int { return b.weight - a.weight })
+	for _, queue := range rest {
+		if queue != first {
+			order = append(order, queue)
+		}
+	}
+	return order
+}
+
+// QueueStats retorna a vazão de cada fila de entrada
+func (mp *MessageProcessor) QueueStats() []QueueStats {
+	queues := mp.getQueues()
+	stats := make([]QueueStats, len(queues))
+	for i, q := range queues {
+		stats[i] = q.stats()
+	}
+	return stats
+}
+
+type inputQueueKey struct{}
+
+func withInputQueue(ctx context.Context, q *inputQueue) context.Context {
+	return context.WithValue(ctx, inputQueueKey{}, q)
+}
+
+// Fila de origem da mensagem em processamento (padrão: a primeira configurada)
+func (mp *MessageProcessor) queueFrom(ctx context.Context) *inputQueue {
+	if q, ok := ctx.Value(inputQueueKey{}).(*inputQueue); ok {
+		return q
+	}
+	return mp.getQueues()[0]
+}
+
+// Formato: "url1=peso1,url2=peso2" (peso opcional, padrão 1)
+func parseInputQueues(value string) []InputQueue {
+	var queues []InputQueue
+	for _, item := range splitList(value) {
+		q := InputQueue{URL: item, Weight: 1}
+		if i := strings.LastIndex(item, "="); i > 0 {
+			if weight, err := strconv.Atoi(item[i+1:]); err == nil {
+				q.URL = item[:i]
+				q.Weight = weight
+			}
+		}
+		queues = append(queues, q)
+	}
+	return queues
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseInputQueues(t *testing.T) {
+	queues := parseInputQueues("https://sqs/1/premium=5, https://sqs/1/bulk")
+	if len(queues) != 2 {
+		t.Fatalf("Esperado 2 filas, obtido %d", len(queues))
+	}
+	if queues[0].URL != "https://sqs/1/premium" || queues[0].Weight != 5 {
+		t.Errorf("Fila inesperada: %+v", queues[0])
+	}
+	if queues[1].URL != "https://sqs/1/bulk" || queues[1].Weight != 1 {
+		t.Errorf("Fila inesperada: %+v", queues[1])
+	}
+}
+
+func TestInputQueues_PadraoFilaUnica(t *testing.T) {
+	mp := &MessageProcessor{config: MessageProcessorConfig{SQSQueueURL: "https://sqs/1/video-processing-queue"}}
+	queues := mp.getQueues()
+	if len(queues) != 1 || queues[0].name != "video-processing-queue" {
+		t.Errorf("Esperado apenas a fila padrão, obtido %+v", queues)
+	}
+}
+
+func TestNextQueue_PonderadoSemStarvation(t *testing.T) {
+	mp := &MessageProcessor{config: MessageProcessorConfig{InputQueues: []InputQueue{
+		{URL: "https://sqs/1/premium", Weight: 3},
+		{URL: "https://sqs/1/bulk", Weight: 1},
+	}}}
+
+	counts := map[string]int{}
+	for i := 0; i < 8; i++ {
+		counts[mp.nextQueue().name]++
+	}
+	if counts["premium"] != 6 || counts["bulk"] != 2 {
+		t.Errorf("Esperado 6 consultas à premium e 2 à bulk, obtido %v", counts)
+	}
+}
+
+func TestQueueStats_Vazao(t *testing.T) {
+	mp := &MessageProcessor{config: MessageProcessorConfig{SQSQueueURL: "https://sqs/1/fila"}}
+	queue := mp.getQueues()[0]
+	queue.recordReceived(3)
+	queue.recordOutcome(true)
+	queue.recordOutcome(false)
+
+	stats := mp.QueueStats()
+	if len(stats) != 1 {
+		t.Fatalf("Esperado 1 fila, obtido %d", len(stats))
+	}
+	if stats[0].Received != 3 || stats[0].Completed != 1 || stats[0].Failed != 1 {
+		t.Errorf("Estatísticas inesperadas: %+v", stats[0])
+	}
+	if stats[0].LastReceivedUnix == 0 {
+		t.Error("Esperado horário do último recebimento")
+	}
+}
+
+func TestQueueFrom_ContextoDaMensagem(t *testing.T) {
+	mp := &MessageProcessor{config: MessageProcessorConfig{InputQueues: []InputQueue{{URL: "a"}, {URL: "b"}}}}
+	second := mp.getQueues()[1]
+	if mp.queueFrom(withInputQueue(context.TODO(), second)) != second {
+		t.Error("Esperado fila de origem do contexto")
+	}
+	if mp.queueFrom(context.TODO()) != mp.getQueues()[0] {
+		t.Error("Esperado fila padrão sem contexto")
+	}
+}
+
+// Processador com duas filas em memória (premium peso 3, bulk peso 1)
+func newWeightedTestProcessor(t *testing.T, wait int32) (*MessageProcessor, *MemoryQueue, *MemoryQueue) {
+	t.Helper()
+	premiumURL := "teste-ponderado-premium-" + t.Name()
+	bulkURL := "teste-ponderado-bulk-" + t.Name()
+	mp := &MessageProcessor{config: MessageProcessorConfig{
+		QueueBackend:    QueueBackendMemory,
+		InputQueues:     []InputQueue{{URL: premiumURL, Weight: 3}, {URL: bulkURL, Weight: 1}},
+		MaxMessages:     10,
+		WaitTimeSeconds: wait,
+	}}
+	return mp, MemoryQueueNamed(premiumURL, 0), MemoryQueueNamed(bulkURL, 0)
+}
+
+func TestProcessMessages_VariasFilasUmaMensagemPorVez(t *testing.T) {
+	mp, premium, bulk := newWeightedTestProcessor(t, 0)
+	for i := 0; i < 4; i++ {
+		premium.Publish(context.TODO(), OutgoingMessage{Body: "invalida"})
+		bulk.Publish(context.TODO(), OutgoingMessage{Body: "invalida"})
+	}
+
+	for i := 0; i < 4; i++ {
+		if received, err := mp.processMessages(context.TODO(), context.TODO()); received != 1 || err != nil {
+			t.Fatalf("Esperado 1 mensagem por recebimento, obtido %d (%v)", received, err)
+		}
+	}
+	stats := mp.QueueStats()
+	if stats[0].Received != 3 || stats[1].Received != 1 {
+		t.Errorf("Esperado 3 mensagens da premium e 1 da bulk, obtido %d e %d", stats[0].Received, stats[1].Received)
+	}
+}
+
+func TestProcessMessages_VariasFilasFilaVaziaCedeVez(t *testing.T) {
+	mp, _, bulk := newWeightedTestProcessor(t, 0)
+	bulk.Publish(context.TODO(), OutgoingMessage{Body: "invalida"})
+
+	if received, _ := mp.processMessages(context.TODO(), context.TODO()); received != 1 {
+		t.Errorf("Esperado mensagem da bulk com a premium vazia, obtido %d", received)
+	}
+}
+
+func TestProcessMessages_VariasFilasLongPollingParalelo(t *testing.T) {
+	mp, _, bulk := newWeightedTestProcessor(t, 5)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		bulk.Publish(context.TODO(), OutgoingMessage{Body: "invalida"})
+	}()
+
+	start := time.Now()
+	received, err := mp.processMessages(context.TODO(), context.TODO())
+	if received != 1 || err != nil {
+		t.Fatalf("Esperado 1 mensagem pelo long polling, obtido %d (%v)", received, err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Esperado retorno assim que a mensagem chega, levou %s", elapsed)
+	}
+}
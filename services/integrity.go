@@ -0,0 +1,95 @@
+package services
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// Nome do arquivo, dentro do ZIP, com o SHA-256 de cada frame (formato do sha256sum)
+const checksumsFilename = "SHA256SUMS"
+
+// Verificação de um download: o SHA-256 é sempre calculado durante a cópia e o
+// conteúdo é conferido com o checksum gravado no objeto ou, sem ele, com o ETag
+// quando este é o MD5 do conteúdo
+type downloadVerifier struct {
+	sha256   hash.Hash
+	check    hash.Hash
+	encode   func([]byte) string
+	expected string
+	source   string // Origem do valor esperado, para logs e erros
+}
+
+func newDownloadVerifier(info ObjectInfo) *downloadVerifier {
+	v := &downloadVerifier{sha256: sha256.New()}
+
+	// Checksums compostos (uploads multipart) não correspondem ao conteúdo inteiro
+	if info.Checksum != "" && !strings.Contains(info.Checksum, "-") {
+		if info.ChecksumAlgorithm == "SHA256" {
+			v.check = v.sha256
+		} else {
+			v.check = newChecksumHash(info.ChecksumAlgorithm)
+		}
+		if v.check != nil {
+			v.encode = base64.StdEncoding.EncodeToString
+			v.expected = info.Checksum
+			v.source = "checksum " + info.ChecksumAlgorithm
+			return v
+		}
+	}
+
+	// O ETag só é o MD5 em uploads simples sem SSE-KMS; multipart tem sufixo "-<partes>"
+	etag := strings.Trim(info.ETag, `"`)
+	if len(etag) == md5.Size*2 && info.Encryption != EncryptionKMS {
+		if _, err := hex.DecodeString(etag); err == nil {
+			v.check = md5.New()
+			v.encode = hex.EncodeToString
+			v.expected = strings.ToLower(etag)
+			v.source = "ETag (MD5)"
+		}
+	}
+	return v
+}
+
+// Destino da cópia que alimenta os hashes
+func (v *downloadVerifier) Writer() io.Writer {
+	if v.check == nil || v.check == v.sha256 {
+		return v.sha256
+	}
+	return io.MultiWriter(v.sha256, v.check)
+}
+
+// Conferir o conteúdo copiado; sem valor esperado não há o que conferir
+func (v *downloadVerifier) Verify() error {
+	if v.check == nil {
+		return nil
+	}
+	if actual := v.encode(v.check.Sum(nil)); actual != v.expected {
+		return fmt.Errorf("conteúdo corrompido: %s esperado %s, calculado %s", v.source, v.expected, actual)
+	}
+	return nil
+}
+
+// Verified indica se havia valor esperado para conferir
+func (v *downloadVerifier) Verified() bool {
+	return v.check != nil
+}
+
+// SHA-256 (hex) do conteúdo copiado
+func (v *downloadVerifier) SHA256() string {
+	return hex.EncodeToString(v.sha256.Sum(nil))
+}
+
+// Converter um SHA-256 em hex para o formato base64 do cabeçalho x-amz-checksum-sha256
+func sha256Checksum(hexDigest string) string {
+	digest, err := hex.DecodeString(hexDigest)
+	if err != nil || len(digest) != sha256.Size {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(digest)
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Copiar o conteúdo pelo verificador e conferir
+func verifyContent(info ObjectInfo, content string) (*downloadVerifier, error) {
+	v := newDownloadVerifier(info)
+	v.Writer().Write([]byte(content))
+	return v, v.Verify()
+}
+
+func TestDownloadVerifier_Checksum(t *testing.T) {
+	info := ObjectInfo{ChecksumAlgorithm: "SHA256", Checksum: "ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0="}
+	v, err := verifyContent(info, "abc")
+	if err != nil || !v.Verified() {
+		t.Errorf("Esperado conteúdo conferido, obtido %v", err)
+	}
+	if v.SHA256() != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("SHA-256 inesperado: %s", v.SHA256())
+	}
+	if _, err := verifyContent(info, "abd"); err == nil || !strings.Contains(err.Error(), "checksum SHA256") {
+		t.Errorf("Esperado erro de conteúdo corrompido, obtido %v", err)
+	}
+}
+
+func TestDownloadVerifier_ETagMD5(t *testing.T) {
+	info := ObjectInfo{ETag: `"900150983cd24fb0d6963f7d28e17f72"`}
+	if v, err := verifyContent(info, "abc"); err != nil || !v.Verified() {
+		t.Errorf("Esperado conteúdo conferido pelo ETag, obtido %v", err)
+	}
+	if _, err := verifyContent(info, "abd"); err == nil {
+		t.Error("Esperado erro para ETag divergente")
+	}
+}
+
+func TestDownloadVerifier_SemValorConfiavel(t *testing.T) {
+	for _, info := range []ObjectInfo{
+		{ETag: `"900150983cd24fb0d6963f7d28e17f72-3"`},
+		{ETag: `"900150983cd24fb0d6963f7d28e17f72"`, Encryption: EncryptionKMS},
+		{ChecksumAlgorithm: "SHA256", Checksum: "abc=-3"},
+	} {
+		if v, err := verifyContent(info, "qualquer"); err != nil || v.Verified() {
+			t.Errorf("Esperado download não conferido para %+v, obtido %v", info, err)
+		}
+	}
+}
+
+func TestSHA256Checksum(t *testing.T) {
+	if sum := sha256Checksum("ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"); sum != "ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=" {
+		t.Errorf("Esperado checksum em base64, obtido '%s'", sum)
+	}
+	if sum := sha256Checksum("invalido"); sum != "" {
+		t.Errorf("Esperado vazio para hex inválido, obtido '%s'", sum)
+	}
+}
+
+func TestDownloadFromS3_ConteudoCorrompido(t *testing.T) {
+	root := t.TempDir()
+	mp := &MessageProcessor{config: MessageProcessorConfig{BlobBackend: BlobBackendDir, BlobDir: root}}
+	NewDirBlobStore(root).Put(context.TODO(), "videos", "a.mp4", strings.NewReader("video"), PutOptions{})
+	// Alterar o objeto depois de gravado: o ETag (MD5) não confere mais
+	os.WriteFile(filepath.Join(root, "videos", "a.mp4"), []byte("vide0"), 0644)
+
+	localPath, err := mp.DownloadFromS3(context.TODO(), "videos", "a.mp4")
+	if err == nil || !strings.Contains(err.Error(), "corrompido") {
+		os.Remove(localPath)
+		t.Errorf("Esperado erro de conteúdo corrompido, obtido %v", err)
+	}
+}
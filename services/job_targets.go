@@ -0,0 +1,104 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Prefixo padrão das chaves dos ZIPs gerados
+const defaultOutputPrefix = "processed/"
+
+// Destinos de um job: buckets de origem/resultado, prefixo da chave do ZIP,
+// URL de callback dos resultados (vazia = WEBHOOK_URL, se configurado),
+// tenant, política de retenção do vídeo original, template da chave do ZIP e
+// opções de gravação do ZIP (criptografia, classe e checksum)
+type JobTargets struct {
+	SourceBucket  string
+	ResultsBucket string
+	OutputPrefix  string
+	CallbackURL   string
+	Tenant        string
+	Retention     string
+	KeyTemplate   string
+	Storage       StorageOptions
+}
+
+// ResolveTargets aplica as sobrescritas da mensagem sobre a configuração e
+// valida os buckets contra a allow-list, permitindo que uma mesma frota de
+// workers atenda buckets de vários tenants
+func (mp *MessageProcessor) ResolveTargets(videoMsg VideoProcessingMessage) (JobTargets, error) {
+	targets := JobTargets{
+		SourceBucket:  mp.config.SourceBucket,
+		ResultsBucket: mp.config.ResultsBucket,
+		OutputPrefix:  defaultOutputPrefix,
+		KeyTemplate:   mp.config.ResultKeyTemplate,
+	}
+
+	if videoMsg.SourceBucket != "" {
+		if !mp.config.isBucketAllowed(videoMsg.SourceBucket) {
+			return targets, fmt.Errorf("bucket de origem não permitido: %s", videoMsg.SourceBucket)
+		}
+		targets.SourceBucket = videoMsg.SourceBucket
+	}
+
+	if videoMsg.ResultsBucket != "" {
+		if !mp.config.isBucketAllowed(videoMsg.ResultsBucket) {
+			return targets, fmt.Errorf("bucket de resultados não permitido: %s", videoMsg.ResultsBucket)
+		}
+		targets.ResultsBucket = videoMsg.ResultsBucket
+	}
+
+	if videoMsg.OutputPrefix != "" {
+		prefix, err := normalizeOutputPrefix(videoMsg.OutputPrefix)
+		if err != nil {
+			return targets, err
+		}
+		targets.OutputPrefix = prefix
+	}
+
+	if videoMsg.CallbackURL != "" {
+		if err := mp.config.isCallbackAllowed(videoMsg.CallbackURL); err != nil {
+			return targets, err
+		}
+		targets.CallbackURL = videoMsg.CallbackURL
+	}
+
+	// Sem tenant explícito, cada bucket de origem é um tenant
+	targets.Tenant = videoMsg.Tenant
+	if targets.Tenant == "" {
+		targets.Tenant = targets.SourceBucket
+	}
+	retention, err := mp.config.retentionFor(targets.Tenant, videoMsg.Retention)
+	if err != nil {
+		return targets, err
+	}
+	targets.Retention = retention
+
+	storage, err := mp.config.storageFor(videoMsg)
+	if err != nil {
+		return targets, err
+	}
+	targets.Storage = storage
+
+	return targets, nil
+}
+
+// Chave do ZIP no bucket de resultados, a partir do template (RESULT_KEY_TEMPLATE)
+func (t JobTargets) ZipKey(processID, sourceKey string, at time.Time) (string, error) {
+	return renderResultKey(t.KeyTemplate, resultKeyValues(t, processID, sourceKey, at))
+}
+
+// Prefixo sempre relativo, sem segmentos "..", terminado em "/"
+func normalizeOutputPrefix(prefix string) (string, error) {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return "", nil
+	}
+	for _, segment := range strings.Split(prefix, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", fmt.Errorf("prefixo de saída inválido: %s", prefix)
+		}
+	}
+	return prefix + "/", nil
+}
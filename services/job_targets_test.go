@@ -0,0 +1,105 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveTargets_Padroes(t *testing.T) {
+	mp := &MessageProcessor{config: MessageProcessorConfig{SourceBucket: "src", ResultsBucket: "res", ResultKeyTemplate: defaultResultKeyTemplate}}
+	targets, err := mp.ResolveTargets(VideoProcessingMessage{FileID: "video.mp4", ProcessID: "proc-1"})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if targets.SourceBucket != "src" || targets.ResultsBucket != "res" || targets.OutputPrefix != "processed/" {
+		t.Errorf("Destinos inesperados: %+v", targets)
+	}
+	at := time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC)
+	if key, _ := targets.ZipKey("proc-1", "video.mp4", at); key != "processed/proc-1_frames_20240305_143000.zip" {
+		t.Errorf("Esperado 'processed/proc-1_frames_20240305_143000.zip', obtido '%s'", key)
+	}
+}
+
+func TestResolveTargets_SobrescritasPermitidas(t *testing.T) {
+	mp := &MessageProcessor{config: MessageProcessorConfig{SourceBucket: "src", ResultsBucket: "res", AllowedBuckets: []string{"tenant-a", "tenant-a-out"}}}
+	targets, err := mp.ResolveTargets(VideoProcessingMessage{
+		SourceBucket:  "tenant-a",
+		ResultsBucket: "tenant-a-out",
+		OutputPrefix:  "/clientes/a/",
+	})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if targets.SourceBucket != "tenant-a" || targets.ResultsBucket != "tenant-a-out" {
+		t.Errorf("Buckets inesperados: %+v", targets)
+	}
+	if targets.OutputPrefix != "clientes/a/" {
+		t.Errorf("Esperado prefixo 'clientes/a/', obtido '%s'", targets.OutputPrefix)
+	}
+}
+
+func TestResolveTargets_BucketForaDaAllowList(t *testing.T) {
+	mp := &MessageProcessor{config: MessageProcessorConfig{SourceBucket: "src", ResultsBucket: "res", AllowedBuckets: []string{"tenant-a"}}}
+	if _, err := mp.ResolveTargets(VideoProcessingMessage{SourceBucket: "tenant-b"}); err == nil {
+		t.Error("Esperado erro para bucket de origem fora da allow-list")
+	}
+	if _, err := mp.ResolveTargets(VideoProcessingMessage{ResultsBucket: "tenant-b"}); err == nil {
+		t.Error("Esperado erro para bucket de resultados fora da allow-list")
+	}
+}
+
+func TestResolveTargets_PrefixoInvalido(t *testing.T) {
+	mp := &MessageProcessor{}
+	for _, prefix := range []string{"../fora", "a//b", "a/./b"} {
+		if _, err := mp.ResolveTargets(VideoProcessingMessage{OutputPrefix: prefix}); err == nil {
+			t.Errorf("Esperado erro para prefixo '%s'", prefix)
+		}
+	}
+}
+
+func TestResolveTargets_CallbackForaDaAllowList(t *testing.T) {
+	mp := &MessageProcessor{config: MessageProcessorConfig{WebhookAllowedHosts: []string{"hooks.exemplo.com"}}}
+	if _, err := mp.ResolveTargets(VideoProcessingMessage{CallbackURL: "http://169.254.169.254/latest"}); err == nil {
+		t.Error("Esperado erro para callback fora da allow-list")
+	}
+	targets, err := mp.ResolveTargets(VideoProcessingMessage{CallbackURL: "https://hooks.exemplo.com/videos"})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if targets.CallbackURL != "https://hooks.exemplo.com/videos" {
+		t.Errorf("Esperado callback 'https://hooks.exemplo.com/videos', obtido '%s'", targets.CallbackURL)
+	}
+}
+
+func TestResolveTargets_Retencao(t *testing.T) {
+	mp := &MessageProcessor{config: MessageProcessorConfig{
+		SourceBucket:      "src",
+		AllowedBuckets:    []string{"tenant-a"},
+		SourceRetention:   RetentionKeep,
+		RetentionByTenant: map[string]string{"tenant-a": RetentionArchive, "acme": RetentionTag},
+	}}
+
+	casos := []struct {
+		msg      VideoProcessingMessage
+		tenant   string
+		esperado string
+	}{
+		{VideoProcessingMessage{}, "src", RetentionKeep},
+		{VideoProcessingMessage{SourceBucket: "tenant-a"}, "tenant-a", RetentionArchive},
+		{VideoProcessingMessage{Tenant: "acme"}, "acme", RetentionTag},
+		{VideoProcessingMessage{Tenant: "acme", Retention: "Delete"}, "acme", RetentionDelete},
+	}
+	for _, caso := range casos {
+		targets, err := mp.ResolveTargets(caso.msg)
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		if targets.Tenant != caso.tenant || targets.Retention != caso.esperado {
+			t.Errorf("Esperado %s/%s, obtido %s/%s", caso.tenant, caso.esperado, targets.Tenant, targets.Retention)
+		}
+	}
+
+	if _, err := mp.ResolveTargets(VideoProcessingMessage{Retention: "shred"}); err == nil {
+		t.Error("Esperado erro para política de retenção desconhecida")
+	}
+}
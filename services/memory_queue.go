@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Fila em memória, compartilhada por nome dentro do processo. Útil para
+// desenvolvimento local e testes: não sobrevive a reinícios.
+type MemoryQueue struct {
+	visibility time.Duration
+
+	mu       sync.Mutex
+	ready    []QueueMessage
+	inFlight map[string]memoryDelivery
+	notify   chan struct{} // Fechado e recriado a cada Publish/Nack
+}
+
+// Intervalo para reavaliar visibilidades expiradas durante o long polling
+const memoryQueueExpiryCheck = 100 * time.Millisecond
+
+type memoryDelivery struct {
+	message  QueueMessage
+	deadline time.Time
+}
+
+var (
+	memoryQueuesMu sync.Mutex
+	memoryQueues   = map[string]*MemoryQueue{}
+)
+
+// MemoryQueueNamed retorna a fila em memória com o nome informado, criando-a se necessário
+func MemoryQueueNamed(name string, visibility time.Duration) *MemoryQueue {
+	memoryQueuesMu.Lock()
+	defer memoryQueuesMu.Unlock()
+	if q, ok := memoryQueues[name]; ok {
+		return q
+	}
+	q := NewMemoryQueue(visibility)
+	memoryQueues[name] = q
+	return q
+}
+
+// NewMemoryQueue cria uma fila em memória isolada
+func NewMemoryQueue(visibility time.Duration) *MemoryQueue {
+	if visibility <= 0 {
+		visibility = defaultVisibilityTimeout
+	}
+	return &MemoryQueue{
+		visibility: visibility,
+		inFlight:   make(map[string]memoryDelivery),
+		notify:     make(chan struct{}),
+	}
+}
+
+func (q *MemoryQueue) Receive(ctx context.Context, max int32, wait time.Duration) ([]QueueMessage, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		q.mu.Lock()
+		q.requeueExpired()
+		messages := q.take(int(max))
+		notify := q.notify
+		q.mu.Unlock()
+
+		remaining := time.Until(deadline)
+		if len(messages) > 0 || remaining <= 0 {
+			return messages, nil
+		}
+
+		// Reavaliar periodicamente mensagens cujo visibility timeout expirou
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-notify:
+		case <-time.After(min(remaining, memoryQueueExpiryCheck)):
+		}
+	}
+}
+
+// Retirar até max mensagens prontas; deve ser chamado com q.mu travado
+func (q *MemoryQueue) take(max int) []QueueMessage {
+	if max <= 0 {
+		max = 1
+	}
+	if max > len(q.ready) {
+		max = len(q.ready)
+	}
+	messages := make([]QueueMessage, 0, max)
+	deadline := time.Now().Add(q.visibility)
+	for _, msg := range q.ready[:max] {
+		msg.Handle = newUUID()
+		q.inFlight[msg.Handle] = memoryDelivery{message: msg, deadline: deadline}
+		messages = append(messages, msg)
+	}
+	q.ready = q.ready[max:]
+	return messages
+}
+
+// Devolver para a fila as mensagens com visibility timeout expirado; deve ser chamado com q.mu travado
+func (q *MemoryQueue) requeueExpired() {
+	now := time.Now()
+	for handle, delivery := range q.inFlight {
+		if now.After(delivery.deadline) {
+			delete(q.inFlight, handle)
+			q.ready = append(q.ready, delivery.message)
+		}
+	}
+}
+
+func (q *MemoryQueue) Ack(ctx context.Context, msg QueueMessage) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.inFlight[msg.Handle]; !ok {
+		return fmt.Errorf("entrega desconhecida ou expirada: %s", msg.ID)
+	}
+	delete(q.inFlight, msg.Handle)
+	return nil
+}
+
+func (q *MemoryQueue) Nack(ctx context.Context, msg QueueMessage) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delivery, ok := q.inFlight[msg.Handle]
+	if !ok {
+		return fmt.Errorf("entrega desconhecida ou expirada: %s", msg.ID)
+	}
+	delete(q.inFlight, msg.Handle)
+	q.ready = append(q.ready, delivery.message)
+	q.signal()
+	return nil
+}
+
+func (q *MemoryQueue) ExtendVisibility(ctx context.Context, msg QueueMessage, timeout time.Duration) error {
+	if timeout <= 0 {
+		return q.Nack(ctx, msg)
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delivery, ok := q.inFlight[msg.Handle]
+	if !ok {
+		return fmt.Errorf("entrega desconhecida ou expirada: %s", msg.ID)
+	}
+	delivery.deadline = time.Now().Add(timeout)
+	q.inFlight[msg.Handle] = delivery
+	return nil
+}
+
+func (q *MemoryQueue) Publish(ctx context.Context, msg OutgoingMessage) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.ready = append(q.ready, QueueMessage{ID: newUUID(), Body: msg.Body, Attributes: msg.Attributes})
+	q.signal()
+	return nil
+}
+
+// Quantidade de mensagens prontas e em processamento
+func (q *MemoryQueue) Len() (ready, inFlight int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.ready), len(q.inFlight)
+}
+
+// Acordar receptores aguardando; deve ser chamado com q.mu travado
+func (q *MemoryQueue) signal() {
+	close(q.notify)
+	q.notify = make(chan struct{})
+}
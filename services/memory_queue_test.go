@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryQueue_PublicaRecebeConfirma(t *testing.T) {
+	q := NewMemoryQueue(time.Minute)
+	q.Publish(context.TODO(), OutgoingMessage{Body: "a", Attributes: map[string]string{"correlationId": "c1"}})
+
+	messages, err := q.Receive(context.TODO(), 10, 0)
+	if err != nil || len(messages) != 1 {
+		t.Fatalf("Esperado 1 mensagem, obtido %d (%v)", len(messages), err)
+	}
+	if messages[0].Body != "a" || messages[0].Attributes["correlationId"] != "c1" {
+		t.Errorf("Mensagem inesperada: %+v", messages[0])
+	}
+	if ready, inFlight := q.Len(); ready != 0 || inFlight != 1 {
+		t.Errorf("Esperado 0 pronta e 1 em processamento, obtido %d/%d", ready, inFlight)
+	}
+	if err := q.Ack(context.TODO(), messages[0]); err != nil {
+		t.Errorf("Esperado nil, obtido %v", err)
+	}
+	if ready, inFlight := q.Len(); ready != 0 || inFlight != 0 {
+		t.Errorf("Esperado fila vazia, obtido %d/%d", ready, inFlight)
+	}
+}
+
+func TestMemoryQueue_NackDevolveMensagem(t *testing.T) {
+	q := NewMemoryQueue(time.Minute)
+	q.Publish(context.TODO(), OutgoingMessage{Body: "a"})
+	messages, _ := q.Receive(context.TODO(), 1, 0)
+
+	q.Nack(context.TODO(), messages[0])
+	again, _ := q.Receive(context.TODO(), 1, 0)
+	if len(again) != 1 || again[0].ID != messages[0].ID {
+		t.Errorf("Esperado a mesma mensagem após Nack, obtido %v", again)
+	}
+	if again[0].Handle == messages[0].Handle {
+		t.Error("Esperado novo handle para a nova entrega")
+	}
+	if err := q.Ack(context.TODO(), messages[0]); err == nil {
+		t.Error("Esperado erro ao confirmar entrega antiga")
+	}
+}
+
+func TestMemoryQueue_VisibilidadeExpirada(t *testing.T) {
+	q := NewMemoryQueue(10 * time.Millisecond)
+	q.Publish(context.TODO(), OutgoingMessage{Body: "a"})
+	q.Receive(context.TODO(), 1, 0)
+
+	messages, _ := q.Receive(context.TODO(), 1, 100*time.Millisecond)
+	if len(messages) != 1 {
+		t.Errorf("Esperado mensagem entregue novamente após expirar a visibilidade, obtido %d", len(messages))
+	}
+}
+
+func TestMemoryQueue_ReceiveAguardaPublicacao(t *testing.T) {
+	q := NewMemoryQueue(time.Minute)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.Publish(context.TODO(), OutgoingMessage{Body: "a"})
+	}()
+
+	messages, _ := q.Receive(context.TODO(), 1, time.Second)
+	if len(messages) != 1 {
+		t.Errorf("Esperado mensagem publicada durante o long polling, obtido %d", len(messages))
+	}
+}
+
+func TestMemoryQueueNamed_CompartilhadaPorNome(t *testing.T) {
+	if MemoryQueueNamed("teste-compartilhada", 0) != MemoryQueueNamed("teste-compartilhada", 0) {
+		t.Error("Esperado a mesma fila para o mesmo nome")
+	}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+)
+
+// Envelopes aceitos na fila de entrada, além da VideoProcessingMessage direta:
+//   - notificação de evento do S3 ({"Records":[{"eventSource":"aws:s3",...}]})
+//   - mensagem publicada em tópico SNS ({"Type":"Notification","Message":"..."})
+//   - evento S3 entregue pelo EventBridge ({"source":"aws.s3","detail-type":"Object Created",...})
+
+// Campos usados para identificar o formato da mensagem
+type messageEnvelope struct {
+	// SNS
+	Type    string `json:"Type"`
+	Message string `json:"Message"`
+
+	// Notificação S3
+	Records []s3EventRecord `json:"Records"`
+	Event   string          `json:"Event"`
+
+	// EventBridge
+	Source     string             `json:"source"`
+	DetailType string             `json:"detail-type"`
+	Detail     *eventBridgeDetail `json:"detail"`
+}
+
+type s3EventRecord struct {
+	EventSource string `json:"eventSource"`
+	EventName   string `json:"eventName"`
+	S3          struct {
+		Bucket struct {
+			Name string `json:"name"`
+		} `json:"bucket"`
+		Object s3EventObject `json:"object"`
+	} `json:"s3"`
+}
+
+type s3EventObject struct {
+	Key       string `json:"key"`
+	ETag      string `json:"eTag"`
+	VersionID string `json:"versionId"`
+	Sequencer string `json:"sequencer"`
+}
+
+type eventBridgeDetail struct {
+	Bucket struct {
+		Name string `json:"name"`
+	} `json:"bucket"`
+	Object struct {
+		Key       string `json:"key"`
+		ETag      string `json:"etag"`
+		VersionID string `json:"version-id"`
+		Sequencer string `json:"sequencer"`
+	} `json:"object"`
+}
+
+// Limite de aninhamento de envelopes (ex: S3 → SNS → SQS)
+const maxEnvelopeDepth = 3
+
+// parseVideoMessages identifica o envelope do corpo da mensagem e retorna os
+// vídeos a processar. Uma notificação S3 pode conter vários registros; eventos
+// que não são de criação de objeto (ex: s3:TestEvent) resultam em lista vazia.
+func parseVideoMessages(body string) ([]VideoProcessingMessage, error) {
+	return parseEnvelope(body, 0)
+}
+
+func parseEnvelope(body string, depth int) ([]VideoProcessingMessage, error) {
+	if depth > maxEnvelopeDepth {
+		return nil, fmt.Errorf("envelopes aninhados demais")
+	}
+
+	var env messageEnvelope
+	if err := json.Unmarshal([]byte(body), &env); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case env.Type == "Notification" && env.Message != "":
+		return parseEnvelope(env.Message, depth+1)
+	case env.Type != "" && env.Message != "":
+		log.Printf("ℹ️ Mensagem SNS do tipo %s ignorada", env.Type)
+		return nil, nil
+	case env.Event == "s3:TestEvent":
+		log.Println("ℹ️ Evento de teste do S3 ignorado")
+		return nil, nil
+	case env.Records != nil:
+		return fromS3Records(env.Records)
+	case env.Source == "aws.s3" && env.Detail != nil:
+		return fromEventBridge(env.DetailType, env.Detail)
+	}
+
+	var videoMsg VideoProcessingMessage
+	if err := json.Unmarshal([]byte(body), &videoMsg); err != nil {
+		return nil, err
+	}
+	return []VideoProcessingMessage{videoMsg}, nil
+}
+
+func fromS3Records(records []s3EventRecord) ([]VideoProcessingMessage, error) {
+	var messages []VideoProcessingMessage
+	for _, record := range records {
+		if record.EventSource != "aws:s3" || !strings.HasPrefix(record.EventName, "ObjectCreated:") {
+			log.Printf("ℹ️ Evento %s ignorado", record.EventName)
+			continue
+		}
+
+		// Chaves em notificações S3 vêm codificadas como form (espaço = '+')
+		key, err := url.QueryUnescape(record.S3.Object.Key)
+		if err != nil {
+			return nil, fmt.Errorf("chave S3 inválida %q: %w", record.S3.Object.Key, err)
+		}
+
+		bucket := record.S3.Bucket.Name
+		object := record.S3.Object
+		messages = append(messages, VideoProcessingMessage{
+			FileID:       key,
+			SourceBucket: bucket,
+			ProcessID:    deriveProcessID(bucket, key, object.VersionID, object.Sequencer, object.ETag),
+			fromEvent:    true,
+		})
+	}
+	return messages, nil
+}
+
+func fromEventBridge(detailType string, detail *eventBridgeDetail) ([]VideoProcessingMessage, error) {
+	if detailType != "Object Created" {
+		log.Printf("ℹ️ Evento EventBridge %q ignorado", detailType)
+		return nil, nil
+	}
+	if detail.Object.Key == "" {
+		return nil, fmt.Errorf("evento EventBridge sem chave do objeto")
+	}
+
+	// No EventBridge a chave não vem codificada
+	bucket := detail.Bucket.Name
+	object := detail.Object
+	return []VideoProcessingMessage{{
+		FileID:       object.Key,
+		SourceBucket: bucket,
+		ProcessID:    deriveProcessID(bucket, object.Key, object.VersionID, object.Sequencer, object.ETag),
+		fromEvent:    true,
+	}}, nil
+}
+
+// ProcessID determinístico: a mesma criação de objeto entregue mais de uma vez
+// gera sempre o mesmo ID, enquanto um novo upload na mesma chave gera outro
+func deriveProcessID(bucket, key string, revision ...string) string {
+	h := sha256.New()
+	h.Write([]byte(bucket + "/" + key))
+	for _, r := range revision {
+		if r != "" {
+			h.Write([]byte("#" + r))
+			break
+		}
+	}
+	return "s3-" + hex.EncodeToString(h.Sum(nil))[:32]
+}
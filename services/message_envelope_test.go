@@ -0,0 +1,106 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const s3NotificationBody = `{"Records":[{"eventSource":"aws:s3","eventName":"ObjectCreated:Put",
+	"s3":{"bucket":{"name":"uploads"},"object":{"key":"videos/meu+video%C3%A7.mp4","eTag":"abc","sequencer":"0055AED6DCD90281E5"}}}]}`
+
+func TestParseVideoMessages_MensagemDireta(t *testing.T) {
+	msgs, err := parseVideoMessages(`{"fileId":"videos/video.mp4","processId":"proc-1"}`)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].FileID != "videos/video.mp4" || msgs[0].ProcessID != "proc-1" {
+		t.Errorf("Mensagem inesperada: %+v", msgs)
+	}
+	if msgs[0].SourceBucket != "" {
+		t.Errorf("Esperado bucket vazio, obtido '%s'", msgs[0].SourceBucket)
+	}
+}
+
+func TestParseVideoMessages_NotificacaoS3(t *testing.T) {
+	msgs, err := parseVideoMessages(s3NotificationBody)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("Esperado 1 mensagem, obtido %d", len(msgs))
+	}
+	if msgs[0].FileID != "videos/meu videoç.mp4" {
+		t.Errorf("Esperado chave decodificada, obtido '%s'", msgs[0].FileID)
+	}
+	if msgs[0].SourceBucket != "uploads" {
+		t.Errorf("Esperado bucket 'uploads', obtido '%s'", msgs[0].SourceBucket)
+	}
+	if msgs[0].ProcessID == "" {
+		t.Error("Esperado ProcessID derivado do evento")
+	}
+
+	// Mesmo evento entregue novamente gera o mesmo ProcessID
+	again, _ := parseVideoMessages(s3NotificationBody)
+	if again[0].ProcessID != msgs[0].ProcessID {
+		t.Errorf("Esperado ProcessID determinístico, obtido '%s' e '%s'", msgs[0].ProcessID, again[0].ProcessID)
+	}
+}
+
+func TestParseVideoMessages_EnvelopeSNS(t *testing.T) {
+	wrapped, _ := json.Marshal(map[string]string{
+		"Type":    "Notification",
+		"Message": s3NotificationBody,
+	})
+	msgs, err := parseVideoMessages(string(wrapped))
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	direct, _ := parseVideoMessages(s3NotificationBody)
+	if len(msgs) != 1 || msgs[0] != direct[0] {
+		t.Errorf("Esperado mesmo resultado da notificação direta, obtido %+v", msgs)
+	}
+}
+
+func TestParseVideoMessages_EventBridge(t *testing.T) {
+	body := `{"source":"aws.s3","detail-type":"Object Created",
+		"detail":{"bucket":{"name":"uploads"},"object":{"key":"videos/meu video.mp4","etag":"abc","sequencer":"1"}}}`
+	msgs, err := parseVideoMessages(body)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].FileID != "videos/meu video.mp4" || msgs[0].SourceBucket != "uploads" {
+		t.Errorf("Mensagem inesperada: %+v", msgs)
+	}
+}
+
+func TestParseVideoMessages_EventosIgnorados(t *testing.T) {
+	bodies := []string{
+		`{"Service":"Amazon S3","Event":"s3:TestEvent","Bucket":"uploads"}`,
+		`{"Records":[{"eventSource":"aws:s3","eventName":"ObjectRemoved:Delete","s3":{"bucket":{"name":"b"},"object":{"key":"k"}}}]}`,
+		`{"source":"aws.s3","detail-type":"Object Deleted","detail":{"bucket":{"name":"b"},"object":{"key":"k"}}}`,
+		`{"Type":"SubscriptionConfirmation","Message":"confirme"}`,
+	}
+	for _, body := range bodies {
+		msgs, err := parseVideoMessages(body)
+		if err != nil {
+			t.Errorf("Erro inesperado para %s: %v", body, err)
+		}
+		if len(msgs) != 0 {
+			t.Errorf("Esperado nenhum vídeo para %s, obtido %+v", body, msgs)
+		}
+	}
+}
+
+func TestParseVideoMessages_JSONInvalido(t *testing.T) {
+	if _, err := parseVideoMessages("{invalid json}"); err == nil {
+		t.Error("Esperado erro para JSON inválido")
+	}
+}
+
+func TestDeriveProcessID_NovaVersaoGeraNovoID(t *testing.T) {
+	first := deriveProcessID("b", "k", "", "seq-1")
+	second := deriveProcessID("b", "k", "", "seq-2")
+	if first == second {
+		t.Error("Esperado ProcessID diferente para uploads diferentes na mesma chave")
+	}
+}
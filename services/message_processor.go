@@ -1,17 +1,23 @@
 package services
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
-	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -19,10 +25,27 @@ import (
 
 // Estrutura da mensagem SQS esperada
 // Exemplo: { "fileId": "videos/video.mp4", "processId": "proc-123" }
+// Notificações de evento do S3 (diretas, via SNS ou EventBridge) também são aceitas,
+// ver parseVideoMessages. Buckets, prefixo e URL de callback opcionais sobrescrevem
+// a configuração (ver ResolveTargets)
 type VideoProcessingMessage struct {
-	FileID    string `json:"fileId"`
-	ProcessID string `json:"processId"`
-	MessageID string `json:"message_id,omitempty"`
+	FileID        string `json:"fileId"`
+	ProcessID     string `json:"processId"`
+	SourceBucket  string `json:"sourceBucket,omitempty"`
+	ResultsBucket string `json:"resultsBucket,omitempty"`
+	OutputPrefix  string `json:"outputPrefix,omitempty"`
+	CallbackURL   string `json:"callbackUrl,omitempty"`
+	Tenant        string `json:"tenant,omitempty"`    // Padrão: bucket de origem
+	Retention     string `json:"retention,omitempty"` // delete, keep, tag ou archive
+	MessageID     string `json:"message_id,omitempty"`
+
+	// Gravação do ZIP: AES256 ou aws:kms (com chave opcional), classe e checksum
+	Encryption        string `json:"encryption,omitempty"`
+	KMSKeyID          string `json:"kmsKeyId,omitempty"`
+	StorageClass      string `json:"storageClass,omitempty"`
+	ChecksumAlgorithm string `json:"checksumAlgorithm,omitempty"`
+
+	fromEvent bool // Gerada a partir de uma notificação do S3 (ProcessID derivado)
 }
 
 // Estrutura da mensagem de resultado
@@ -31,18 +54,21 @@ type VideoProcessingResult struct {
 	ZipKey    string `json:"zipKey"`
 	Status    string `json:"status"`
 	Timestamp string `json:"timestamp"`
-}
 
-// Configuração do processador de mensagens
-type MessageProcessorConfig struct {
-	SQSQueueURL     string
-	ResultsQueueURL string
-	LocalStackURL   string
-	AWSRegion       string
-	SourceBucket    string
-	ResultsBucket   string
-	PollingInterval time.Duration
-	MaxMessages     int32
+	// URL temporária de download do ZIP e sua expiração (apenas em COMPLETED)
+	DownloadURL          string `json:"downloadUrl,omitempty"`
+	DownloadURLExpiresAt string `json:"downloadUrlExpiresAt,omitempty"`
+
+	// SHA-256 (hex) do ZIP (apenas em COMPLETED)
+	SHA256 string `json:"sha256,omitempty"`
+
+	// Destino do vídeo original (apenas em COMPLETED)
+	Retention *RetentionOutcome `json:"retention,omitempty"`
+
+	// Criptografia, classe e checksum do ZIP gravado (apenas em COMPLETED)
+	Storage *StoredObject `json:"storage,omitempty"`
+
+	zipBucket string // Bucket do ZIP, para renovar DownloadURL no reenvio (ver outbox)
 }
 
 // Processador principal de mensagens
@@ -50,35 +76,98 @@ type MessageProcessor struct {
 	config    MessageProcessorConfig
 	sqsClient SQSClient
 	s3Client  S3Client
+	snsClient SNSClient
+
+	// Controle de drenagem no shutdown
+	mu         sync.Mutex
+	inFlight   map[string]inFlightMessage
+	stopped    chan struct{}
+	cancelJobs context.CancelFunc
+
+	// Filas de entrada ponderadas (ver getQueues), de resultados e de controle,
+	// no backend configurado (ver newQueue)
+	queues  []*inputQueue
+	results Queue
+	control Queue
+
+	// Armazenamento de vídeos e resultados (ver BlobStore) e URLs de download
+	blobs     BlobStore
+	presigner S3Presigner
+
+	// Callbacks HTTP dos resultados (ver webhooks) e destinos dos eventos de status
+	webhook   *webhookPublisher
+	publisher ResultPublisher
+
+	// Resultados gravados antes do envio e reenviados até a confirmação (ver outbox)
+	resultsOutbox *resultOutbox
+
+	// Circuit breakers dos clients S3 e SQS (ver CircuitBreakers)
+	breakers []*circuitBreaker
+
+	// Jobs em execução, canceláveis por mensagem de controle
+	jobs jobRegistry
+}
+
+// Mensagem recebida e ainda não concluída, com a fila de origem
+type inFlightMessage struct {
+	queue   *inputQueue
+	message QueueMessage
 }
 
 // Criar novo processador de mensagens
 func NewMessageProcessor(config MessageProcessorConfig) (*MessageProcessor, error) {
+	if err := config.validateQueueBackend(); err != nil {
+		return nil, err
+	}
+	if err := config.validateBlobBackend(); err != nil {
+		return nil, err
+	}
+	if err := config.validateResultSinks(); err != nil {
+		return nil, err
+	}
+	if err := config.validateRetention(); err != nil {
+		return nil, err
+	}
+	if err := validateResultKeyTemplate(config.ResultKeyTemplate); err != nil {
+		return nil, err
+	}
+	if err := config.validateResultStorage(); err != nil {
+		return nil, err
+	}
+	if err := config.validatePresign(); err != nil {
+		return nil, err
+	}
+	if err := config.validateDirectUploads(); err != nil {
+		return nil, err
+	}
+
 	cfg, err := config.loadAWSConfig()
 	if err != nil {
 		return nil, fmt.Errorf("erro ao carregar configuração AWS: %w", err)
 	}
 
-	var sqsClient *sqs.Client
-	var s3Client *s3.Client
-	if config.LocalStackURL != "" {
-		sqsClient = sqs.NewFromConfig(cfg, func(o *sqs.Options) {
-			o.BaseEndpoint = aws.String(config.LocalStackURL)
-		})
-		s3Client = s3.NewFromConfig(cfg, func(o *s3.Options) {
-			o.BaseEndpoint = aws.String(config.LocalStackURL)
-			o.UsePathStyle = true // Necessário para LocalStack
-		})
-	} else {
-		sqsClient = sqs.NewFromConfig(cfg)
-		s3Client = s3.NewFromConfig(cfg)
+	sqsClient, s3Client, snsClient := config.newAWSClients(cfg)
+
+	// Novas tentativas com backoff e circuit breaker em volta do S3 e do SQS
+	policy := retryPolicy{
+		maxAttempts: config.AWSMaxAttempts,
+		backoffMin:  config.AWSRetryBackoffMin,
+		backoffMax:  config.AWSRetryBackoffMax,
+		timeout:     config.AWSOperationTimeout,
 	}
+	s3Breaker := newCircuitBreaker("s3", config.CircuitBreakerThreshold, config.CircuitBreakerCooldown)
+	sqsBreaker := newCircuitBreaker("sqs", config.CircuitBreakerThreshold, config.CircuitBreakerCooldown)
 
-	return &MessageProcessor{
+	mp := &MessageProcessor{
 		config:    config,
-		sqsClient: sqsClient,
-		s3Client:  s3Client,
-	}, nil
+		sqsClient: newResilientSQSClient(sqsClient, policy, sqsBreaker),
+		s3Client:  newResilientS3Client(s3Client, policy, s3Breaker),
+		snsClient: snsClient,
+		presigner: newS3Presigner(s3Client, config.PresignEndpoint),
+		breakers:  []*circuitBreaker{s3Breaker, sqsBreaker},
+	}
+	mp.getQueues()
+	return mp, nil
 }
 
 // Carregar configuração AWS
@@ -88,154 +177,610 @@ func (c MessageProcessorConfig) loadAWSConfig() (aws.Config, error) {
 	)
 }
 
+// Clientes AWS (apontando para o LocalStack, se configurado). O S3 e o SQS
+// ficam sem o retryer padrão do SDK: as novas tentativas são de callAWS, e os
+// dois juntos fariam até AWS_MAX_ATTEMPTS × 3 requisições por operação
+func (c MessageProcessorConfig) newAWSClients(cfg aws.Config) (*sqs.Client, *s3.Client, *sns.Client) {
+	sqsOpts := []func(*sqs.Options){func(o *sqs.Options) { o.Retryer = aws.NopRetryer{} }}
+	s3Opts := []func(*s3.Options){func(o *s3.Options) { o.Retryer = aws.NopRetryer{} }}
+	var snsOpts []func(*sns.Options)
+	if c.LocalStackURL != "" {
+		sqsOpts = append(sqsOpts, func(o *sqs.Options) {
+			o.BaseEndpoint = aws.String(c.LocalStackURL)
+		})
+		s3Opts = append(s3Opts, func(o *s3.Options) {
+			o.BaseEndpoint = aws.String(c.LocalStackURL)
+			o.UsePathStyle = true // Necessário para LocalStack
+		})
+		snsOpts = append(snsOpts, func(o *sns.Options) {
+			o.BaseEndpoint = aws.String(c.LocalStackURL)
+		})
+	}
+	return sqs.NewFromConfig(cfg, sqsOpts...), s3.NewFromConfig(cfg, s3Opts...), sns.NewFromConfig(cfg, snsOpts...)
+}
+
 // Iniciar o loop de processamento de mensagens
 func (mp *MessageProcessor) StartProcessing(ctx context.Context) {
-	log.Printf("🚀 Iniciando processamento de mensagens SQS")
-	log.Printf("📡 Queue: %s", mp.config.SQSQueueURL)
-	log.Printf("⏱️  Intervalo: %s", mp.config.PollingInterval)
+	log.Printf("🚀 Iniciando processamento de mensagens (backend: %s)", mp.queueBackend())
+	log.Printf("📤 Destinos de resultados: %s", describeResultSinks(mp.resultPublisher()))
+	for _, q := range mp.getQueues() {
+		log.Printf("📡 Queue: %s (peso %d)", q.url, q.weight)
+	}
+	log.Printf("⏱️  Long polling: %ds, backoff: %s-%s", mp.waitTimeSeconds(), mp.config.PollBackoffMin, mp.config.PollingInterval)
+
+	// Jobs em andamento não são interrompidos pelo cancelamento do ctx de recebimento;
+	// só são cancelados por Shutdown quando o período de tolerância expira
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	stopped := make(chan struct{})
+
+	mp.mu.Lock()
+	mp.stopped = stopped
+	mp.cancelJobs = cancelJobs
+	mp.mu.Unlock()
+
+	defer close(stopped)
+	defer cancelJobs()
 
-	ticker := time.NewTicker(mp.config.PollingInterval)
-	defer ticker.Stop()
+	// A fila de controle é consumida enquanto houver jobs em andamento
+	if control := mp.controlQueue(); control != nil {
+		go mp.pollControlQueue(jobCtx, control)
+	}
+
+	// Resultados pendentes de execuções anteriores são reenviados já na inicialização
+	if outbox := mp.outbox(); outbox != nil {
+		log.Printf("📮 Outbox de resultados: %s", outbox.dir)
+		go mp.runOutboxRelay(jobCtx, outbox)
+	}
+
+	// Após um lote com mensagens, o próximo long polling começa imediatamente;
+	// erros e respostas vazias aumentam a espera exponencialmente até PollingInterval.
+	// Com várias filas, uma resposta vazia significa que todas estavam vazias.
+	wait := newBackoff(mp.config.PollBackoffMin, mp.config.PollingInterval)
+	idle := false
 
 	for {
+		if ctx.Err() != nil {
+			log.Println("🛑 Parando processamento de mensagens")
+			return
+		}
+
+		// Com o S3 ou o SQS indisponível, novas mensagens só falhariam: o
+		// recebimento fica pausado até o circuito aceitar uma chamada de teste
+		if pause := mp.circuitPause(); pause > 0 {
+			log.Printf("⏸️  Circuit breaker aberto, recebimento pausado por %s", pause.Round(time.Second))
+			timer := time.NewTimer(pause)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				log.Println("🛑 Parando processamento de mensagens")
+				return
+			case <-timer.C:
+			}
+			continue
+		}
+
+		received, err := mp.processMessages(ctx, jobCtx)
+		if err == nil && received > 0 {
+			wait.Reset()
+			idle = false
+			continue
+		}
+
+		if err == nil && !idle {
+			log.Println("📭 Fila vazia, aguardando novas mensagens")
+			idle = true
+		}
+
+		timer := time.NewTimer(wait.Next())
 		select {
 		case <-ctx.Done():
+			timer.Stop()
 			log.Println("🛑 Parando processamento de mensagens")
 			return
-		case <-ticker.C:
-			mp.processMessages(ctx)
+		case <-timer.C:
 		}
 	}
 }
 
-// Processar mensagens da fila SQS
-func (mp *MessageProcessor) processMessages(ctx context.Context) {
-	resp, err := mp.sqsClient.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
-		QueueUrl:            aws.String(mp.config.SQSQueueURL),
-		MaxNumberOfMessages: mp.config.MaxMessages,
-		WaitTimeSeconds:     5, // Long polling
-	})
+// Tempo de long polling do ReceiveMessage (limite do SQS: 0-20s)
+func (mp *MessageProcessor) waitTimeSeconds() int32 {
+	if mp.config.WaitTimeSeconds < 0 {
+		return 0
+	}
+	if mp.config.WaitTimeSeconds > 20 {
+		return 20
+	}
+	return mp.config.WaitTimeSeconds
+}
 
-	if err != nil {
-		log.Printf("❌ Erro ao receber mensagens SQS: %v", err)
-		return
+// Shutdown aguarda a finalização dos jobs em andamento até o prazo do ctx.
+// Deve ser chamado após cancelar o ctx passado para StartProcessing. Se o prazo
+// expirar, os jobs são cancelados e as mensagens não concluídas voltam para a fila
+// (visibility timeout = 0) para serem reprocessadas por outra instância.
+func (mp *MessageProcessor) Shutdown(ctx context.Context) error {
+	mp.mu.Lock()
+	stopped := mp.stopped
+	cancelJobs := mp.cancelJobs
+	mp.mu.Unlock()
+
+	if stopped == nil {
+		return mp.flushBatches(ctx)
 	}
 
-	if len(resp.Messages) == 0 {
-		log.Println("📭 Nenhuma mensagem na fila")
-		return
+	log.Printf("⏳ Aguardando finalização dos jobs em andamento...")
+	select {
+	case <-stopped:
+		log.Println("✅ Todos os jobs em andamento foram finalizados")
+		return mp.flushBatches(ctx)
+	case <-ctx.Done():
+		log.Printf("⚠️ Período de tolerância expirado, liberando mensagens não concluídas")
+		if cancelJobs != nil {
+			cancelJobs()
+		}
+		mp.releaseInFlight(context.Background())
+
+		// Exclusões já enfileiradas correspondem a jobs concluídos: tentar enviá-las mesmo assim
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := mp.flushBatches(flushCtx); err != nil {
+			log.Printf("⚠️ %v", err)
+		}
+		return ctx.Err()
 	}
+}
 
-	log.Printf("📨 Recebidas %d mensagem(s)", len(resp.Messages))
+// Aguardar as novas tentativas de webhook em background e enviar exclusões e
+// resultados ainda pendentes nos lotes
+func (mp *MessageProcessor) flushBatches(ctx context.Context) error {
+	mp.mu.Lock()
+	webhook := mp.webhook
+	mp.mu.Unlock()
+	if webhook != nil {
+		if err := webhook.wait(ctx); err != nil {
+			log.Printf("⚠️ Novas tentativas de webhook interrompidas, callbacks guardados para reenvio: %v", err)
+		}
+	}
 
-	for _, message := range resp.Messages {
-		mp.processMessage(ctx, message)
+	backends := []Queue{mp.resultsQueue(), mp.controlQueue()}
+	for _, q := range mp.getQueues() {
+		backends = append(backends, q.backend)
 	}
+	for _, backend := range backends {
+		closer, ok := backend.(queueCloser)
+		if !ok {
+			continue
+		}
+		if err := closer.Close(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (mp *MessageProcessor) processMessage(ctx context.Context, message types.Message) {
-	log.Printf("🔄 Processando mensagem: %s", *message.MessageId)
+// Processar as mensagens recebidas e retornar quantas foram recebidas
+// ctx controla o recebimento; jobCtx é repassado para o processamento de cada mensagem
+func (mp *MessageProcessor) processMessages(ctx, jobCtx context.Context) (int, error) {
+	queue, messages, err := mp.receive(ctx)
+	if err != nil || len(messages) == 0 {
+		return 0, err
+	}
+	jobCtx = withInputQueue(jobCtx, queue)
 
-	var videoMsg VideoProcessingMessage
-	if err := json.Unmarshal([]byte(*message.Body), &videoMsg); err != nil {
-		log.Printf("❌ Erro ao fazer parse da mensagem: %v", err)
-		mp.deleteMessage(ctx, message)
-		return
+	log.Printf("📨 Recebidas %d mensagem(s) de %s", len(messages), queue.name)
+	queue.recordReceived(len(messages))
+
+	for _, message := range messages {
+		mp.trackMessage(jobCtx, message)
 	}
 
-	videoMsg.MessageID = *message.MessageId
-	log.Printf("📹 Processando vídeo: s3://%s/%s (ProcessID: %s)", mp.config.SourceBucket, videoMsg.FileID, videoMsg.ProcessID)
+	for _, message := range messages {
+		// Em drenagem: não iniciar novos jobs, devolver as mensagens para a fila
+		if ctx.Err() != nil {
+			mp.releaseMessage(jobCtx, message)
+			mp.untrackMessage(message)
+			continue
+		}
+		stopHeartbeat := mp.keepInvisible(jobCtx, queue.backend, message)
+		queue.recordOutcome(mp.processMessage(jobCtx, message))
+		stopHeartbeat()
+		mp.untrackMessage(message)
+	}
+	return len(messages), nil
+}
+
+// Receber a próxima leva de mensagens. Com uma fila, até MaxMessages por long
+// polling. Com várias, uma mensagem por vez, para que a fila seja escolhida de
+// novo pelo peso a cada job: as filas são consultadas sem espera, a escolhida
+// pelo round-robin ponderado primeiro; se todas estiverem vazias, o long polling
+// é feito em todas em paralelo
+func (mp *MessageProcessor) receive(ctx context.Context) (*inputQueue, []QueueMessage, error) {
+	queues := mp.getQueues()
+	wait := time.Duration(mp.waitTimeSeconds()) * time.Second
+	if len(queues) == 1 {
+		messages, err := mp.receiveFrom(ctx, queues[0], mp.config.MaxMessages, wait)
+		return queues[0], messages, err
+	}
+
+	failures := 0
+	var lastErr error
+	for _, queue := range mp.queueOrder() {
+		messages, err := mp.receiveFrom(ctx, queue, 1, 0)
+		if err != nil {
+			failures++
+			lastErr = err
+			continue
+		}
+		if len(messages) > 0 {
+			return queue, messages, nil
+		}
+	}
+	if failures == len(queues) || ctx.Err() != nil {
+		return nil, nil, lastErr
+	}
+	if wait <= 0 {
+		return nil, nil, nil
+	}
+	return mp.receiveAny(ctx, queues, wait)
+}
 
-	// Enviar notificação de início do processamento
-	err := mp.SendProcessingResult(ctx, videoMsg.ProcessID, "", "IN_PROGRESS")
+// Receber de uma fila, registrando os erros
+func (mp *MessageProcessor) receiveFrom(ctx context.Context, queue *inputQueue, max int32, wait time.Duration) ([]QueueMessage, error) {
+	messages, err := queue.backend.Receive(ctx, max, wait)
+	if err != nil && ctx.Err() == nil {
+		log.Printf("❌ Erro ao receber mensagens (%s): %v", queue.name, err)
+	}
+	return messages, err
+}
+
+// Long polling em todas as filas em paralelo. A primeira mensagem recebida é
+// processada e as demais consultas são canceladas; mensagens recebidas por elas
+// mesmo assim voltam imediatamente para a fila. Uma consulta cancelada depois de
+// o SQS já ter entregue a mensagem a deixa invisível até o visibility timeout
+func (mp *MessageProcessor) receiveAny(ctx context.Context, queues []*inputQueue, wait time.Duration) (*inputQueue, []QueueMessage, error) {
+	pollCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type polled struct {
+		queue    *inputQueue
+		messages []QueueMessage
+		err      error
+	}
+	results := make(chan polled, len(queues))
+	for _, queue := range queues {
+		go func() {
+			messages, err := mp.receiveFrom(pollCtx, queue, 1, wait)
+			results <- polled{queue, messages, err}
+		}()
+	}
+
+	var first *polled
+	failures := 0
+	var lastErr error
+	for range queues {
+		result := <-results
+		switch {
+		case result.err != nil:
+			if pollCtx.Err() == nil {
+				failures++
+				lastErr = result.err
+			}
+		case len(result.messages) == 0:
+		case first == nil:
+			first = &result
+			cancel()
+		default:
+			for _, message := range result.messages {
+				mp.releaseMessage(withInputQueue(ctx, result.queue), message)
+			}
+		}
+	}
+	if first != nil {
+		return first.queue, first.messages, nil
+	}
+	if failures == len(queues) {
+		return nil, nil, lastErr
+	}
+	return nil, nil, nil
+}
+
+// Registrar mensagem recebida e ainda não concluída
+func (mp *MessageProcessor) trackMessage(ctx context.Context, message QueueMessage) {
+	queue := mp.queueFrom(ctx)
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if mp.inFlight == nil {
+		mp.inFlight = make(map[string]inFlightMessage)
+	}
+	mp.inFlight[message.ID] = inFlightMessage{queue: queue, message: message}
+}
+
+func (mp *MessageProcessor) untrackMessage(message QueueMessage) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	delete(mp.inFlight, message.ID)
+}
+
+// Devolver para a fila todas as mensagens ainda não concluídas
+func (mp *MessageProcessor) releaseInFlight(ctx context.Context) {
+	mp.mu.Lock()
+	pending := make([]inFlightMessage, 0, len(mp.inFlight))
+	for id, entry := range mp.inFlight {
+		pending = append(pending, entry)
+		delete(mp.inFlight, id)
+	}
+	mp.mu.Unlock()
+
+	for _, entry := range pending {
+		mp.releaseMessage(withInputQueue(ctx, entry.queue), entry.message)
+	}
+}
+
+// Tornar a mensagem visível imediatamente (visibility timeout = 0)
+func (mp *MessageProcessor) releaseMessage(ctx context.Context, message QueueMessage) {
+	err := mp.queueFrom(ctx).backend.Nack(ctx, message)
 	if err != nil {
-		log.Printf("⚠️ Erro ao enviar notificação de início: %v", err)
+		log.Printf("❌ Erro ao devolver mensagem para a fila: %v", err)
+	} else {
+		log.Printf("↩️  Mensagem devolvida para a fila: %s", message.ID)
 	}
+}
 
-	// Baixar arquivo do S3
-	localPath, err := mp.DownloadFromS3(ctx, mp.config.SourceBucket, videoMsg.FileID)
+// Processar uma mensagem e retornar se foi concluída com sucesso
+func (mp *MessageProcessor) processMessage(ctx context.Context, message QueueMessage) bool {
+	ctx = withTrace(ctx, traceFromAttributes(message.Attributes))
+	logf(ctx, "🔄 Processando mensagem: %s", message.ID)
+
+	// Na fila de entrada, um cancelamento só seria lido depois do job que deveria
+	// interromper (o consumo é sequencial): mensagens de controle são recusadas
+	if control, ok := parseControlMessage(message.Body); ok {
+		logf(ctx, "⚠️ Mensagem de controle (%s) recusada na fila de entrada: envie para CONTROL_QUEUE_URL", control.Action)
+		mp.deleteMessage(ctx, message)
+		return false
+	}
+
+	videoMsgs, err := parseVideoMessages(message.Body)
 	if err != nil {
-		log.Printf("❌ Erro ao baixar do S3: %v", err)
-		// Enviar notificação de erro
+		logf(ctx, "❌ Erro ao fazer parse da mensagem: %v", err)
+		mp.deleteMessage(ctx, message)
+		return false
+	}
+
+	// Todos os vídeos da mensagem precisam ser concluídos para removê-la da fila
+	completed := true
+	for _, videoMsg := range videoMsgs {
+		videoMsg, ok := mp.directUploadEvent(videoMsg)
+		if !ok {
+			logf(ctx, "ℹ️ Evento do upload direto %s ignorado, aguardando confirmação do cliente", videoMsg.FileID)
+			continue
+		}
+		videoMsg.MessageID = message.ID
+		if !mp.processVideoMessage(ctx, videoMsg) {
+			completed = false
+		}
+	}
+
+	if completed {
+		// Deletar mensagem da fila após sucesso completo
+		mp.deleteMessage(ctx, message)
+	}
+	// Caso contrário a mensagem voltará para a fila após visibility timeout
+	return completed
+}
+
+// Processar um vídeo e retornar se a mensagem pode ser removida da fila
+// (processamento concluído ou mensagem rejeitada de forma definitiva)
+func (mp *MessageProcessor) processVideoMessage(ctx context.Context, videoMsg VideoProcessingMessage) bool {
+	ctx = withResultSequence(ctx)
+	targets, err := mp.ResolveTargets(videoMsg)
+	if err != nil {
+		logf(ctx, "❌ Mensagem rejeitada (ProcessID: %s): %v", videoMsg.ProcessID, err)
 		mp.SendProcessingResult(ctx, videoMsg.ProcessID, "", "FAILED")
-		return
+		return true
+	}
+	if targets.CallbackURL != "" {
+		ctx = withCallbackURL(ctx, targets.CallbackURL)
 	}
-	defer os.Remove(localPath) // Limpar arquivo local após processamento
 
-	// Processar vídeo
-	timestamp := time.Now().Format("20060102_150405")
-	result := ProcessVideo(localPath, timestamp)
+	// Chave do ZIP definida no início do job (o template usa a data de início)
+	startedAt := time.Now().UTC()
+	zipS3Key, err := targets.ZipKey(videoMsg.ProcessID, videoMsg.FileID, startedAt)
+	if err != nil {
+		logf(ctx, "❌ Mensagem rejeitada (ProcessID: %s): %v", videoMsg.ProcessID, err)
+		mp.SendProcessingResult(ctx, videoMsg.ProcessID, "", "FAILED")
+		return true
+	}
 
-	if result.Success {
-		log.Printf("✅ Vídeo processado com sucesso: %s", result.ZipPath)
+	if mp.jobs.takePending(videoMsg.ProcessID) {
+		return mp.finishCancelledJob(ctx, videoMsg.ProcessID, "", "")
+	}
+	ctx, done := mp.jobs.start(ctx, videoMsg.ProcessID)
+	defer done()
 
-		// Upload do ZIP para S3 com ProcessID único
-		zipS3Key := fmt.Sprintf("processed/%s_%s", videoMsg.ProcessID, result.ZipPath)
-		localZipPath := filepath.Join("outputs", result.ZipPath)
+	sourceBucket := targets.SourceBucket
+	logf(ctx, "📹 Processando vídeo: s3://%s/%s (ProcessID: %s)", sourceBucket, videoMsg.FileID, videoMsg.ProcessID)
 
-		log.Printf("📤 Iniciando upload: %s → s3://%s/%s", localZipPath, mp.config.ResultsBucket, zipS3Key)
+	// Workspace isolado do job, removido ao final (inclusive em panic)
+	ws, err := NewWorkspace(NewJobID())
+	if err != nil {
+		logf(ctx, "❌ %v", err)
+		mp.SendProcessingResult(ctx, videoMsg.ProcessID, "", "FAILED")
+		return false
+	}
+	defer ws.Cleanup()
+	ctx = withWorkspace(ctx, ws)
+	logf(ctx, "🧰 Job %s: workspace %s", ws.JobID, ws.Dir)
+
+	// Conferir o espaço em disco antes de anunciar o início: um job adiado
+	// por falta de espaço não deve aparecer como IN_PROGRESS
+	var localPath string
+	err = mp.checkSourceFits(ctx, sourceBucket, videoMsg.FileID)
+	if err == nil {
+		// Enviar notificação de início do processamento
+		if err := mp.SendProcessingResult(ctx, videoMsg.ProcessID, "", "IN_PROGRESS"); err != nil {
+			logf(ctx, "⚠️ Erro ao enviar notificação de início: %v", err)
+		}
 
-		err := mp.UploadZipToS3(ctx, mp.config.ResultsBucket, zipS3Key, localZipPath)
-		if err != nil {
-			log.Printf("❌ Erro ao enviar ZIP para S3: %v", err)
-			// Enviar notificação de erro
+		// Baixar arquivo do S3
+		localPath, err = mp.download(ctx, sourceBucket, videoMsg.FileID)
+	}
+	if err != nil && isJobCancelled(ctx) {
+		return mp.finishCancelledJob(ctx, videoMsg.ProcessID, "", "")
+	}
+	var diskErr *DiskSpaceError
+	if errors.As(err, &diskErr) {
+		if diskErr.Permanent() {
+			logf(ctx, "❌ Job recusado (ProcessID: %s): %v", videoMsg.ProcessID, err)
 			mp.SendProcessingResult(ctx, videoMsg.ProcessID, "", "FAILED")
-			return
+			return true
 		}
+		// Transitório: a mensagem volta para a fila após o visibility timeout
+		logf(ctx, "⏳ Job adiado (ProcessID: %s): %v", videoMsg.ProcessID, err)
+		return false
+	}
+	if err != nil {
+		logf(ctx, "❌ Erro ao baixar do S3: %v", err)
+		// Enviar notificação de erro
+		mp.SendProcessingResult(ctx, videoMsg.ProcessID, "", "FAILED")
+		return false
+	}
+	defer os.Remove(localPath) // Limpar arquivo local após processamento
 
-		// Remover ZIP local após upload bem-sucedido
-		if err := os.Remove(localZipPath); err != nil {
-			log.Printf("⚠️ Aviso: Erro ao remover ZIP local: %v", err)
-		} else {
-			log.Printf("🗑️ ZIP local removido: %s", localZipPath)
-		}
+	// Processar vídeo
+	result := ProcessVideoContext(ctx, localPath, ws.JobID)
 
-		// Excluir arquivo original do S3 após processamento
-		_, err = mp.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
-			Bucket: aws.String(mp.config.SourceBucket),
-			Key:    aws.String(videoMsg.FileID),
-		})
-		if err != nil {
-			log.Printf("⚠️ Aviso: Erro ao excluir arquivo original do S3: %v", err)
-		} else {
-			log.Printf("🗑️ Arquivo original excluído do S3: s3://%s/%s", mp.config.SourceBucket, videoMsg.FileID)
+	if isJobCancelled(ctx) {
+		if result.Success {
+			os.Remove(ws.Path(result.ZipPath))
 		}
+		return mp.finishCancelledJob(ctx, videoMsg.ProcessID, "", "")
+	}
+	if !result.Success {
+		logf(ctx, "❌ Erro no processamento: %s", result.Message)
+		// Enviar notificação de erro
+		mp.SendProcessingResult(ctx, videoMsg.ProcessID, "", "FAILED")
+		return false
+	}
 
-		// Deletar mensagem da fila após sucesso completo
-		mp.deleteMessage(ctx, message)
+	logf(ctx, "✅ Vídeo processado com sucesso: %s", result.ZipPath)
 
-		// Enviar resultado para fila de resultados
-		err = mp.SendProcessingResult(ctx, videoMsg.ProcessID, zipS3Key, "COMPLETED")
-		if err != nil {
-			log.Printf("⚠️ Erro ao enviar notificação de resultado: %v", err)
+	// Upload do ZIP para S3 com ProcessID único
+	localZipPath := ws.Path(result.ZipPath)
+
+	logf(ctx, "📤 Iniciando upload: %s → s3://%s/%s", localZipPath, targets.ResultsBucket, zipS3Key)
+
+	opts := resultObjectOptions(zipS3Key, videoMsg.FileID, result)
+	opts.StorageOptions = targets.Storage
+	if opts.ChecksumAlgorithm == "SHA256" {
+		// SHA-256 calculado na criação do ZIP: o S3 confere o que sai do disco
+		opts.Checksum = sha256Checksum(result.SHA256)
+	}
+	stored, err := mp.uploadResult(ctx, targets.ResultsBucket, zipS3Key, localZipPath, opts)
+	if isJobCancelled(ctx) {
+		os.Remove(localZipPath)
+		uploaded := ""
+		if err == nil {
+			uploaded = zipS3Key
 		}
-	} else {
-		log.Printf("❌ Erro no processamento: %s", result.Message)
+		return mp.finishCancelledJob(ctx, videoMsg.ProcessID, targets.ResultsBucket, uploaded)
+	}
+	if err != nil {
+		logf(ctx, "❌ Erro ao enviar ZIP para S3: %v", err)
 		// Enviar notificação de erro
 		mp.SendProcessingResult(ctx, videoMsg.ProcessID, "", "FAILED")
-		// Mensagem voltará para a fila após visibility timeout
+		return false
+	}
+
+	// Com o ZIP enviado o job não é mais cancelável: concluir a limpeza e a notificação
+	ctx = context.WithoutCancel(ctx)
+
+	// Remover ZIP local após upload bem-sucedido
+	if err := os.Remove(localZipPath); err != nil {
+		logf(ctx, "⚠️ Aviso: Erro ao remover ZIP local: %v", err)
+	} else {
+		logf(ctx, "🗑️ ZIP local removido: %s", localZipPath)
+	}
+
+	// Aplicar a política de retenção ao arquivo original (padrão: excluir)
+	retention := mp.applyRetention(ctx, targets.Retention, sourceBucket, videoMsg.FileID, videoMsg.ProcessID)
+
+	// Enviar resultado para fila de resultados
+	completed := VideoProcessingResult{
+		ProcessID: videoMsg.ProcessID,
+		ZipKey:    zipS3Key,
+		Status:    "COMPLETED",
+		SHA256:    result.SHA256,
+		Retention: &retention,
+		Storage:   &stored,
+		zipBucket: targets.ResultsBucket,
 	}
+	url, expiresAt, err := mp.PresignDownload(ctx, targets.ResultsBucket, zipS3Key)
+	if err == nil {
+		completed.DownloadURL = url
+		completed.DownloadURLExpiresAt = expiresAt.Format(time.RFC3339)
+	} else if !errors.Is(err, ErrPresignUnavailable) {
+		logf(ctx, "⚠️ Aviso: %v", err)
+	}
+	err = mp.publishResult(ctx, completed)
+	if err != nil {
+		logf(ctx, "⚠️ Erro ao enviar notificação de resultado: %v", err)
+	}
+	return true
 }
 
-// Baixar arquivo do S3
+// Baixar arquivo do armazenamento configurado (S3 ou diretório local), se ele
+// e o que ele vai gerar couberem no disco
 func (mp *MessageProcessor) DownloadFromS3(ctx context.Context, bucket, key string) (string, error) {
-	log.Printf("⬇️  Baixando s3://%s/%s", bucket, key)
+	if err := mp.checkSourceFits(ctx, bucket, key); err != nil {
+		return "", err
+	}
+	return mp.download(ctx, bucket, key)
+}
 
-	resp, err := mp.s3Client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
-	})
+// Conferir se o vídeo e o que ele vai gerar cabem no disco (DISK_EXPANSION_FACTOR)
+func (mp *MessageProcessor) checkSourceFits(ctx context.Context, bucket, key string) error {
+	if mp.config.DiskExpansionFactor <= 0 {
+		return nil
+	}
+	head, err := mp.BlobStore().Head(ctx, bucket, key)
+	if err != nil {
+		return fmt.Errorf("erro ao consultar objeto S3: %w", err)
+	}
+	return checkDiskSpace(head.Size, mp.config.DiskExpansionFactor, mp.config.DiskReserveBytes, mp.stateDirs()...)
+}
+
+// Diretórios fora dos de trabalho em que o job grava estado (OUTBOX_DIR, WEBHOOK_FAILED_DIR)
+func (mp *MessageProcessor) stateDirs() []string {
+	var dirs []string
+	for _, dir := range []string{mp.config.OutboxDir, mp.config.WebhookFailedDir} {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// Baixar o arquivo, sem conferir o espaço em disco (ver DownloadFromS3)
+func (mp *MessageProcessor) download(ctx context.Context, bucket, key string) (string, error) {
+	logf(ctx, "⬇️  Baixando s3://%s/%s", bucket, key)
+
+	body, info, err := mp.BlobStore().Get(ctx, bucket, key)
 	if err != nil {
 		return "", fmt.Errorf("erro ao baixar objeto S3: %w", err)
 	}
-	defer resp.Body.Close()
+	defer body.Close()
+	verifier := newDownloadVerifier(info)
 
-	// Criar arquivo local
+	// Criar arquivo local: no workspace do job ou, fora do worker (ex:
+	// /api/process-message), com nome único em UPLOADS_DIR
 	filename := filepath.Base(key)
-	localPath := filepath.Join("uploads", fmt.Sprintf("sqs_%d_%s", time.Now().Unix(), filename))
-
-	// Garantir que o diretório existe
-	os.MkdirAll("uploads", 0755)
+	var localPath string
+	if ws, ok := workspaceFrom(ctx); ok {
+		localPath = ws.Path(filename)
+	} else {
+		uploadsDir := Scratch().Uploads
+		os.MkdirAll(uploadsDir, 0755)
+		localPath = filepath.Join(uploadsDir, NewJobID()+"_"+filename)
+	}
 
 	file, err := os.Create(localPath)
 	if err != nil {
@@ -243,51 +788,102 @@ func (mp *MessageProcessor) DownloadFromS3(ctx context.Context, bucket, key stri
 	}
 	defer file.Close()
 
-	// Copiar dados do S3 para arquivo local
-	_, err = file.ReadFrom(resp.Body)
+	// Copiar dados do S3 para arquivo local, calculando os hashes no caminho
+	_, err = file.ReadFrom(io.TeeReader(body, verifier.Writer()))
 	if err != nil {
 		os.Remove(localPath)
 		return "", fmt.Errorf("erro ao salvar arquivo: %w", err)
 	}
+	if err := verifier.Verify(); err != nil {
+		os.Remove(localPath)
+		return "", err
+	}
+	if verifier.Verified() {
+		logf(ctx, "🔐 Download conferido (%s), SHA-256: %s", verifier.source, verifier.SHA256())
+	} else {
+		logf(ctx, "⚠️ Aviso: objeto sem checksum ou ETag MD5 para conferir, SHA-256: %s", verifier.SHA256())
+	}
 
-	log.Printf("📁 Arquivo salvo em: %s", localPath)
+	logf(ctx, "📁 Arquivo salvo em: %s", localPath)
 	return localPath, nil
 }
 
-// Upload do ZIP processado para S3
+// Upload do ZIP processado para o armazenamento configurado
 func (mp *MessageProcessor) UploadZipToS3(ctx context.Context, bucket, key, localZipPath string) error {
-	log.Printf("📤 Enviando ZIP para S3: s3://%s/%s", bucket, key)
+	_, err := mp.uploadResult(ctx, bucket, key, localZipPath, PutOptions{
+		ContentType:        "application/zip",
+		ContentDisposition: attachmentDisposition(path.Base(key)),
+		StorageOptions:     mp.config.resultStorage(),
+	})
+	return err
+}
+
+// Upload do ZIP com as opções do job (metadados de rastreamento são acrescentados).
+// Com um algoritmo de checksum, o valor calculado localmente é enviado e conferido
+// com o retornado pelo armazenamento
+func (mp *MessageProcessor) uploadResult(ctx context.Context, bucket, key, localZipPath string, opts PutOptions) (StoredObject, error) {
+	logf(ctx, "📤 Enviando ZIP para S3: s3://%s/%s", bucket, key)
+
+	if opts.ChecksumAlgorithm != "" && opts.Checksum == "" {
+		checksum, err := fileChecksum(localZipPath, opts.ChecksumAlgorithm)
+		if err != nil {
+			return StoredObject{}, fmt.Errorf("erro ao calcular checksum do ZIP: %w", err)
+		}
+		opts.Checksum = checksum
+	}
 
 	// Abrir arquivo ZIP local
 	file, err := os.Open(localZipPath)
 	if err != nil {
-		return fmt.Errorf("erro ao abrir ZIP local: %w", err)
+		return StoredObject{}, fmt.Errorf("erro ao abrir ZIP local: %w", err)
 	}
 	defer file.Close()
 
 	// Upload para S3
-	_, err = mp.s3Client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
-		Body:   file,
-	})
+	if tc, ok := traceFrom(ctx); ok {
+		opts.Metadata = maps.Clone(opts.Metadata)
+		if opts.Metadata == nil {
+			opts.Metadata = make(map[string]string)
+		}
+		maps.Copy(opts.Metadata, tc.objectMetadata())
+	}
+
+	info, err := mp.BlobStore().Put(ctx, bucket, key, file, opts)
 	if err != nil {
-		return fmt.Errorf("erro ao enviar ZIP para S3: %w", err)
+		return StoredObject{}, fmt.Errorf("erro ao enviar ZIP para S3: %w", err)
 	}
 
-	log.Printf("✅ ZIP enviado com sucesso: s3://%s/%s", bucket, key)
-	return nil
+	if opts.Checksum != "" {
+		switch info.Checksum {
+		case opts.Checksum:
+			logf(ctx, "🔐 Checksum %s confirmado: %s", opts.ChecksumAlgorithm, info.Checksum)
+		case "":
+			logf(ctx, "⚠️ Aviso: armazenamento não retornou o checksum %s", opts.ChecksumAlgorithm)
+		default:
+			return StoredObject{}, fmt.Errorf("checksum %s divergente: esperado %s, obtido %s", opts.ChecksumAlgorithm, opts.Checksum, info.Checksum)
+		}
+	}
+
+	logf(ctx, "✅ ZIP enviado com sucesso: s3://%s/%s", bucket, key)
+
+	// Registrar o que o armazenamento aplicou; sem resposta, o que foi pedido
+	stored := StoredObject{
+		Encryption:        cmp.Or(info.Encryption, opts.Encryption),
+		KMSKeyID:          cmp.Or(info.KMSKeyID, opts.KMSKeyID),
+		StorageClass:      cmp.Or(info.StorageClass, opts.StorageClass),
+		ChecksumAlgorithm: opts.ChecksumAlgorithm,
+		Checksum:          cmp.Or(info.Checksum, opts.Checksum),
+		ETag:              info.ETag,
+	}
+	return stored, nil
 }
 
-func (mp *MessageProcessor) deleteMessage(ctx context.Context, message types.Message) {
-	_, err := mp.sqsClient.DeleteMessage(ctx, &sqs.DeleteMessageInput{
-		QueueUrl:      aws.String(mp.config.SQSQueueURL),
-		ReceiptHandle: message.ReceiptHandle,
-	})
+func (mp *MessageProcessor) deleteMessage(ctx context.Context, message QueueMessage) {
+	err := mp.queueFrom(ctx).backend.Ack(ctx, message)
 	if err != nil {
-		log.Printf("❌ Erro ao deletar mensagem: %v", err)
+		logf(ctx, "❌ Erro ao deletar mensagem: %v", err)
 	} else {
-		log.Printf("🗑️  Mensagem deletada: %s", *message.MessageId)
+		logf(ctx, "🗑️  Mensagem deletada: %s", message.ID)
 	}
 }
 
@@ -296,42 +892,69 @@ func (mp *MessageProcessor) GetSourceBucket() string {
 	return mp.config.SourceBucket
 }
 
-// Enviar resultado do processamento para fila de resultados
+// Enviar resultado do processamento para os destinos configurados
+// (fila de resultados, tópico SNS, webhook, log; ver resultPublisher)
 func (mp *MessageProcessor) SendProcessingResult(ctx context.Context, processID, zipKey, status string) error {
-	if mp.config.ResultsQueueURL == "" {
-		log.Printf("⚠️ Fila de resultados não configurada, pulando notificação")
-		return nil
-	}
-
-	result := VideoProcessingResult{
+	return mp.publishResult(ctx, VideoProcessingResult{
 		ProcessID: processID,
 		ZipKey:    zipKey,
 		Status:    status,
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// Publicar o resultado completo (com os detalhes do job), preenchendo o timestamp
+func (mp *MessageProcessor) publishResult(ctx context.Context, result VideoProcessingResult) error {
+	publisher := mp.resultPublisher()
+	if publisher == nil {
+		logf(ctx, "⚠️ Fila de resultados não configurada, pulando notificação")
+		return nil
 	}
 
+	result.Timestamp = time.Now().UTC().Format(time.RFC3339)
 	resultJSON, err := json.Marshal(result)
 	if err != nil {
 		return fmt.Errorf("erro ao serializar resultado: %w", err)
 	}
 
-	log.Printf("📨 Enviando resultado para fila: %s", string(resultJSON))
-
-	input := &sqs.SendMessageInput{
-		QueueUrl:    aws.String(mp.config.ResultsQueueURL),
-		MessageBody: aws.String(string(resultJSON)),
+	event := ResultEvent{
+		Result:   result,
+		Body:     resultJSON,
+		Sequence: nextResultSequence(ctx),
 	}
-	// Adiciona MessageGroupId se a fila for FIFO
-	if len(mp.config.ResultsQueueURL) > 5 && mp.config.ResultsQueueURL[len(mp.config.ResultsQueueURL)-5:] == ".fifo" {
-		input.MessageGroupId = aws.String("soat-fiap-x-group")
+
+	outbox := mp.outbox()
+	if outbox == nil {
+		return publisher.PublishResult(ctx, event)
 	}
 
-	_, err = mp.sqsClient.SendMessage(ctx, input)
+	// O evento é gravado antes do envio: se algum destino falhar, o relay
+	// reenvia mesmo que a mensagem de entrada já tenha sido removida
+	entry := outboxEntry{
+		Result:      result,
+		Body:        resultJSON,
+		Sequence:    event.Sequence,
+		CallbackURL: callbackURLFrom(ctx),
+		ZipBucket:   result.zipBucket,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if tc, ok := traceFrom(ctx); ok {
+		entry.Attributes = tc.messageAttributes()
+	}
+	path, err := outbox.record(entry)
 	if err != nil {
-		return fmt.Errorf("erro ao enviar mensagem para fila de resultados: %w", err)
+		logf(ctx, "⚠️ Erro ao gravar resultado no outbox, enviando diretamente: %v", err)
+		return publisher.PublishResult(ctx, event)
+	}
+	// Um evento anterior do job ainda pendente seria ultrapassado: este fica na
+	// fila do outbox e o relay os entrega em ordem
+	if outbox.pendingBefore(path, result.ProcessID) {
+		outbox.release(path)
+		logf(ctx, "📮 Resultado %s aguardando no outbox a entrega dos eventos anteriores do job", result.Status)
+		return nil
+	}
+	if err := mp.deliverOutboxEntry(ctx, outbox, path, entry); err != nil {
+		logf(ctx, "📮 Resultado %s mantido no outbox para reenvio: %v", result.Status, err)
 	}
-
-	log.Printf("✅ Resultado enviado com sucesso para fila de resultados")
 	return nil
 }
 
@@ -341,10 +964,21 @@ type SQSClient interface {
 	ReceiveMessage(ctx context.Context, input *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
 	SendMessage(ctx context.Context, input *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
 	DeleteMessage(ctx context.Context, input *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+	ChangeMessageVisibility(ctx context.Context, input *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
+	DeleteMessageBatch(ctx context.Context, input *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error)
+	SendMessageBatch(ctx context.Context, input *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)
 }
 
 type S3Client interface {
 	GetObject(ctx context.Context, input *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
 	PutObject(ctx context.Context, input *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
 	DeleteObject(ctx context.Context, input *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	HeadObject(ctx context.Context, input *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	ListObjectsV2(ctx context.Context, input *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	CopyObject(ctx context.Context, input *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error)
+	PutObjectTagging(ctx context.Context, input *s3.PutObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.PutObjectTaggingOutput, error)
+	GetObjectTagging(ctx context.Context, input *s3.GetObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.GetObjectTaggingOutput, error)
+	CreateMultipartUpload(ctx context.Context, input *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
+	CompleteMultipartUpload(ctx context.Context, input *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(ctx context.Context, input *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
 }
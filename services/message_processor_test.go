@@ -7,7 +7,9 @@ import (
 	"io"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
@@ -27,6 +29,30 @@ func (m *mockS3ClientErro) DeleteObject(ctx context.Context, input *s3.DeleteObj
 func (m *mockS3ClientErro) GetObject(ctx context.Context, input *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
 	return &s3.GetObjectOutput{}, nil
 }
+func (m *mockS3ClientErro) HeadObject(ctx context.Context, input *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	return &s3.HeadObjectOutput{}, nil
+}
+func (m *mockS3ClientErro) ListObjectsV2(ctx context.Context, input *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	return &s3.ListObjectsV2Output{}, nil
+}
+func (m *mockS3ClientErro) CopyObject(ctx context.Context, input *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	return &s3.CopyObjectOutput{}, nil
+}
+func (m *mockS3ClientErro) PutObjectTagging(ctx context.Context, input *s3.PutObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.PutObjectTaggingOutput, error) {
+	return &s3.PutObjectTaggingOutput{}, nil
+}
+func (m *mockS3ClientErro) GetObjectTagging(ctx context.Context, input *s3.GetObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.GetObjectTaggingOutput, error) {
+	return &s3.GetObjectTaggingOutput{}, nil
+}
+func (m *mockS3ClientErro) CreateMultipartUpload(ctx context.Context, input *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	return &s3.CreateMultipartUploadOutput{UploadId: aws.String("upload-1")}, nil
+}
+func (m *mockS3ClientErro) CompleteMultipartUpload(ctx context.Context, input *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+func (m *mockS3ClientErro) AbortMultipartUpload(ctx context.Context, input *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
 
 func TestGetSourceBucket(t *testing.T) {
 	config := MessageProcessorConfig{SourceBucket: "bucket-test"}
@@ -66,7 +92,7 @@ func TestNewMessageProcessor_MinimalConfig(t *testing.T) {
 func TestProcessMessage_InvalidJSON(t *testing.T) {
 	mock := &mockSQSClient{}
 	mp := &MessageProcessor{sqsClient: mock}
-	msg := types.Message{MessageId: ptr("id1"), Body: ptr("{invalid json}")}
+	msg := QueueMessage{ID: "id1", Body: "{invalid json}"}
 	mp.processMessage(context.TODO(), msg)
 	// Espera não panicar e logar erro
 }
@@ -101,9 +127,12 @@ type mockSQSClient struct {
 	failReceive bool
 	failSend    bool
 	failDelete  bool
+	released    []string
+	received    *sqs.ReceiveMessageInput
 }
 
 func (m *mockSQSClient) ReceiveMessage(ctx context.Context, input *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	m.received = input
 	if m.failReceive {
 		return nil, errors.New("erro simulado no ReceiveMessage")
 	}
@@ -121,6 +150,16 @@ func (m *mockSQSClient) DeleteMessage(ctx context.Context, input *sqs.DeleteMess
 	}
 	return &sqs.DeleteMessageOutput{}, nil
 }
+func (m *mockSQSClient) DeleteMessageBatch(ctx context.Context, input *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+	return &sqs.DeleteMessageBatchOutput{}, nil
+}
+func (m *mockSQSClient) SendMessageBatch(ctx context.Context, input *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	return &sqs.SendMessageBatchOutput{}, nil
+}
+func (m *mockSQSClient) ChangeMessageVisibility(ctx context.Context, input *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+	m.released = append(m.released, *input.ReceiptHandle)
+	return &sqs.ChangeMessageVisibilityOutput{}, nil
+}
 
 // Mock S3Client para testes
 // Retorna erro ou sucesso simulado
@@ -141,27 +180,51 @@ func (m *mockS3Client) PutObject(ctx context.Context, input *s3.PutObjectInput,
 func (m *mockS3Client) DeleteObject(ctx context.Context, input *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
 	return &s3.DeleteObjectOutput{}, nil
 }
+func (m *mockS3Client) HeadObject(ctx context.Context, input *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	return &s3.HeadObjectOutput{}, nil
+}
+func (m *mockS3Client) ListObjectsV2(ctx context.Context, input *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	return &s3.ListObjectsV2Output{}, nil
+}
+func (m *mockS3Client) CopyObject(ctx context.Context, input *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	return &s3.CopyObjectOutput{}, nil
+}
+func (m *mockS3Client) PutObjectTagging(ctx context.Context, input *s3.PutObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.PutObjectTaggingOutput, error) {
+	return &s3.PutObjectTaggingOutput{}, nil
+}
+func (m *mockS3Client) GetObjectTagging(ctx context.Context, input *s3.GetObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.GetObjectTaggingOutput, error) {
+	return &s3.GetObjectTaggingOutput{}, nil
+}
+func (m *mockS3Client) CreateMultipartUpload(ctx context.Context, input *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	return &s3.CreateMultipartUploadOutput{UploadId: aws.String("upload-1")}, nil
+}
+func (m *mockS3Client) CompleteMultipartUpload(ctx context.Context, input *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+func (m *mockS3Client) AbortMultipartUpload(ctx context.Context, input *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
 
 func TestProcessMessages_Success(t *testing.T) {
 	msg := types.Message{MessageId: ptr("id1"), Body: ptr(`{"fileId":"video.mp4","processId":"proc-1"}`), ReceiptHandle: ptr("rh1")}
 	mockSQS := &mockSQSClient{messages: []types.Message{msg}}
 	mockS3 := &mockS3Client{}
 	mp := &MessageProcessor{config: MessageProcessorConfig{SQSQueueURL: "url", MaxMessages: 1}, sqsClient: mockSQS, s3Client: mockS3}
-	mp.processMessages(context.TODO())
+	mp.processMessages(context.TODO(), context.TODO())
 	// Espera processar mensagem sem panic
 }
 
 func TestProcessMessages_Error(t *testing.T) {
 	mock := &mockSQSClient{failReceive: true}
 	mp := &MessageProcessor{config: MessageProcessorConfig{SQSQueueURL: "url", MaxMessages: 1}, sqsClient: mock}
-	mp.processMessages(context.TODO())
+	mp.processMessages(context.TODO(), context.TODO())
 	// Espera logar erro e não panicar
 }
 
 func TestDeleteMessage_Error(t *testing.T) {
 	mock := &mockSQSClient{failDelete: true}
 	mp := &MessageProcessor{config: MessageProcessorConfig{SQSQueueURL: "url"}, sqsClient: mock}
-	msg := types.Message{MessageId: ptr("id1"), ReceiptHandle: ptr("rh1")}
+	msg := QueueMessage{ID: "id1", Handle: "rh1"}
 	mp.deleteMessage(context.TODO(), msg)
 	// Espera logar erro e não panicar
 }
@@ -179,6 +242,15 @@ func (m *mockSQSClientErro) SendMessage(ctx context.Context, input *sqs.SendMess
 func (m *mockSQSClientErro) ReceiveMessage(ctx context.Context, input *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
 	return &sqs.ReceiveMessageOutput{}, nil
 }
+func (m *mockSQSClientErro) DeleteMessageBatch(ctx context.Context, input *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+	return &sqs.DeleteMessageBatchOutput{}, nil
+}
+func (m *mockSQSClientErro) SendMessageBatch(ctx context.Context, input *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	return &sqs.SendMessageBatchOutput{}, nil
+}
+func (m *mockSQSClientErro) ChangeMessageVisibility(ctx context.Context, input *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+	return &sqs.ChangeMessageVisibilityOutput{}, nil
+}
 func (m *mockSQSClientErro) DeleteMessage(ctx context.Context, input *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
 	return &sqs.DeleteMessageOutput{}, nil
 }
@@ -205,11 +277,35 @@ func (m *mockS3ClientGetErro) PutObject(ctx context.Context, input *s3.PutObject
 func (m *mockS3ClientGetErro) DeleteObject(ctx context.Context, input *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
 	return &s3.DeleteObjectOutput{}, nil
 }
+func (m *mockS3ClientGetErro) HeadObject(ctx context.Context, input *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	return &s3.HeadObjectOutput{}, nil
+}
+func (m *mockS3ClientGetErro) ListObjectsV2(ctx context.Context, input *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	return &s3.ListObjectsV2Output{}, nil
+}
+func (m *mockS3ClientGetErro) CopyObject(ctx context.Context, input *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	return &s3.CopyObjectOutput{}, nil
+}
+func (m *mockS3ClientGetErro) PutObjectTagging(ctx context.Context, input *s3.PutObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.PutObjectTaggingOutput, error) {
+	return &s3.PutObjectTaggingOutput{}, nil
+}
+func (m *mockS3ClientGetErro) GetObjectTagging(ctx context.Context, input *s3.GetObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.GetObjectTaggingOutput, error) {
+	return &s3.GetObjectTaggingOutput{}, nil
+}
+func (m *mockS3ClientGetErro) CreateMultipartUpload(ctx context.Context, input *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	return &s3.CreateMultipartUploadOutput{UploadId: aws.String("upload-1")}, nil
+}
+func (m *mockS3ClientGetErro) CompleteMultipartUpload(ctx context.Context, input *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+func (m *mockS3ClientGetErro) AbortMultipartUpload(ctx context.Context, input *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
 
 func TestProcessMessage_ErroParseJSON(t *testing.T) {
 	mockSQS := &mockSQSClient{}
 	mp := &MessageProcessor{sqsClient: mockSQS}
-	msg := types.Message{MessageId: ptr("id1"), Body: ptr("{invalid json}")}
+	msg := QueueMessage{ID: "id1", Body: "{invalid json}"}
 	mp.processMessage(context.TODO(), msg)
 	// Espera não panicar e logar erro
 }
@@ -218,7 +314,7 @@ func TestProcessMessage_ErroNoDownloadS3(t *testing.T) {
 	mockSQS := &mockSQSClient{}
 	mockS3 := &mockS3ClientGetErro{}
 	mp := &MessageProcessor{sqsClient: mockSQS, s3Client: mockS3, config: MessageProcessorConfig{SourceBucket: "bucket"}}
-	msg := types.Message{MessageId: ptr("id1"), Body: ptr(`{"fileId":"video.mp4","processId":"proc-1"}`)}
+	msg := QueueMessage{ID: "id1", Body: `{"fileId":"video.mp4","processId":"proc-1"}`}
 	mp.processMessage(context.TODO(), msg)
 	// Espera não panicar e logar erro
 }
@@ -245,10 +341,19 @@ func (m *mockSQSClientDeleteErro) SendMessage(ctx context.Context, input *sqs.Se
 func (m *mockSQSClientDeleteErro) ReceiveMessage(ctx context.Context, input *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
 	return &sqs.ReceiveMessageOutput{}, nil
 }
+func (m *mockSQSClientDeleteErro) DeleteMessageBatch(ctx context.Context, input *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+	return &sqs.DeleteMessageBatchOutput{}, nil
+}
+func (m *mockSQSClientDeleteErro) SendMessageBatch(ctx context.Context, input *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	return &sqs.SendMessageBatchOutput{}, nil
+}
+func (m *mockSQSClientDeleteErro) ChangeMessageVisibility(ctx context.Context, input *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+	return &sqs.ChangeMessageVisibilityOutput{}, nil
+}
 
 func TestDeleteMessage_ErroNoDeleteSQS(t *testing.T) {
 	mp := &MessageProcessor{config: MessageProcessorConfig{SQSQueueURL: "url"}, sqsClient: &mockSQSClientDeleteErro{}}
-	msg := types.Message{MessageId: ptr("id1"), ReceiptHandle: ptr("rh1")}
+	msg := QueueMessage{ID: "id1", Handle: "rh1"}
 	mp.deleteMessage(context.TODO(), msg)
 	// Espera logar erro de deleção na fila e não panicar
 }
@@ -267,6 +372,30 @@ func (m *mockS3ClientDeleteErro) PutObject(ctx context.Context, input *s3.PutObj
 func (m *mockS3ClientDeleteErro) GetObject(ctx context.Context, input *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
 	return &s3.GetObjectOutput{}, nil
 }
+func (m *mockS3ClientDeleteErro) HeadObject(ctx context.Context, input *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	return &s3.HeadObjectOutput{}, nil
+}
+func (m *mockS3ClientDeleteErro) ListObjectsV2(ctx context.Context, input *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	return &s3.ListObjectsV2Output{}, nil
+}
+func (m *mockS3ClientDeleteErro) CopyObject(ctx context.Context, input *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	return &s3.CopyObjectOutput{}, nil
+}
+func (m *mockS3ClientDeleteErro) PutObjectTagging(ctx context.Context, input *s3.PutObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.PutObjectTaggingOutput, error) {
+	return &s3.PutObjectTaggingOutput{}, nil
+}
+func (m *mockS3ClientDeleteErro) GetObjectTagging(ctx context.Context, input *s3.GetObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.GetObjectTaggingOutput, error) {
+	return &s3.GetObjectTaggingOutput{}, nil
+}
+func (m *mockS3ClientDeleteErro) CreateMultipartUpload(ctx context.Context, input *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	return &s3.CreateMultipartUploadOutput{UploadId: aws.String("upload-1")}, nil
+}
+func (m *mockS3ClientDeleteErro) CompleteMultipartUpload(ctx context.Context, input *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+func (m *mockS3ClientDeleteErro) AbortMultipartUpload(ctx context.Context, input *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
 
 func TestDeleteObject_ErroNoDelete(t *testing.T) {
 	mp := &MessageProcessor{s3Client: &mockS3ClientDeleteErro{}}
@@ -281,7 +410,7 @@ func TestProcessMessage_ErroNoProcessamento(t *testing.T) {
 	mockS3 := &mockS3Client{}
 	mp := &MessageProcessor{sqsClient: mockSQS, s3Client: mockS3, config: MessageProcessorConfig{SourceBucket: "bucket"}}
 	// Simula vídeo inválido para forçar erro no processamento
-	msg := types.Message{MessageId: ptr("id1"), Body: ptr(`{"fileId":"arquivo_invalido.mp4","processId":"proc-1"}`)}
+	msg := QueueMessage{ID: "id1", Body: `{"fileId":"arquivo_invalido.mp4","processId":"proc-1"}`}
 	mp.processMessage(context.TODO(), msg)
 	// Espera não panicar e logar erro
 }
@@ -292,13 +421,118 @@ func TestProcessMessages_MultiplasMensagens(t *testing.T) {
 	mockSQS := &mockSQSClient{messages: []types.Message{msg1, msg2}}
 	mockS3 := &mockS3Client{}
 	mp := &MessageProcessor{config: MessageProcessorConfig{SQSQueueURL: "url", MaxMessages: 2, SourceBucket: "bucket"}, sqsClient: mockSQS, s3Client: mockS3}
-	mp.processMessages(context.TODO())
+	mp.processMessages(context.TODO(), context.TODO())
 	// Espera processar múltiplas mensagens sem panic
 }
 
 func TestProcessMessages_ErroNoReceiveMessage(t *testing.T) {
 	mockSQS := &mockSQSClient{failReceive: true}
 	mp := &MessageProcessor{config: MessageProcessorConfig{SQSQueueURL: "url", MaxMessages: 1}, sqsClient: mockSQS}
-	mp.processMessages(context.TODO())
+	mp.processMessages(context.TODO(), context.TODO())
 	// Espera logar erro e não panicar
 }
+
+func TestShutdown_SemProcessamentoIniciado(t *testing.T) {
+	mp := &MessageProcessor{}
+	if err := mp.Shutdown(context.TODO()); err != nil {
+		t.Errorf("Esperado nil, obtido %v", err)
+	}
+}
+
+func TestShutdown_AguardaFinalizacao(t *testing.T) {
+	mockSQS := &mockSQSClient{}
+	mp := &MessageProcessor{config: MessageProcessorConfig{SQSQueueURL: "url", MaxMessages: 1, PollingInterval: time.Hour}, sqsClient: mockSQS}
+	ctx, cancel := context.WithCancel(context.Background())
+	go mp.StartProcessing(ctx)
+	for {
+		mp.mu.Lock()
+		started := mp.stopped != nil
+		mp.mu.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), time.Second)
+	defer cancelShutdown()
+	if err := mp.Shutdown(shutdownCtx); err != nil {
+		t.Errorf("Esperado nil, obtido %v", err)
+	}
+}
+
+func TestShutdown_PrazoExpiradoLiberaMensagens(t *testing.T) {
+	mockSQS := &mockSQSClient{}
+	mp := &MessageProcessor{config: MessageProcessorConfig{SQSQueueURL: "url"}, sqsClient: mockSQS, stopped: make(chan struct{})}
+	mp.trackMessage(context.TODO(), QueueMessage{ID: "id1", Handle: "rh1"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := mp.Shutdown(ctx); err == nil {
+		t.Error("Esperado erro de prazo expirado")
+	}
+	if len(mockSQS.released) != 1 || mockSQS.released[0] != "rh1" {
+		t.Errorf("Esperado mensagem rh1 devolvida para a fila, obtido %v", mockSQS.released)
+	}
+}
+
+func TestProcessMessages_EmDrenagemDevolveMensagens(t *testing.T) {
+	msg := types.Message{MessageId: ptr("id1"), Body: ptr(`{"fileId":"video.mp4","processId":"proc-1"}`), ReceiptHandle: ptr("rh1")}
+	mockSQS := &mockSQSClient{messages: []types.Message{msg}}
+	mp := &MessageProcessor{config: MessageProcessorConfig{SQSQueueURL: "url", MaxMessages: 1}, sqsClient: mockSQS}
+
+	// Mock não respeita o cancelamento no ReceiveMessage: simula mensagens recebidas durante o shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	mp.processMessages(ctx, context.TODO())
+	if len(mockSQS.released) != 1 {
+		t.Errorf("Esperado 1 mensagem devolvida para a fila, obtido %d", len(mockSQS.released))
+	}
+	if len(mp.inFlight) != 0 {
+		t.Errorf("Esperado nenhuma mensagem em andamento, obtido %d", len(mp.inFlight))
+	}
+}
+
+// Mock SQSClient que entrega mensagens nas primeiras chamadas e cancela o ctx depois
+type mockSQSClientContinuo struct {
+	mockSQSClient
+	receives int
+	limit    int
+	cancel   context.CancelFunc
+}
+
+func (m *mockSQSClientContinuo) ReceiveMessage(ctx context.Context, input *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	m.receives++
+	if m.receives >= m.limit {
+		m.cancel()
+		return &sqs.ReceiveMessageOutput{}, nil
+	}
+	msg := types.Message{MessageId: ptr("id"), Body: ptr("{invalid json}"), ReceiptHandle: ptr("rh")}
+	return &sqs.ReceiveMessageOutput{Messages: []types.Message{msg}}, nil
+}
+
+func TestStartProcessing_PollingContinuoAposLoteComMensagens(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mockSQS := &mockSQSClientContinuo{limit: 5, cancel: cancel}
+	mp := &MessageProcessor{config: MessageProcessorConfig{SQSQueueURL: "url", MaxMessages: 1, PollBackoffMin: time.Hour, PollingInterval: time.Hour}, sqsClient: mockSQS}
+
+	start := time.Now()
+	mp.StartProcessing(ctx)
+	if time.Since(start) > time.Second {
+		t.Errorf("Esperado polling imediato após lotes com mensagens, levou %s", time.Since(start))
+	}
+	if mockSQS.receives != 5 {
+		t.Errorf("Esperado 5 chamadas ao ReceiveMessage, obtido %d", mockSQS.receives)
+	}
+}
+
+func TestWaitTimeSeconds_Limites(t *testing.T) {
+	cases := map[int32]int32{-1: 0, 0: 0, 10: 10, 30: 20}
+	for input, expected := range cases {
+		mp := &MessageProcessor{config: MessageProcessorConfig{WaitTimeSeconds: input}}
+		if got := mp.waitTimeSeconds(); got != expected {
+			t.Errorf("WaitTimeSeconds %d: esperado %d, obtido %d", input, expected, got)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Métricas do processador de mensagens, expostas em /metrics
+
+var queueMessagesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "video_processor_queue_messages_total",
+	Help: "Mensagens por fila de entrada e resultado (received, completed, failed)",
+}, []string{"queue", "outcome"})
+
+var resultPublishTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "video_processor_result_publish_total",
+	Help: "Eventos de resultado por destino (published, failed, skipped)",
+}, []string{"sink", "outcome"})
+
+var resultPublishDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "video_processor_result_publish_duration_seconds",
+	Help:    "Tempo de entrega dos eventos de resultado por destino",
+	Buckets: prometheus.DefBuckets,
+}, []string{"sink"})
+
+var awsRetriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "video_processor_aws_retries_total",
+	Help: "Novas tentativas de chamadas à AWS por serviço e operação",
+}, []string{"client", "operation"})
+
+var circuitBreakerOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "video_processor_circuit_breaker_open",
+	Help: "1 enquanto o circuit breaker do serviço estiver aberto",
+}, []string{"client"})
+
+var outboxPending = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "video_processor_outbox_pending",
+	Help: "Resultados no outbox aguardando confirmação dos destinos",
+})
+
+var outboxRelayedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "video_processor_outbox_relayed_total",
+	Help: "Reenvios de resultados do outbox (relayed, failed, dead)",
+}, []string{"outcome"})
+
+var janitorReclaimedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "video_processor_janitor_reclaimed_total",
+	Help: "Workspaces, arquivos órfãos e ZIPs expirados removidos por diretório (temp, uploads, outputs)",
+}, []string{"dir"})
+
+var janitorReclaimedBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "video_processor_janitor_reclaimed_bytes_total",
+	Help: "Bytes recuperados pela limpeza de órfãos e pela retenção por diretório (temp, uploads, outputs)",
+}, []string{"dir"})
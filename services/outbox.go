@@ -0,0 +1,401 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Subdiretório do outbox com as entradas abandonadas: destinos que recusaram o
+// evento de forma definitiva ou tentativas esgotadas (OUTBOX_MAX_ATTEMPTS)
+const outboxDeadDir = "dead"
+
+// Entrada movida para o dead-letter do outbox; não bloqueia as seguintes do job
+var errOutboxAbandoned = errors.New("entrada movida para o dead-letter do outbox")
+
+// Outbox de resultados: cada evento é gravado em disco antes do envio e só é
+// removido quando todos os destinos confirmam. O que falhar é reenviado pelo
+// relay em background, inclusive após reinícios (ver runOutboxRelay).
+type resultOutbox struct {
+	dir         string
+	maxAttempts int // 0 = sem limite
+
+	mu      sync.Mutex
+	sending map[string]bool   // Entradas em envio, para o relay não duplicá-las
+	jobs    map[string]string // ProcessID de cada entrada pendente (nil até a primeira leitura)
+}
+
+// Entrada do outbox, com o necessário para reenviar o evento fora do job
+type outboxEntry struct {
+	Result      VideoProcessingResult `json:"result"`
+	Body        json.RawMessage       `json:"body"`
+	Sequence    int64                 `json:"sequence"`
+	Attributes  map[string]string     `json:"attributes,omitempty"` // Rastreamento do job
+	CallbackURL string                `json:"callbackUrl,omitempty"`
+	ZipBucket   string                `json:"zipBucket,omitempty"` // Para renovar a downloadUrl (ver refreshDownloadURL)
+	Delivered   []string              `json:"delivered,omitempty"` // Destinos que já confirmaram
+	Abandoned   []string              `json:"abandoned,omitempty"` // Destinos que recusaram definitivamente
+	Attempts    int                   `json:"attempts"`
+	LastError   string                `json:"lastError,omitempty"`
+	CreatedAt   time.Time             `json:"createdAt"`
+}
+
+func newResultOutbox(dir string, maxAttempts int) *resultOutbox {
+	return &resultOutbox{dir: dir, maxAttempts: maxAttempts, sending: make(map[string]bool)}
+}
+
+// Outbox configurado (nil se OUTBOX_DIR estiver vazio)
+func (mp *MessageProcessor) outbox() *resultOutbox {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if mp.resultsOutbox == nil && mp.config.OutboxDir != "" {
+		mp.resultsOutbox = newResultOutbox(mp.config.OutboxDir, mp.config.OutboxMaxAttempts)
+	}
+	return mp.resultsOutbox
+}
+
+// Gravar a entrada de forma durável (fsync + rename) e marcá-la como em envio
+func (o *resultOutbox) record(entry outboxEntry) (string, error) {
+	if err := os.MkdirAll(o.dir, 0755); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%020d_%s.json", time.Now().UnixNano(), randomHex(4))
+	path := filepath.Join(o.dir, name)
+
+	o.mu.Lock()
+	o.sending[path] = true
+	o.mu.Unlock()
+
+	if err := o.write(path, entry); err != nil {
+		o.release(path)
+		return "", err
+	}
+	o.track(path, entry.Result.ProcessID)
+	return path, nil
+}
+
+// Índice das entradas pendentes por job, carregado do disco na primeira
+// chamada (entradas de execuções anteriores); deve ser chamado com o.mu travado
+func (o *resultOutbox) loadJobs() {
+	if o.jobs != nil {
+		return
+	}
+	o.jobs = make(map[string]string)
+	files, _ := filepath.Glob(filepath.Join(o.dir, "*.json"))
+	for _, path := range files {
+		var entry outboxEntry
+		if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &entry) == nil {
+			o.jobs[path] = entry.Result.ProcessID
+		}
+	}
+}
+
+func (o *resultOutbox) track(path, processID string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.loadJobs()
+	o.jobs[path] = processID
+}
+
+func (o *resultOutbox) untrack(path string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.jobs, path)
+}
+
+// Há entrada do job anterior a path ainda pendente? Os nomes das entradas são
+// ordenados pela criação
+func (o *resultOutbox) pendingBefore(path, processID string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.loadJobs()
+	for other, id := range o.jobs {
+		if id == processID && other < path {
+			return true
+		}
+	}
+	return false
+}
+
+// Remover a entrada confirmada
+func (o *resultOutbox) remove(ctx context.Context, path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		logf(ctx, "⚠️ Erro ao remover entrada do outbox: %v", err)
+	}
+	o.untrack(path)
+}
+
+// Mover a entrada para o dead-letter, onde fica para inspeção e reenvio manual
+func (o *resultOutbox) abandon(ctx context.Context, path string, entry outboxEntry) {
+	dead := filepath.Join(o.dir, outboxDeadDir)
+	err := os.MkdirAll(dead, 0755)
+	if err == nil {
+		err = o.write(path, entry)
+	}
+	if err == nil {
+		err = os.Rename(path, filepath.Join(dead, filepath.Base(path)))
+	}
+	if err != nil {
+		logf(ctx, "❌ Erro ao mover entrada para o dead-letter do outbox: %v", err)
+		return
+	}
+	o.untrack(path)
+	outboxRelayedTotal.WithLabelValues("dead").Inc()
+}
+
+func (o *resultOutbox) write(path string, entry outboxEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(o.dir, ".entry-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Reservar uma entrada para envio; false se já estiver sendo enviada
+func (o *resultOutbox) claim(path string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.sending[path] {
+		return false
+	}
+	o.sending[path] = true
+	return true
+}
+
+func (o *resultOutbox) release(path string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.sending, path)
+}
+
+// Entradas pendentes, da mais antiga para a mais recente
+func (o *resultOutbox) pending() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(o.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Contexto do job reconstruído a partir da entrada, para o reenvio
+func (e outboxEntry) context(ctx context.Context) context.Context {
+	if len(e.Attributes) > 0 {
+		ctx = withTrace(ctx, traceFromAttributes(e.Attributes))
+	}
+	if e.CallbackURL != "" {
+		ctx = withCallbackURL(ctx, e.CallbackURL)
+	}
+	return ctx
+}
+
+// Enviar a entrada aos destinos que ainda não confirmaram. Sem erro, a entrada
+// é removida; caso contrário é regravada com os destinos já confirmados. Destinos
+// que recusam o evento definitivamente (ex: 4xx do webhook) não são repetidos, e
+// a entrada vai para o dead-letter quando só restam eles ou quando as tentativas
+// se esgotam (errOutboxAbandoned). Deve ser chamado com a entrada reservada
+// (record ou claim).
+func (mp *MessageProcessor) deliverOutboxEntry(ctx context.Context, outbox *resultOutbox, path string, entry outboxEntry) error {
+	defer outbox.release(path)
+
+	event := ResultEvent{Result: entry.Result, Body: entry.Body, Sequence: entry.Sequence}
+	skip := append(slices.Clone(entry.Delivered), entry.Abandoned...)
+	results := publishPending(ctx, mp.resultPublisher(), event, skip)
+	entry.Attempts++
+
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(results)) {
+		switch err := results[name]; {
+		case err == nil:
+			entry.Delivered = append(entry.Delivered, name)
+		case isPermanentPublishError(err):
+			entry.Abandoned = append(entry.Abandoned, name)
+			entry.LastError = err.Error()
+		default:
+			errs = append(errs, err)
+		}
+	}
+	err := errors.Join(errs...)
+	if err != nil {
+		entry.LastError = err.Error()
+	}
+
+	switch {
+	case err == nil && len(entry.Abandoned) == 0:
+		outbox.remove(ctx, path)
+		return nil
+	case err == nil:
+		logf(ctx, "☠️ Resultado %s recusado definitivamente por %v, movido para o dead-letter do outbox", entry.Result.Status, entry.Abandoned)
+		mp.storeAbandonedWebhook(ctx, entry, results[ResultSinkWebhook])
+		outbox.abandon(ctx, path, entry)
+		return fmt.Errorf("%w: %s", errOutboxAbandoned, entry.LastError)
+	case outbox.maxAttempts > 0 && entry.Attempts >= outbox.maxAttempts:
+		logf(ctx, "☠️ Resultado %s não entregue após %d tentativa(s), movido para o dead-letter do outbox", entry.Result.Status, entry.Attempts)
+		mp.storeAbandonedWebhook(ctx, entry, results[ResultSinkWebhook])
+		outbox.abandon(ctx, path, entry)
+		return fmt.Errorf("%w: %v", errOutboxAbandoned, err)
+	}
+
+	if writeErr := outbox.write(path, entry); writeErr != nil {
+		logf(ctx, "❌ Erro ao atualizar entrada do outbox: %v", writeErr)
+	}
+	return err
+}
+
+// Se o webhook ficou sem entrega, guardar o callback em WEBHOOK_FAILED_DIR para
+// que ReplayWebhooks também alcance os eventos do dead-letter
+func (mp *MessageProcessor) storeAbandonedWebhook(ctx context.Context, entry outboxEntry, webhookErr error) {
+	if webhookErr == nil && !slices.Contains(entry.Abandoned, ResultSinkWebhook) {
+		return
+	}
+	callbackURL := entry.CallbackURL
+	if callbackURL == "" {
+		callbackURL = mp.config.WebhookURL
+	}
+	lastError := entry.LastError
+	if webhookErr != nil {
+		lastError = webhookErr.Error()
+	}
+	mp.webhooks().store(entry.context(ctx), callbackURL, entry.Body, entry.Attempts, lastError)
+}
+
+// A downloadUrl gravada com o evento pode ter expirado até o reenvio: gerar
+// uma nova a partir do bucket e da chave do ZIP ou, se não for possível,
+// removê-la do evento (o destino pode pedir outra em /api/status)
+func (mp *MessageProcessor) refreshDownloadURL(ctx context.Context, entry *outboxEntry) {
+	if entry.Result.DownloadURL == "" {
+		return
+	}
+	url, expiresAt, err := "", time.Time{}, ErrPresignUnavailable
+	if entry.ZipBucket != "" {
+		url, expiresAt, err = mp.PresignDownload(ctx, entry.ZipBucket, entry.Result.ZipKey)
+	}
+	if err != nil {
+		logf(ctx, "⚠️ URL de download do resultado não renovada, enviando sem ela: %v", err)
+		entry.Result.DownloadURL, entry.Result.DownloadURLExpiresAt = "", ""
+	} else {
+		entry.Result.DownloadURL, entry.Result.DownloadURLExpiresAt = url, expiresAt.Format(time.RFC3339)
+	}
+	if body, err := json.Marshal(entry.Result); err == nil {
+		entry.Body = body
+	}
+}
+
+// Enviar aos destinos fora de skip; retorna o resultado de cada destino tentado
+func publishPending(ctx context.Context, publisher ResultPublisher, event ResultEvent, skip []string) map[string]error {
+	if publisher == nil {
+		return nil
+	}
+	if composite, ok := publisher.(*compositeResultPublisher); ok {
+		return composite.publishTo(ctx, event, skip)
+	}
+	if slices.Contains(skip, publisher.Name()) {
+		return nil
+	}
+	return map[string]error{publisher.Name(): publisher.PublishResult(ctx, event)}
+}
+
+// Relay do outbox: reenvia as entradas pendentes ao iniciar e depois a cada
+// OUTBOX_RELAY_INTERVAL, até que sejam confirmadas
+func (mp *MessageProcessor) runOutboxRelay(ctx context.Context, outbox *resultOutbox) {
+	interval := mp.config.OutboxRelayInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		mp.relayOutbox(ctx, outbox)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Uma passada pelas entradas pendentes. Se uma entrada de um job falhar, as
+// seguintes do mesmo job ficam para a próxima passada, preservando a ordem;
+// entradas movidas para o dead-letter não bloqueiam as seguintes
+func (mp *MessageProcessor) relayOutbox(ctx context.Context, outbox *resultOutbox) (relayed, failed int) {
+	files, err := outbox.pending()
+	if err != nil {
+		log.Printf("❌ Erro ao listar outbox: %v", err)
+		return 0, 0
+	}
+	defer func() {
+		if remaining, err := outbox.pending(); err == nil {
+			outboxPending.Set(float64(len(remaining)))
+		}
+	}()
+	if len(files) == 0 {
+		return 0, 0
+	}
+
+	blocked := make(map[string]bool)
+	for _, path := range files {
+		if ctx.Err() != nil {
+			break
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var entry outboxEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			log.Printf("⚠️ Entrada inválida no outbox ignorada: %s", path)
+			continue
+		}
+		if blocked[entry.Result.ProcessID] || !outbox.claim(path) {
+			blocked[entry.Result.ProcessID] = true
+			continue
+		}
+
+		jobCtx := entry.context(ctx)
+		mp.refreshDownloadURL(jobCtx, &entry)
+		if err := mp.deliverOutboxEntry(jobCtx, outbox, path, entry); errors.Is(err, errOutboxAbandoned) {
+			failed++
+			continue
+		} else if err != nil {
+			failed++
+			blocked[entry.Result.ProcessID] = true
+			outboxRelayedTotal.WithLabelValues("failed").Inc()
+			logf(jobCtx, "📮 Reenvio do resultado %s falhou (tentativa %d): %v", entry.Result.Status, entry.Attempts+1, err)
+			continue
+		}
+		relayed++
+		outboxRelayedTotal.WithLabelValues("relayed").Inc()
+		logf(jobCtx, "📮 Resultado %s reenviado do outbox", entry.Result.Status)
+	}
+
+	if relayed > 0 || failed > 0 {
+		log.Printf("📮 Outbox: %d reenviado(s), %d com falha", relayed, failed)
+	}
+	return relayed, failed
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestOutboxProcessor(t *testing.T, sinks ...ResultPublisher) (*MessageProcessor, string) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	mp := &MessageProcessor{config: MessageProcessorConfig{OutboxDir: dir, WebhookFailedDir: filepath.Join(t.TempDir(), "failed")}}
+	mp.publisher = newCompositeResultPublisher(sinks...)
+	return mp, dir
+}
+
+func outboxFiles(t *testing.T, dir string) []string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	return files
+}
+
+func TestSendProcessingResult_OutboxRemoveAposConfirmacao(t *testing.T) {
+	sink := &recordingSink{name: "queue"}
+	mp, dir := newTestOutboxProcessor(t, sink)
+
+	if err := mp.SendProcessingResult(context.Background(), "proc-1", "zip", "COMPLETED"); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(sink.events) != 1 {
+		t.Errorf("Esperado 1 evento entregue, obtido %d", len(sink.events))
+	}
+	if files := outboxFiles(t, dir); len(files) != 0 {
+		t.Errorf("Esperado outbox vazio, obtido %d entrada(s)", len(files))
+	}
+}
+
+func TestSendProcessingResult_OutboxReenviaSomenteDestinosPendentes(t *testing.T) {
+	ok := &recordingSink{name: "queue"}
+	failing := &recordingSink{name: "webhook", err: errors.New("indisponível")}
+	mp, dir := newTestOutboxProcessor(t, ok, failing)
+	ctx := withCallbackURL(withTrace(context.Background(), traceFromAttributes(map[string]string{CorrelationIDAttribute: "corr-1"})), "https://hooks.exemplo.com")
+
+	if err := mp.SendProcessingResult(ctx, "proc-1", "zip", "COMPLETED"); err != nil {
+		t.Fatalf("Esperado resultado mantido no outbox sem erro, obtido %v", err)
+	}
+	if files := outboxFiles(t, dir); len(files) != 1 {
+		t.Fatalf("Esperado 1 entrada pendente, obtido %d", len(files))
+	}
+
+	failing.err = nil
+	relayed, failed := mp.relayOutbox(context.Background(), mp.outbox())
+	if relayed != 1 || failed != 0 {
+		t.Errorf("Esperado 1 reenvio e 0 falhas, obtido %d/%d", relayed, failed)
+	}
+	if len(ok.events) != 1 {
+		t.Errorf("Esperado destino já confirmado sem reenvio, obtido %d evento(s)", len(ok.events))
+	}
+	if len(failing.events) != 2 {
+		t.Errorf("Esperado 2 tentativas no destino com falha, obtido %d", len(failing.events))
+	}
+	if files := outboxFiles(t, dir); len(files) != 0 {
+		t.Errorf("Esperado outbox vazio após reenvio, obtido %d entrada(s)", len(files))
+	}
+}
+
+func TestRelayOutbox_PreservaOrdemDoJob(t *testing.T) {
+	sink := &recordingSink{name: "queue", err: errors.New("indisponível")}
+	mp, dir := newTestOutboxProcessor(t, sink)
+	mp.SendProcessingResult(context.Background(), "proc-1", "", "IN_PROGRESS")
+	mp.SendProcessingResult(context.Background(), "proc-1", "zip", "COMPLETED")
+	sink.events = nil
+
+	relayed, failed := mp.relayOutbox(context.Background(), mp.outbox())
+	if relayed != 0 || failed != 1 {
+		t.Errorf("Esperado só a primeira entrada tentada, obtido %d/%d", relayed, failed)
+	}
+	if len(sink.events) != 1 || sink.events[0].Result.Status != "IN_PROGRESS" {
+		t.Errorf("Esperado apenas IN_PROGRESS reenviado, obtido %+v", sink.events)
+	}
+
+	sink.err = nil
+	sink.events = nil
+	mp.relayOutbox(context.Background(), mp.outbox())
+	if len(sink.events) != 2 || sink.events[0].Result.Status != "IN_PROGRESS" || sink.events[1].Result.Status != "COMPLETED" {
+		t.Errorf("Esperado IN_PROGRESS e COMPLETED em ordem, obtido %+v", sink.events)
+	}
+	if files := outboxFiles(t, dir); len(files) != 0 {
+		t.Errorf("Esperado outbox vazio, obtido %d entrada(s)", len(files))
+	}
+}
+
+func TestRelayOutbox_EntradaDeExecucaoAnterior(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	previous := newResultOutbox(dir, 0)
+	path, err := previous.record(outboxEntry{
+		Result:      VideoProcessingResult{ProcessID: "proc-1", Status: "COMPLETED"},
+		Body:        []byte(`{"status":"COMPLETED"}`),
+		CallbackURL: "https://hooks.exemplo.com",
+	})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Esperado entrada gravada em disco: %v", err)
+	}
+
+	sink := &recordingSink{name: "queue"}
+	mp := &MessageProcessor{config: MessageProcessorConfig{OutboxDir: dir}}
+	mp.publisher = newCompositeResultPublisher(sink)
+	if relayed, _ := mp.relayOutbox(context.Background(), mp.outbox()); relayed != 1 {
+		t.Errorf("Esperado 1 reenvio na inicialização, obtido %d", relayed)
+	}
+	if len(sink.events) != 1 || string(sink.events[0].Body) != `{"status":"COMPLETED"}` {
+		t.Errorf("Esperado evento original reenviado, obtido %+v", sink.events)
+	}
+}
+
+func deadFiles(dir string) []string {
+	files, _ := filepath.Glob(filepath.Join(dir, outboxDeadDir, "*.json"))
+	return files
+}
+
+func TestRelayOutbox_FalhaDefinitivaVaiParaDeadLetter(t *testing.T) {
+	ok := &recordingSink{name: "queue"}
+	rejecting := &recordingSink{name: "webhook", err: &permanentWebhookError{err: errors.New("status 400")}}
+	mp, dir := newTestOutboxProcessor(t, ok, rejecting)
+
+	mp.SendProcessingResult(context.Background(), "proc-1", "", "IN_PROGRESS")
+	if files := outboxFiles(t, dir); len(files) != 0 {
+		t.Errorf("Esperado entrada fora da fila de reenvio, obtido %d pendente(s)", len(files))
+	}
+	if files := deadFiles(dir); len(files) != 1 {
+		t.Fatalf("Esperado entrada no dead-letter, obtido %d", len(files))
+	}
+
+	// A entrada abandonada não bloqueia os eventos seguintes do job
+	mp.SendProcessingResult(context.Background(), "proc-1", "zip", "COMPLETED")
+	if len(ok.events) != 2 || ok.events[1].Result.Status != "COMPLETED" {
+		t.Errorf("Esperado COMPLETED entregue após o dead-letter, obtido %+v", ok.events)
+	}
+	mp.relayOutbox(context.Background(), mp.outbox())
+	if len(rejecting.events) != 2 {
+		t.Errorf("Esperado uma única tentativa por evento no destino que recusou, obtido %d", len(rejecting.events))
+	}
+}
+
+func TestRelayOutbox_WebhookDoDeadLetterGuardadoParaReenvio(t *testing.T) {
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+	}))
+	defer server.Close()
+
+	failing := &recordingSink{name: ResultSinkWebhook, err: errors.New("indisponível")}
+	mp, dir := newTestOutboxProcessor(t, failing)
+	mp.config.OutboxMaxAttempts = 1
+	ctx := withCallbackURL(context.Background(), server.URL)
+
+	mp.SendProcessingResult(ctx, "proc-1", "", "IN_PROGRESS")
+	if files := deadFiles(dir); len(files) != 1 {
+		t.Fatalf("Esperado entrada no dead-letter, obtido %d", len(files))
+	}
+
+	delivered, failed, err := mp.ReplayWebhooks(context.Background())
+	if err != nil || delivered != 1 || failed != 0 {
+		t.Errorf("Esperado 1 webhook reenviado do dead-letter, obtido %d/%d (%v)", delivered, failed, err)
+	}
+	if received.Load() != 1 {
+		t.Errorf("Esperado callback recebido no reenvio, obtido %d", received.Load())
+	}
+}
+
+func TestRelayOutbox_TentativasEsgotadas(t *testing.T) {
+	sink := &recordingSink{name: "queue", err: errors.New("indisponível")}
+	mp, dir := newTestOutboxProcessor(t, sink)
+	mp.config.OutboxMaxAttempts = 2
+
+	mp.SendProcessingResult(context.Background(), "proc-1", "", "IN_PROGRESS")
+	if _, failed := mp.relayOutbox(context.Background(), mp.outbox()); failed != 1 {
+		t.Errorf("Esperado 1 falha no reenvio, obtido %d", failed)
+	}
+	if files := outboxFiles(t, dir); len(files) != 0 {
+		t.Errorf("Esperado entrada fora da fila após %d tentativas, obtido %d pendente(s)", 2, len(files))
+	}
+	if files := deadFiles(dir); len(files) != 1 {
+		t.Errorf("Esperado entrada no dead-letter, obtido %d", len(files))
+	}
+	mp.relayOutbox(context.Background(), mp.outbox())
+	if len(sink.events) != 2 {
+		t.Errorf("Esperado 2 tentativas no total, obtido %d", len(sink.events))
+	}
+}
+
+func TestSendProcessingResult_AguardaEventoAnteriorPendente(t *testing.T) {
+	sink := &recordingSink{name: "queue", err: errors.New("indisponível")}
+	mp, dir := newTestOutboxProcessor(t, sink)
+
+	mp.SendProcessingResult(context.Background(), "proc-1", "", "IN_PROGRESS")
+	sink.err = nil
+	mp.SendProcessingResult(context.Background(), "proc-1", "zip", "COMPLETED")
+	mp.SendProcessingResult(context.Background(), "proc-2", "", "IN_PROGRESS")
+
+	if len(sink.events) != 2 || sink.events[1].Result.ProcessID != "proc-2" {
+		t.Fatalf("Esperado COMPLETED de proc-1 retido e proc-2 entregue, obtido %+v", sink.events)
+	}
+	if files := outboxFiles(t, dir); len(files) != 2 {
+		t.Errorf("Esperado 2 entradas pendentes de proc-1, obtido %d", len(files))
+	}
+
+	sink.events = nil
+	mp.relayOutbox(context.Background(), mp.outbox())
+	if len(sink.events) != 2 || sink.events[0].Result.Status != "IN_PROGRESS" || sink.events[1].Result.Status != "COMPLETED" {
+		t.Errorf("Esperado IN_PROGRESS e COMPLETED em ordem, obtido %+v", sink.events)
+	}
+}
+
+func TestRelayOutbox_RenovaURLDeDownload(t *testing.T) {
+	sink := &recordingSink{name: "queue", err: errors.New("indisponível")}
+	mp, _ := newTestOutboxProcessor(t, sink)
+	mp.config.PresignTTL = time.Hour
+	mp.presigner = newS3Presigner(newLocalStackS3Client(), "")
+
+	expired := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	mp.publishResult(context.Background(), VideoProcessingResult{
+		ProcessID: "proc-1", ZipKey: "processed/proc-1.zip", Status: "COMPLETED",
+		DownloadURL: "https://expirada", DownloadURLExpiresAt: expired, zipBucket: "video-results",
+	})
+	// Entrada sem o bucket (gravada por uma versão anterior): a URL é removida
+	mp.publishResult(context.Background(), VideoProcessingResult{
+		ProcessID: "proc-2", ZipKey: "processed/proc-2.zip", Status: "COMPLETED",
+		DownloadURL: "https://expirada", DownloadURLExpiresAt: expired,
+	})
+
+	sink.err, sink.events = nil, nil
+	mp.relayOutbox(context.Background(), mp.outbox())
+	if len(sink.events) != 2 {
+		t.Fatalf("Esperado 2 eventos reenviados, obtido %d", len(sink.events))
+	}
+	renewed := sink.events[0]
+	if !strings.Contains(renewed.Result.DownloadURL, "/video-results/processed/proc-1.zip") || renewed.Result.DownloadURLExpiresAt == expired {
+		t.Errorf("Esperado URL renovada, obtido %s (%s)", renewed.Result.DownloadURL, renewed.Result.DownloadURLExpiresAt)
+	}
+	if !strings.Contains(string(renewed.Body), renewed.Result.DownloadURLExpiresAt) {
+		t.Errorf("Esperado corpo com a nova expiração, obtido %s", renewed.Body)
+	}
+	if dropped := sink.events[1]; dropped.Result.DownloadURL != "" || strings.Contains(string(dropped.Body), "downloadUrl") {
+		t.Errorf("Esperado evento sem URL de download, obtido %s", dropped.Body)
+	}
+}
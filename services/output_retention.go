@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"video-processor/utils"
+)
+
+// ZIPs gerados por POST /upload: frames_<jobID>.zip (ver ProcessVideoContext)
+var outputJobIDRe = regexp.MustCompile(`^frames_(\d{8}_\d{6}_[0-9a-f]{8})\.zip$`)
+
+// Motivos de remoção de um ZIP de OUTPUTS_DIR
+const (
+	EvictionAge   = "age"
+	EvictionBytes = "bytes"
+	EvictionFiles = "files"
+)
+
+// Retenção dos ZIPs servidos por HTTP a partir de OUTPUTS_DIR. Os limites de
+// espaço e quantidade removem primeiro os ZIPs baixados há mais tempo
+// (os nunca baixados contam a partir da criação). 0 = sem limite
+type OutputRetentionConfig struct {
+	Interval time.Duration // Intervalo entre as passadas (0 = desabilitada)
+	MaxAge   time.Duration // Idade máxima desde a criação
+	MaxBytes int64         // Tamanho total máximo
+	MaxFiles int           // Quantidade máxima de ZIPs
+}
+
+// Enabled indica se a retenção roda: precisa de um intervalo e de ao menos um limite
+func (c OutputRetentionConfig) Enabled() bool {
+	return c.Interval > 0 && (c.MaxAge > 0 || c.MaxBytes > 0 || c.MaxFiles > 0)
+}
+
+// LoadOutputRetentionConfig lê OUTPUT_RETENTION_INTERVAL, OUTPUT_MAX_AGE,
+// OUTPUT_MAX_BYTES e OUTPUT_MAX_FILES. Sem nenhum limite (o padrão), nenhum ZIP
+// é removido: a retenção precisa ser habilitada explicitamente
+func LoadOutputRetentionConfig() OutputRetentionConfig {
+	return OutputRetentionConfig{
+		Interval: utils.GetEnvDuration("OUTPUT_RETENTION_INTERVAL", 5*time.Minute),
+		MaxAge:   utils.GetEnvDuration("OUTPUT_MAX_AGE", 0),
+		MaxBytes: int64(utils.GetEnvInt("OUTPUT_MAX_BYTES", 0)),
+		MaxFiles: utils.GetEnvInt("OUTPUT_MAX_FILES", 0),
+	}
+}
+
+// Estatísticas da retenção, expostas em /api/status
+type OutputRetentionStats struct {
+	Config       OutputRetentionConfig
+	Runs         int
+	LastRunAt    *time.Time
+	Files        int // ZIPs mantidos na última passada
+	Bytes        int64
+	EvictedFiles int // Total removido desde o início, por motivo em ByReason
+	EvictedBytes int64
+	ByReason     map[string]int
+}
+
+// Último download de cada ZIP e estatísticas acumuladas. Em memória: após um
+// restart, todos os ZIPs voltam a contar a partir da criação
+var (
+	retentionMu    sync.Mutex
+	lastDownloads  = make(map[string]time.Time)
+	retentionStats = OutputRetentionStats{ByReason: map[string]int{}}
+)
+
+// TouchOutput registra o download de um ZIP de OUTPUTS_DIR
+func TouchOutput(name string) {
+	retentionMu.Lock()
+	defer retentionMu.Unlock()
+	lastDownloads[filepath.Base(name)] = time.Now()
+}
+
+// OutputRetentionStatus retorna uma cópia das estatísticas da retenção
+func OutputRetentionStatus() OutputRetentionStats {
+	retentionMu.Lock()
+	defer retentionMu.Unlock()
+	stats := retentionStats
+	stats.ByReason = make(map[string]int, len(retentionStats.ByReason))
+	for reason, count := range retentionStats.ByReason {
+		stats.ByReason[reason] = count
+	}
+	return stats
+}
+
+// LastOutputDownload retorna quando o ZIP foi baixado pela última vez
+func LastOutputDownload(name string) (time.Time, bool) {
+	retentionMu.Lock()
+	defer retentionMu.Unlock()
+	at, ok := lastDownloads[filepath.Base(name)]
+	return at, ok
+}
+
+// StartOutputRetention aplica a retenção imediatamente e depois a cada
+// Interval, até o ctx ser cancelado. Sem Interval ou sem nenhum limite, não faz nada
+func StartOutputRetention(ctx context.Context, config OutputRetentionConfig) {
+	retentionMu.Lock()
+	retentionStats.Config = config
+	retentionMu.Unlock()
+	if !config.Enabled() {
+		log.Printf("🗑️ Retenção de outputs desabilitada (defina OUTPUT_MAX_AGE, OUTPUT_MAX_BYTES ou OUTPUT_MAX_FILES)")
+		return
+	}
+
+	enforceOutputRetention(config)
+	go func() {
+		ticker := time.NewTicker(config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				enforceOutputRetention(config)
+			}
+		}
+	}()
+}
+
+// ZIP de OUTPUTS_DIR candidato à remoção
+type outputFile struct {
+	path     string
+	size     int64
+	created  time.Time
+	lastUsed time.Time
+}
+
+// Uma passada por OUTPUTS_DIR: remove os ZIPs mais antigos que MaxAge e, enquanto
+// o total passar de MaxBytes ou MaxFiles, os baixados há mais tempo. ZIPs de jobs
+// em andamento nunca são removidos. Retorna os itens e bytes removidos
+func enforceOutputRetention(config OutputRetentionConfig) (removed int, reclaimed int64) {
+	dir := Scratch().Outputs
+	entries, _ := os.ReadDir(dir)
+	now := time.Now()
+
+	retentionMu.Lock()
+	var files []outputFile
+	present := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".zip") {
+			continue
+		}
+		present[entry.Name()] = true
+		if match := outputJobIDRe.FindStringSubmatch(entry.Name()); match != nil && isJobActive(match[1]) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		file := outputFile{path: filepath.Join(dir, entry.Name()), size: info.Size(), created: info.ModTime(), lastUsed: info.ModTime()}
+		if at, ok := lastDownloads[entry.Name()]; ok && at.After(file.lastUsed) {
+			file.lastUsed = at
+		}
+		files = append(files, file)
+	}
+	// Esquecer downloads de ZIPs que não existem mais
+	for name := range lastDownloads {
+		if !present[name] {
+			delete(lastDownloads, name)
+		}
+	}
+	retentionMu.Unlock()
+
+	// Baixados há mais tempo primeiro
+	slices.SortFunc(files, func(a, b outputFile) int {
+		return a.lastUsed.Compare(b.lastUsed)
+	})
+
+	var total int64
+	for _, file := range files {
+		total += file.size
+	}
+	count := len(files)
+	byReason := map[string]int{}
+
+	evict := func(file outputFile, reason string) bool {
+		if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+			log.Printf("⚠️ Erro ao remover ZIP %s: %v", file.path, err)
+			return false
+		}
+		removed++
+		reclaimed += file.size
+		total -= file.size
+		count--
+		byReason[reason]++
+		janitorReclaimedTotal.WithLabelValues("outputs").Inc()
+		janitorReclaimedBytes.WithLabelValues("outputs").Add(float64(file.size))
+		return true
+	}
+
+	kept := files[:0]
+	for _, file := range files {
+		if config.MaxAge > 0 && now.Sub(file.created) > config.MaxAge && evict(file, EvictionAge) {
+			continue
+		}
+		kept = append(kept, file)
+	}
+	for _, file := range kept {
+		switch {
+		case config.MaxFiles > 0 && count > config.MaxFiles:
+			evict(file, EvictionFiles)
+		case config.MaxBytes > 0 && total > config.MaxBytes:
+			evict(file, EvictionBytes)
+		}
+	}
+
+	retentionMu.Lock()
+	retentionStats.Runs++
+	retentionStats.LastRunAt = &now
+	retentionStats.Files = count
+	retentionStats.Bytes = total
+	retentionStats.EvictedFiles += removed
+	retentionStats.EvictedBytes += reclaimed
+	for reason, n := range byReason {
+		retentionStats.ByReason[reason] += n
+	}
+	retentionMu.Unlock()
+
+	if removed > 0 {
+		log.Printf("🗑️ Retenção de outputs: %d ZIP(s) removido(s), %s liberados; restam %d (%s)",
+			removed, formatBytes(uint64(reclaimed)), count, formatBytes(uint64(total)))
+	}
+	return removed, reclaimed
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Criar um ZIP em OUTPUTS_DIR com o tamanho e a idade indicados
+func writeOutput(t *testing.T, dirs ScratchDirs, name string, size int, d time.Duration) string {
+	t.Helper()
+	os.MkdirAll(dirs.Outputs, 0755)
+	path := filepath.Join(dirs.Outputs, name)
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatalf("Erro ao criar ZIP de teste: %v", err)
+	}
+	age(path, d)
+	return path
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestLoadOutputRetentionConfig_Padrao(t *testing.T) {
+	config := LoadOutputRetentionConfig()
+	if config.Interval != 5*time.Minute {
+		t.Errorf("Esperado intervalo 5m, obtido %v", config.Interval)
+	}
+	if config.MaxAge != 0 || config.MaxBytes != 0 || config.MaxFiles != 0 {
+		t.Errorf("Esperado sem limites por padrão, obtido %v, %d e %d", config.MaxAge, config.MaxBytes, config.MaxFiles)
+	}
+	if config.Enabled() {
+		t.Error("Retenção não deveria rodar sem ser habilitada")
+	}
+	t.Setenv("OUTPUT_MAX_AGE", "24h")
+	if !LoadOutputRetentionConfig().Enabled() {
+		t.Error("Esperado retenção habilitada com OUTPUT_MAX_AGE")
+	}
+}
+
+func TestEnforceOutputRetention_IdadeMaxima(t *testing.T) {
+	dirs := useScratchDirs(t)
+	old := writeOutput(t, dirs, "antigo.zip", 10, 2*time.Hour)
+	recent := writeOutput(t, dirs, "recente.zip", 10, time.Minute)
+	other := writeOutput(t, dirs, "notas.txt", 10, 2*time.Hour)
+
+	removed, reclaimed := enforceOutputRetention(OutputRetentionConfig{MaxAge: time.Hour})
+	if removed != 1 || reclaimed != 10 {
+		t.Errorf("Esperado 1 ZIP e 10 bytes removidos, obtido %d e %d", removed, reclaimed)
+	}
+	if exists(old) || !exists(recent) || !exists(other) {
+		t.Error("Esperado apenas o ZIP antigo removido")
+	}
+}
+
+func TestEnforceOutputRetention_QuantidadeRemoveMenosBaixados(t *testing.T) {
+	dirs := useScratchDirs(t)
+	downloaded := writeOutput(t, dirs, "baixado.zip", 10, 3*time.Hour)
+	idle := writeOutput(t, dirs, "parado.zip", 10, 2*time.Hour)
+	recent := writeOutput(t, dirs, "recente.zip", 10, time.Hour)
+	TouchOutput("baixado.zip")
+
+	if removed, _ := enforceOutputRetention(OutputRetentionConfig{MaxFiles: 2}); removed != 1 {
+		t.Errorf("Esperado 1 ZIP removido, obtido %d", removed)
+	}
+	if exists(idle) {
+		t.Error("Esperado removido o ZIP baixado há mais tempo")
+	}
+	if !exists(downloaded) || !exists(recent) {
+		t.Error("ZIP baixado recentemente não deveria ser removido")
+	}
+}
+
+func TestEnforceOutputRetention_EspacoMaximo(t *testing.T) {
+	dirs := useScratchDirs(t)
+	first := writeOutput(t, dirs, "primeiro.zip", 100, 3*time.Hour)
+	second := writeOutput(t, dirs, "segundo.zip", 100, 2*time.Hour)
+	third := writeOutput(t, dirs, "terceiro.zip", 100, time.Hour)
+
+	before := OutputRetentionStatus()
+	removed, reclaimed := enforceOutputRetention(OutputRetentionConfig{MaxBytes: 150})
+	if removed != 2 || reclaimed != 200 {
+		t.Errorf("Esperado 2 ZIPs e 200 bytes removidos, obtido %d e %d", removed, reclaimed)
+	}
+	if exists(first) || exists(second) || !exists(third) {
+		t.Error("Esperado mantido apenas o ZIP mais recente")
+	}
+
+	stats := OutputRetentionStatus()
+	if stats.Files != 1 || stats.Bytes != 100 {
+		t.Errorf("Esperado 1 ZIP com 100 bytes mantido, obtido %d e %d", stats.Files, stats.Bytes)
+	}
+	if got := stats.ByReason[EvictionBytes] - before.ByReason[EvictionBytes]; got != 2 {
+		t.Errorf("Esperado 2 remoções por espaço, obtido %d", got)
+	}
+	if stats.Runs != before.Runs+1 || stats.LastRunAt == nil {
+		t.Error("Esperado passada registrada nas estatísticas")
+	}
+}
+
+func TestEnforceOutputRetention_JobEmAndamentoPreservado(t *testing.T) {
+	dirs := useScratchDirs(t)
+	ws, err := NewWorkspace(NewJobID())
+	if err != nil {
+		t.Fatalf("Erro ao criar workspace: %v", err)
+	}
+	defer ws.Cleanup()
+	zip := writeOutput(t, dirs, "frames_"+ws.JobID+".zip", 10, 2*time.Hour)
+
+	if removed, _ := enforceOutputRetention(OutputRetentionConfig{MaxAge: time.Hour}); removed != 0 {
+		t.Errorf("Esperado ZIP em gravação preservado, obtido %d removido(s)", removed)
+	}
+	if !exists(zip) {
+		t.Error("ZIP de job em andamento não deveria ser removido")
+	}
+}
+
+func TestEnforceOutputRetention_EsqueceDownloadsRemovidos(t *testing.T) {
+	useScratchDirs(t)
+	TouchOutput("inexistente.zip")
+
+	enforceOutputRetention(OutputRetentionConfig{MaxFiles: 1})
+	if _, ok := LastOutputDownload("inexistente.zip"); ok {
+		t.Error("Esperado download de ZIP inexistente descartado")
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Validade máxima de uma URL pré-assinada com SigV4
+const maxPresignTTL = 7 * 24 * time.Hour
+
+// ErrPresignUnavailable indica que URLs pré-assinadas estão desabilitadas
+// (PRESIGN_URL_TTL=0) ou não se aplicam ao backend (BLOB_BACKEND=dir)
+var ErrPresignUnavailable = errors.New("URL pré-assinada indisponível")
+
+// Interface do presign client do S3, para facilitar mocks nos testes
+type S3Presigner interface {
+	PresignGetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
+	PresignPutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
+	PresignUploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
+}
+
+// Validar a validade configurada das URLs
+func (c MessageProcessorConfig) validatePresign() error {
+	if c.PresignTTL < 0 || c.PresignTTL > maxPresignTTL {
+		return fmt.Errorf("PRESIGN_URL_TTL deve estar entre 0 e %s: %s", maxPresignTTL, c.PresignTTL)
+	}
+	return nil
+}
+
+// Presign client a partir do client S3 (com o endpoint do LocalStack, se
+// houver). PRESIGN_ENDPOINT troca o endpoint das URLs geradas, para quando o
+// endereço usado pelo worker não é acessível pelos clientes (ex: rede do Docker)
+func newS3Presigner(client *s3.Client, endpoint string) S3Presigner {
+	return s3.NewPresignClient(client, func(o *s3.PresignOptions) {
+		if endpoint != "" {
+			o.ClientOptions = append(o.ClientOptions, func(so *s3.Options) {
+				so.BaseEndpoint = aws.String(endpoint)
+				so.UsePathStyle = true
+			})
+		}
+	})
+}
+
+// PresignDownload gera uma URL GET temporária para o objeto e retorna quando
+// ela expira. Retorna ErrPresignUnavailable se o recurso não estiver disponível
+func (mp *MessageProcessor) PresignDownload(ctx context.Context, bucket, key string) (string, time.Time, error) {
+	if mp.presigner == nil || mp.config.PresignTTL <= 0 || strings.ToLower(mp.config.BlobBackend) == BlobBackendDir {
+		return "", time.Time{}, ErrPresignUnavailable
+	}
+
+	expiresAt := time.Now().Add(mp.config.PresignTTL).UTC()
+	req, err := mp.presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(mp.config.PresignTTL))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("erro ao gerar URL pré-assinada: %w", err)
+	}
+	return req.URL, expiresAt, nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Client S3 apontando para o LocalStack, com credenciais fixas (o presign não acessa a rede)
+func newLocalStackS3Client() *s3.Client {
+	return s3.New(s3.Options{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "test", SecretAccessKey: "test"}, nil
+		}),
+		BaseEndpoint: aws.String("http://localhost:4566"),
+		UsePathStyle: true,
+	})
+}
+
+func TestValidatePresign(t *testing.T) {
+	if err := (MessageProcessorConfig{PresignTTL: time.Hour}).validatePresign(); err != nil {
+		t.Errorf("Erro inesperado: %v", err)
+	}
+	if err := (MessageProcessorConfig{PresignTTL: 8 * 24 * time.Hour}).validatePresign(); err == nil {
+		t.Error("Esperado erro para validade acima de 7 dias")
+	}
+}
+
+func TestPresignDownload_LocalStack(t *testing.T) {
+	mp := &MessageProcessor{
+		config:    MessageProcessorConfig{PresignTTL: 15 * time.Minute},
+		presigner: newS3Presigner(newLocalStackS3Client(), ""),
+	}
+
+	before := time.Now()
+	rawURL, expiresAt, err := mp.PresignDownload(context.TODO(), "video-results", "processed/proc-1.zip")
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	parsed, _ := url.Parse(rawURL)
+	if parsed.Host != "localhost:4566" || parsed.Path != "/video-results/processed/proc-1.zip" {
+		t.Errorf("Esperado URL path-style no LocalStack, obtido %s", rawURL)
+	}
+	if parsed.Query().Get("X-Amz-Expires") != "900" || parsed.Query().Get("X-Amz-Signature") == "" {
+		t.Errorf("Esperado URL assinada com validade de 900s, obtido %s", rawURL)
+	}
+	if expiresAt.Sub(before) < 15*time.Minute || expiresAt.Sub(before) > 16*time.Minute {
+		t.Errorf("Expiração inesperada: %s", expiresAt)
+	}
+}
+
+func TestPresignDownload_EndpointPublico(t *testing.T) {
+	mp := &MessageProcessor{
+		config:    MessageProcessorConfig{PresignTTL: time.Hour},
+		presigner: newS3Presigner(newLocalStackS3Client(), "http://s3.publico.exemplo:8080"),
+	}
+	rawURL, _, err := mp.PresignDownload(context.TODO(), "video-results", "a.zip")
+	if err != nil || !strings.HasPrefix(rawURL, "http://s3.publico.exemplo:8080/video-results/a.zip?") {
+		t.Errorf("Esperado URL no endpoint público, obtido %s (%v)", rawURL, err)
+	}
+}
+
+func TestPresignDownload_Indisponivel(t *testing.T) {
+	presigner := newS3Presigner(newLocalStackS3Client(), "")
+	casos := []*MessageProcessor{
+		{config: MessageProcessorConfig{PresignTTL: 0}, presigner: presigner},
+		{config: MessageProcessorConfig{PresignTTL: time.Hour, BlobBackend: BlobBackendDir}, presigner: presigner},
+		{config: MessageProcessorConfig{PresignTTL: time.Hour}},
+	}
+	for _, mp := range casos {
+		if _, _, err := mp.PresignDownload(context.TODO(), "bucket", "a.zip"); !errors.Is(err, ErrPresignUnavailable) {
+			t.Errorf("Esperado ErrPresignUnavailable, obtido %v", err)
+		}
+	}
+}
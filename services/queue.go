@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Backends de fila disponíveis (QUEUE_BACKEND)
+const (
+	QueueBackendSQS    = "sqs"
+	QueueBackendMemory = "memory"
+	QueueBackendDir    = "dir"
+)
+
+// Visibility timeout padrão dos backends memory e dir
+const defaultVisibilityTimeout = 5 * time.Minute
+
+// Mensagem recebida de uma fila, independente do backend
+type QueueMessage struct {
+	ID         string
+	Body       string
+	Attributes map[string]string // Atributos de mensagem (correlationId, traceparent...)
+	Handle     string            // Identificador da entrega, usado em Ack/Nack/ExtendVisibility
+}
+
+// Mensagem a publicar. GroupID e DeduplicationID só são usados por filas FIFO do SQS.
+type OutgoingMessage struct {
+	Body            string
+	Attributes      map[string]string
+	GroupID         string
+	DeduplicationID string
+}
+
+// Queue abstrai o backend de filas usado pelo processador
+type Queue interface {
+	// Receive aguarda até wait por até max mensagens; mensagens recebidas ficam
+	// invisíveis até Ack, Nack ou o fim do visibility timeout
+	Receive(ctx context.Context, max int32, wait time.Duration) ([]QueueMessage, error)
+	// Ack remove a mensagem da fila após o processamento
+	Ack(ctx context.Context, msg QueueMessage) error
+	// Nack devolve a mensagem para a fila imediatamente
+	Nack(ctx context.Context, msg QueueMessage) error
+	// ExtendVisibility mantém a mensagem invisível por mais timeout a partir de agora
+	ExtendVisibility(ctx context.Context, msg QueueMessage, timeout time.Duration) error
+	// Publish envia uma nova mensagem para a fila
+	Publish(ctx context.Context, msg OutgoingMessage) error
+}
+
+// Backends com operações em lote pendentes implementam Close
+type queueCloser interface {
+	Close(ctx context.Context) error
+}
+
+// Backends com Publish assíncrono permitem aguardar a confirmação do envio
+type confirmingQueue interface {
+	PublishConfirmed(ctx context.Context, msg OutgoingMessage) error
+}
+
+// Validar o backend de fila configurado
+func (c MessageProcessorConfig) validateQueueBackend() error {
+	switch strings.ToLower(c.QueueBackend) {
+	case "", QueueBackendSQS, QueueBackendMemory, QueueBackendDir:
+		return nil
+	default:
+		return fmt.Errorf("backend de fila desconhecido: %s", c.QueueBackend)
+	}
+}
+
+// Criar o backend configurado para a fila identificada pela URL. Nos backends
+// memory e dir o nome da fila é o último segmento da URL (ver queueName).
+func (mp *MessageProcessor) newQueue(url string) Queue {
+	visibility := mp.config.VisibilityTimeout
+	if visibility <= 0 {
+		visibility = defaultVisibilityTimeout
+	}
+
+	switch strings.ToLower(mp.config.QueueBackend) {
+	case QueueBackendMemory:
+		return MemoryQueueNamed(queueName(url), visibility)
+	case QueueBackendDir:
+		return newDirQueue(filepath.Join(mp.config.QueueDir, queueName(url)), visibility)
+	default:
+		return newSQSQueue(mp.sqsClient, url, mp.config.VisibilityTimeout, mp.config.SQSBatchSize, mp.config.SQSBatchFlushInterval)
+	}
+}
+
+// Nome do backend de fila em uso
+func (mp *MessageProcessor) queueBackend() string {
+	if mp.config.QueueBackend == "" {
+		return QueueBackendSQS
+	}
+	return strings.ToLower(mp.config.QueueBackend)
+}
+
+// Fila de resultados (nil se RESULTS_QUEUE_URL não estiver configurada)
+func (mp *MessageProcessor) resultsQueue() Queue {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if mp.results == nil && mp.config.ResultsQueueURL != "" {
+		mp.results = mp.newQueue(mp.config.ResultsQueueURL)
+	}
+	return mp.results
+}
+
+// Fila de controle (nil se CONTROL_QUEUE_URL não estiver configurada)
+func (mp *MessageProcessor) controlQueue() Queue {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if mp.control == nil && mp.config.ControlQueueURL != "" {
+		mp.control = mp.newQueue(mp.config.ControlQueueURL)
+	}
+	return mp.control
+}
+
+// Renovar a visibilidade da mensagem enquanto o job estiver em andamento,
+// para que jobs longos não sejam entregues a outro worker. Retorna a função
+// que interrompe a renovação.
+func (mp *MessageProcessor) keepInvisible(ctx context.Context, queue Queue, message QueueMessage) func() {
+	timeout := mp.config.VisibilityTimeout
+	if timeout <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(timeout / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := queue.ExtendVisibility(ctx, message, timeout); err != nil {
+					log.Printf("⚠️ Erro ao renovar visibilidade da mensagem %s: %v", message.ID, err)
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestValidateQueueBackend(t *testing.T) {
+	for _, backend := range []string{"", "sqs", "memory", "DIR"} {
+		if err := (MessageProcessorConfig{QueueBackend: backend}).validateQueueBackend(); err != nil {
+			t.Errorf("Esperado backend '%s' válido, obtido %v", backend, err)
+		}
+	}
+	if err := (MessageProcessorConfig{QueueBackend: "kafka"}).validateQueueBackend(); err == nil {
+		t.Error("Esperado erro para backend desconhecido")
+	}
+}
+
+func TestNewQueue_SelecionaBackend(t *testing.T) {
+	mp := &MessageProcessor{config: MessageProcessorConfig{QueueBackend: "dir", QueueDir: t.TempDir()}}
+	if _, ok := mp.newQueue("http://localhost/000/fila").(*dirQueue); !ok {
+		t.Error("Esperado backend dir")
+	}
+	mp.config.QueueBackend = "memory"
+	if _, ok := mp.newQueue("http://localhost/000/fila").(*MemoryQueue); !ok {
+		t.Error("Esperado backend memory")
+	}
+	mp.config.QueueBackend = ""
+	if _, ok := mp.newQueue("http://localhost/000/fila").(*sqsQueue); !ok {
+		t.Error("Esperado backend sqs por padrão")
+	}
+}
+
+func TestProcessMessages_BackendMemoria(t *testing.T) {
+	mp := &MessageProcessor{
+		config: MessageProcessorConfig{
+			QueueBackend:    QueueBackendMemory,
+			SQSQueueURL:     "teste-memoria-entrada",
+			ResultsQueueURL: "teste-memoria-resultados",
+			SourceBucket:    "bucket",
+			ResultsBucket:   "bucket",
+			MaxMessages:     10,
+		},
+		s3Client: &mockS3Client{},
+	}
+	input := MemoryQueueNamed("teste-memoria-entrada", 0)
+	results := MemoryQueueNamed("teste-memoria-resultados", 0)
+
+	// Bucket fora da allow-list: rejeitada de forma definitiva, sem acessar o S3
+	input.Publish(context.TODO(), OutgoingMessage{Body: `{"fileId":"a.mp4","processId":"proc-1","sourceBucket":"outro"}`})
+
+	received, err := mp.processMessages(context.TODO(), context.TODO())
+	if err != nil || received != 1 {
+		t.Fatalf("Esperado 1 mensagem recebida, obtido %d (%v)", received, err)
+	}
+	if ready, inFlight := input.Len(); ready != 0 || inFlight != 0 {
+		t.Errorf("Esperado mensagem confirmada, obtido %d/%d", ready, inFlight)
+	}
+
+	published, _ := results.Receive(context.TODO(), 10, time.Millisecond)
+	if len(published) != 1 {
+		t.Fatalf("Esperado 1 resultado publicado, obtido %d", len(published))
+	}
+	var result VideoProcessingResult
+	json.Unmarshal([]byte(published[0].Body), &result)
+	if result.ProcessID != "proc-1" || result.Status != "FAILED" {
+		t.Errorf("Resultado inesperado: %+v", result)
+	}
+}
+
+func TestKeepInvisible_RenovaVisibilidade(t *testing.T) {
+	q := NewMemoryQueue(time.Minute)
+	q.Publish(context.TODO(), OutgoingMessage{Body: "a"})
+	messages, _ := q.Receive(context.TODO(), 1, 0)
+
+	mp := &MessageProcessor{config: MessageProcessorConfig{VisibilityTimeout: 20 * time.Millisecond}}
+	stop := mp.keepInvisible(context.TODO(), q, messages[0])
+	time.Sleep(50 * time.Millisecond)
+	stop()
+
+	if ready, _ := q.Len(); ready != 0 {
+		t.Error("Esperado mensagem mantida invisível durante o job")
+	}
+}
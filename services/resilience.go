@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Estados do circuit breaker
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+// ErrCircuitOpen indica chamada recusada sem acessar o serviço
+var ErrCircuitOpen = errors.New("circuit breaker aberto")
+
+// Política de novas tentativas das chamadas à AWS
+type retryPolicy struct {
+	maxAttempts int
+	backoffMin  time.Duration
+	backoffMax  time.Duration
+	timeout     time.Duration // Prazo de cada tentativa; 0 = sem prazo
+}
+
+// Circuit breaker: após threshold falhas seguidas, recusa chamadas por cooldown
+type circuitBreaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newCircuitBreaker(name string, threshold int, cooldown time.Duration) *circuitBreaker {
+	circuitBreakerOpen.WithLabelValues(name).Set(0)
+	return &circuitBreaker{name: name, threshold: threshold, cooldown: cooldown, state: CircuitClosed}
+}
+
+// Liberar ou recusar uma chamada
+func (b *circuitBreaker) allow() error {
+	if b == nil || b.threshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return fmt.Errorf("%w: %s", ErrCircuitOpen, b.name)
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		log.Printf("🟡 Circuit breaker %s em half-open, testando o serviço", b.name)
+		return nil
+	case CircuitHalfOpen:
+		// Apenas uma chamada de teste por vez
+		if b.probing {
+			return fmt.Errorf("%w: %s", ErrCircuitOpen, b.name)
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Registrar o resultado de uma chamada liberada por allow
+func (b *circuitBreaker) record(failed bool) {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !failed {
+		if b.state != CircuitClosed {
+			log.Printf("🟢 Circuit breaker %s fechado", b.name)
+			circuitBreakerOpen.WithLabelValues(b.name).Set(0)
+		}
+		b.state = CircuitClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		if b.state != CircuitOpen {
+			log.Printf("🔴 Circuit breaker %s aberto após %d falha(s), pausando por %s", b.name, b.failures, b.cooldown)
+		}
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+		circuitBreakerOpen.WithLabelValues(b.name).Set(1)
+	}
+}
+
+// Liberar a chamada de teste sem registrar resultado
+func (b *circuitBreaker) abort() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// Estado atual (open passa a half-open quando o cooldown expira)
+func (b *circuitBreaker) State() string {
+	if b == nil {
+		return CircuitClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// Tempo restante até o circuito aceitar uma chamada de teste (0 se não estiver aberto)
+func (b *circuitBreaker) remainingOpen() time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != CircuitOpen {
+		return 0
+	}
+	return max(b.cooldown-time.Since(b.openedAt), 0)
+}
+
+// Executar a chamada com circuit breaker, prazo e novas tentativas com backoff
+// (só falhas do serviço são repetidas e contam para o breaker)
+func callAWS[T any](ctx context.Context, policy retryPolicy, breaker *circuitBreaker, operation string, call func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	attempts := max(policy.maxAttempts, 1)
+	wait := newBackoff(policy.backoffMin, policy.backoffMax)
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err := breaker.allow(); err != nil {
+			return zero, err
+		}
+
+		var out T
+		out, err = callWithTimeout(ctx, policy.timeout, call)
+		if ctx.Err() != nil {
+			// Cancelamento do chamador não diz nada sobre a saúde do serviço
+			breaker.abort()
+			return zero, err
+		}
+		retryable := isRetryableAWSError(err)
+		breaker.record(retryable)
+		if err == nil {
+			return out, nil
+		}
+		if !retryable || attempt == attempts {
+			break
+		}
+
+		awsRetriesTotal.WithLabelValues(breaker.label(), operation).Inc()
+		delay := wait.Next()
+		log.Printf("🔁 %s falhou (tentativa %d/%d), nova tentativa em %s: %v", operation, attempt, attempts, delay.Round(time.Millisecond), err)
+		select {
+		case <-ctx.Done():
+			return zero, err
+		case <-time.After(delay):
+		}
+	}
+	return zero, err
+}
+
+func callWithTimeout[T any](ctx context.Context, timeout time.Duration, call func(ctx context.Context) (T, error)) (T, error) {
+	if timeout <= 0 {
+		return call(ctx)
+	}
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return call(callCtx)
+}
+
+func (b *circuitBreaker) label() string {
+	if b == nil {
+		return "aws"
+	}
+	return b.name
+}
+
+// Falhas transitórias do serviço, da rede ou do prazo da tentativa
+func isRetryableAWSError(err error) bool {
+	if err == nil || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	var httpErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &httpErr) {
+		code := httpErr.HTTPStatusCode()
+		return code >= 500 || code == http.StatusTooManyRequests || code == http.StatusRequestTimeout
+	}
+	// Sem resposta HTTP: erro de rede ou prazo da tentativa esgotado
+	return true
+}
+
+// Voltar o corpo ao início antes de repetir um upload; false se não for possível
+func rewindBody(body io.Reader) bool {
+	if body == nil {
+		return true
+	}
+	seeker, ok := body.(io.Seeker)
+	if !ok {
+		return false
+	}
+	_, err := seeker.Seek(0, io.SeekStart)
+	return err == nil
+}
+
+// Maior tempo restante entre os circuitos abertos (0 se todos estiverem fechados)
+func (mp *MessageProcessor) circuitPause() time.Duration {
+	var pause time.Duration
+	for _, breaker := range mp.breakers {
+		pause = max(pause, breaker.remainingOpen())
+	}
+	return pause
+}
+
+// CircuitBreakers retorna o estado de cada circuit breaker (closed, open, half-open)
+func (mp *MessageProcessor) CircuitBreakers() map[string]string {
+	states := make(map[string]string, len(mp.breakers))
+	for _, breaker := range mp.breakers {
+		states[breaker.name] = breaker.State()
+	}
+	return states
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Erro com status HTTP, como os retornados pelo SDK
+type httpStatusError struct{ code int }
+
+func (e *httpStatusError) Error() string       { return "status " + http.StatusText(e.code) }
+func (e *httpStatusError) HTTPStatusCode() int { return e.code }
+
+var testRetryPolicy = retryPolicy{maxAttempts: 3, backoffMin: time.Millisecond, backoffMax: time.Millisecond}
+
+func TestCallAWS_RepeteFalhasDoServico(t *testing.T) {
+	calls := 0
+	out, err := callAWS(context.Background(), testRetryPolicy, nil, "op", func(ctx context.Context) (string, error) {
+		calls++
+		if calls < 3 {
+			return "", &httpStatusError{code: 503}
+		}
+		return "ok", nil
+	})
+	if err != nil || out != "ok" {
+		t.Errorf("Esperado sucesso na terceira tentativa, obtido '%s' (%v)", out, err)
+	}
+	if calls != 3 {
+		t.Errorf("Esperado 3 tentativas, obtido %d", calls)
+	}
+}
+
+func TestCallAWS_NaoRepeteErroDoCliente(t *testing.T) {
+	calls := 0
+	_, err := callAWS(context.Background(), testRetryPolicy, nil, "op", func(ctx context.Context) (string, error) {
+		calls++
+		return "", &httpStatusError{code: 404}
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("Esperado erro sem novas tentativas, obtido %d chamada(s) (%v)", calls, err)
+	}
+}
+
+func TestCallAWS_PrazoPorTentativa(t *testing.T) {
+	policy := testRetryPolicy
+	policy.maxAttempts = 2
+	policy.timeout = 10 * time.Millisecond
+	calls := 0
+	_, err := callAWS(context.Background(), policy, nil, "op", func(ctx context.Context) (string, error) {
+		calls++
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || calls != 2 {
+		t.Errorf("Esperado prazo esgotado em 2 tentativas, obtido %d (%v)", calls, err)
+	}
+}
+
+func TestCircuitBreaker_AbreEFechaAposCooldown(t *testing.T) {
+	breaker := newCircuitBreaker("teste", 2, 20*time.Millisecond)
+	policy := retryPolicy{maxAttempts: 1}
+	failing := func(ctx context.Context) (string, error) { return "", errors.New("conexão recusada") }
+
+	callAWS(context.Background(), policy, breaker, "op", failing)
+	callAWS(context.Background(), policy, breaker, "op", failing)
+	if breaker.State() != CircuitOpen {
+		t.Fatalf("Esperado circuito aberto, obtido %s", breaker.State())
+	}
+
+	called := false
+	_, err := callAWS(context.Background(), policy, breaker, "op", func(ctx context.Context) (string, error) {
+		called = true
+		return "ok", nil
+	})
+	if !errors.Is(err, ErrCircuitOpen) || called {
+		t.Errorf("Esperado chamada recusada com circuito aberto, obtido %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if breaker.State() != CircuitHalfOpen {
+		t.Errorf("Esperado half-open após cooldown, obtido %s", breaker.State())
+	}
+	if _, err := callAWS(context.Background(), policy, breaker, "op", func(ctx context.Context) (string, error) { return "ok", nil }); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if breaker.State() != CircuitClosed {
+		t.Errorf("Esperado circuito fechado após chamada de teste, obtido %s", breaker.State())
+	}
+}
+
+func TestCircuitBreaker_ErroDoClienteNaoAbre(t *testing.T) {
+	breaker := newCircuitBreaker("teste", 1, time.Minute)
+	callAWS(context.Background(), retryPolicy{maxAttempts: 1}, breaker, "op", func(ctx context.Context) (string, error) {
+		return "", &httpStatusError{code: 403}
+	})
+	if breaker.State() != CircuitClosed {
+		t.Errorf("Esperado circuito fechado após erro 4xx, obtido %s", breaker.State())
+	}
+}
+
+func TestCircuitPause(t *testing.T) {
+	breaker := newCircuitBreaker("sqs", 1, time.Minute)
+	mp := &MessageProcessor{breakers: []*circuitBreaker{newCircuitBreaker("s3", 1, time.Minute), breaker}}
+	if mp.circuitPause() != 0 {
+		t.Error("Esperado nenhuma pausa com circuitos fechados")
+	}
+	breaker.record(true)
+	if pause := mp.circuitPause(); pause <= 0 || pause > time.Minute {
+		t.Errorf("Esperado pausa até o fim do cooldown, obtido %s", pause)
+	}
+	if states := mp.CircuitBreakers(); states["sqs"] != CircuitOpen || states["s3"] != CircuitClosed {
+		t.Errorf("Estados inesperados: %v", states)
+	}
+}
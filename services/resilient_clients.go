@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// S3Client com novas tentativas, prazo por operação e circuit breaker
+type resilientS3Client struct {
+	client  S3Client
+	policy  retryPolicy
+	breaker *circuitBreaker
+}
+
+func newResilientS3Client(client S3Client, policy retryPolicy, breaker *circuitBreaker) *resilientS3Client {
+	return &resilientS3Client{client: client, policy: policy, breaker: breaker}
+}
+
+// O prazo do GetObject vale até a resposta chegar; a leitura do corpo
+// (download do vídeo) não é limitada por ele
+func (c *resilientS3Client) GetObject(ctx context.Context, input *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	policy := c.policy
+	policy.timeout = 0
+	return callAWS(ctx, policy, c.breaker, "s3:GetObject", func(ctx context.Context) (*s3.GetObjectOutput, error) {
+		callCtx, cancel := context.WithCancel(ctx)
+		stop := func() bool { return true }
+		if c.policy.timeout > 0 {
+			stop = time.AfterFunc(c.policy.timeout, cancel).Stop
+		}
+		resp, err := c.client.GetObject(callCtx, input, optFns...)
+		if !stop() && err == nil {
+			// Prazo esgotado junto com a resposta: o corpo já foi cancelado
+			resp.Body.Close()
+			err = context.DeadlineExceeded
+		}
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		return resp, nil
+	})
+}
+
+// Uploads só são repetidos se o corpo puder voltar ao início; sem prazo, pois
+// o envio do corpo (ZIP de resultado) dura o quanto o tamanho exigir
+func (c *resilientS3Client) PutObject(ctx context.Context, input *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	policy := c.policy
+	policy.timeout = 0
+	if _, ok := input.Body.(io.Seeker); !ok && input.Body != nil {
+		policy.maxAttempts = 1
+	}
+	first := true
+	return callAWS(ctx, policy, c.breaker, "s3:PutObject", func(ctx context.Context) (*s3.PutObjectOutput, error) {
+		if !first {
+			rewindBody(input.Body)
+		}
+		first = false
+		return c.client.PutObject(ctx, input, optFns...)
+	})
+}
+
+func (c *resilientS3Client) DeleteObject(ctx context.Context, input *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	return callAWS(ctx, c.policy, c.breaker, "s3:DeleteObject", func(ctx context.Context) (*s3.DeleteObjectOutput, error) {
+		return c.client.DeleteObject(ctx, input, optFns...)
+	})
+}
+
+func (c *resilientS3Client) HeadObject(ctx context.Context, input *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	return callAWS(ctx, c.policy, c.breaker, "s3:HeadObject", func(ctx context.Context) (*s3.HeadObjectOutput, error) {
+		return c.client.HeadObject(ctx, input, optFns...)
+	})
+}
+
+func (c *resilientS3Client) ListObjectsV2(ctx context.Context, input *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	return callAWS(ctx, c.policy, c.breaker, "s3:ListObjectsV2", func(ctx context.Context) (*s3.ListObjectsV2Output, error) {
+		return c.client.ListObjectsV2(ctx, input, optFns...)
+	})
+}
+
+// Sem prazo: a cópia no servidor de um vídeo grande pode levar minutos
+func (c *resilientS3Client) CopyObject(ctx context.Context, input *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	policy := c.policy
+	policy.timeout = 0
+	return callAWS(ctx, policy, c.breaker, "s3:CopyObject", func(ctx context.Context) (*s3.CopyObjectOutput, error) {
+		return c.client.CopyObject(ctx, input, optFns...)
+	})
+}
+
+func (c *resilientS3Client) PutObjectTagging(ctx context.Context, input *s3.PutObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.PutObjectTaggingOutput, error) {
+	return callAWS(ctx, c.policy, c.breaker, "s3:PutObjectTagging", func(ctx context.Context) (*s3.PutObjectTaggingOutput, error) {
+		return c.client.PutObjectTagging(ctx, input, optFns...)
+	})
+}
+
+func (c *resilientS3Client) GetObjectTagging(ctx context.Context, input *s3.GetObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.GetObjectTaggingOutput, error) {
+	return callAWS(ctx, c.policy, c.breaker, "s3:GetObjectTagging", func(ctx context.Context) (*s3.GetObjectTaggingOutput, error) {
+		return c.client.GetObjectTagging(ctx, input, optFns...)
+	})
+}
+
+func (c *resilientS3Client) CreateMultipartUpload(ctx context.Context, input *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	return callAWS(ctx, c.policy, c.breaker, "s3:CreateMultipartUpload", func(ctx context.Context) (*s3.CreateMultipartUploadOutput, error) {
+		return c.client.CreateMultipartUpload(ctx, input, optFns...)
+	})
+}
+
+// Sem prazo: o S3 monta o objeto a partir das partes antes de responder
+func (c *resilientS3Client) CompleteMultipartUpload(ctx context.Context, input *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	policy := c.policy
+	policy.timeout = 0
+	return callAWS(ctx, policy, c.breaker, "s3:CompleteMultipartUpload", func(ctx context.Context) (*s3.CompleteMultipartUploadOutput, error) {
+		return c.client.CompleteMultipartUpload(ctx, input, optFns...)
+	})
+}
+
+func (c *resilientS3Client) AbortMultipartUpload(ctx context.Context, input *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	return callAWS(ctx, c.policy, c.breaker, "s3:AbortMultipartUpload", func(ctx context.Context) (*s3.AbortMultipartUploadOutput, error) {
+		return c.client.AbortMultipartUpload(ctx, input, optFns...)
+	})
+}
+
+// Libera o ctx da chamada quando o corpo da resposta é fechado
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+// SQSClient com novas tentativas, prazo por operação e circuit breaker
+type resilientSQSClient struct {
+	client  SQSClient
+	policy  retryPolicy
+	breaker *circuitBreaker
+}
+
+func newResilientSQSClient(client SQSClient, policy retryPolicy, breaker *circuitBreaker) *resilientSQSClient {
+	return &resilientSQSClient{client: client, policy: policy, breaker: breaker}
+}
+
+// O prazo do ReceiveMessage inclui o tempo de long polling
+func (c *resilientSQSClient) ReceiveMessage(ctx context.Context, input *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	policy := c.policy
+	if policy.timeout > 0 {
+		policy.timeout += time.Duration(input.WaitTimeSeconds) * time.Second
+	}
+	return callAWS(ctx, policy, c.breaker, "sqs:ReceiveMessage", func(ctx context.Context) (*sqs.ReceiveMessageOutput, error) {
+		return c.client.ReceiveMessage(ctx, input, optFns...)
+	})
+}
+
+func (c *resilientSQSClient) SendMessage(ctx context.Context, input *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	return callAWS(ctx, c.policy, c.breaker, "sqs:SendMessage", func(ctx context.Context) (*sqs.SendMessageOutput, error) {
+		return c.client.SendMessage(ctx, input, optFns...)
+	})
+}
+
+func (c *resilientSQSClient) DeleteMessage(ctx context.Context, input *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	return callAWS(ctx, c.policy, c.breaker, "sqs:DeleteMessage", func(ctx context.Context) (*sqs.DeleteMessageOutput, error) {
+		return c.client.DeleteMessage(ctx, input, optFns...)
+	})
+}
+
+func (c *resilientSQSClient) ChangeMessageVisibility(ctx context.Context, input *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+	return callAWS(ctx, c.policy, c.breaker, "sqs:ChangeMessageVisibility", func(ctx context.Context) (*sqs.ChangeMessageVisibilityOutput, error) {
+		return c.client.ChangeMessageVisibility(ctx, input, optFns...)
+	})
+}
+
+// Chamadas em lote têm uma única tentativa: o sqsBatcher já reenvia só as
+// entradas que falharam (ver batchPolicy)
+func (c *resilientSQSClient) DeleteMessageBatch(ctx context.Context, input *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+	return callAWS(ctx, c.batchPolicy(), c.breaker, "sqs:DeleteMessageBatch", func(ctx context.Context) (*sqs.DeleteMessageBatchOutput, error) {
+		return c.client.DeleteMessageBatch(ctx, input, optFns...)
+	})
+}
+
+func (c *resilientSQSClient) SendMessageBatch(ctx context.Context, input *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	return callAWS(ctx, c.batchPolicy(), c.breaker, "sqs:SendMessageBatch", func(ctx context.Context) (*sqs.SendMessageBatchOutput, error) {
+		return c.client.SendMessageBatch(ctx, input, optFns...)
+	})
+}
+
+func (c *resilientSQSClient) batchPolicy() retryPolicy {
+	policy := c.policy
+	policy.maxAttempts = 1
+	return policy
+}
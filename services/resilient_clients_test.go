@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// S3Client de teste para os wrappers: falha as primeiras chamadas de PutObject
+type mockS3ClientInstavel struct {
+	mockS3Client
+	putFailures int
+	bodies      []string
+}
+
+func (m *mockS3ClientInstavel) PutObject(ctx context.Context, input *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	data, _ := io.ReadAll(input.Body)
+	m.bodies = append(m.bodies, string(data))
+	if m.putFailures > 0 {
+		m.putFailures--
+		return nil, &httpStatusError{code: 500}
+	}
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (m *mockS3ClientInstavel) GetObject(ctx context.Context, input *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	// O corpo só pode ser lido enquanto o ctx da chamada estiver ativo
+	return &s3.GetObjectOutput{Body: io.NopCloser(&ctxReader{ctx: ctx, r: strings.NewReader("video")})}, nil
+}
+
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+func TestResilientS3Client_PutObjectReenviaCorpoCompleto(t *testing.T) {
+	mock := &mockS3ClientInstavel{putFailures: 1}
+	client := newResilientS3Client(mock, testRetryPolicy, nil)
+	_, err := client.PutObject(context.Background(), &s3.PutObjectInput{Body: strings.NewReader("conteudo")})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(mock.bodies) != 2 || mock.bodies[1] != "conteudo" {
+		t.Errorf("Esperado corpo completo na nova tentativa, obtido %q", mock.bodies)
+	}
+}
+
+func TestResilientS3Client_PrazoNaoInterrompeDownload(t *testing.T) {
+	policy := testRetryPolicy
+	policy.timeout = 10 * time.Millisecond
+	client := newResilientS3Client(&mockS3ClientInstavel{}, policy, nil)
+
+	resp, err := client.GetObject(context.Background(), &s3.GetObjectInput{})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	defer resp.Body.Close()
+	time.Sleep(20 * time.Millisecond)
+	data, err := io.ReadAll(resp.Body)
+	if err != nil || string(data) != "video" {
+		t.Errorf("Esperado corpo lido após o prazo da chamada, obtido %q (%v)", data, err)
+	}
+}
+
+func TestNewAWSClients_SemNovasTentativasDoSDK(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := aws.Config{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "teste", SecretAccessKey: "teste"}, nil
+		}),
+	}
+	sqsClient, s3Client, _ := MessageProcessorConfig{LocalStackURL: server.URL}.newAWSClients(cfg)
+	policy := retryPolicy{maxAttempts: 3, backoffMin: time.Millisecond, backoffMax: time.Millisecond}
+
+	s3Wrapped := newResilientS3Client(s3Client, policy, nil)
+	if _, err := s3Wrapped.HeadObject(context.Background(), &s3.HeadObjectInput{Bucket: aws.String("b"), Key: aws.String("k")}); err == nil {
+		t.Fatal("Esperado erro com S3 indisponível")
+	}
+	if n := atomic.SwapInt32(&requests, 0); n != 3 {
+		t.Errorf("Esperado 3 requisições ao S3 (uma por tentativa), obtido %d", n)
+	}
+
+	sqsWrapped := newResilientSQSClient(sqsClient, policy, nil)
+	if _, err := sqsWrapped.DeleteMessage(context.Background(), &sqs.DeleteMessageInput{QueueUrl: aws.String(server.URL + "/fila"), ReceiptHandle: aws.String("r")}); err == nil {
+		t.Fatal("Esperado erro com SQS indisponível")
+	}
+	if n := atomic.LoadInt32(&requests); n != 3 {
+		t.Errorf("Esperado 3 requisições ao SQS (uma por tentativa), obtido %d", n)
+	}
+}
+
+// S3Client de teste com upload e cópia lentos, interrompidos se o ctx expirar
+type mockS3ClientLento struct {
+	mockS3Client
+	calls int
+}
+
+func (m *mockS3ClientLento) PutObject(ctx context.Context, input *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	m.calls++
+	_, err := io.ReadAll(&ctxReader{ctx: ctx, r: input.Body})
+	return &s3.PutObjectOutput{}, err
+}
+
+func (m *mockS3ClientLento) CopyObject(ctx context.Context, input *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	m.calls++
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(50 * time.Millisecond):
+		return &s3.CopyObjectOutput{}, nil
+	}
+}
+
+// Corpo que leva 10ms por leitura
+type slowReader struct {
+	r io.Reader
+}
+
+func (s *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(10 * time.Millisecond)
+	return s.r.Read(p[:min(len(p), 2)])
+}
+
+func TestResilientS3Client_UploadECopiaSemPrazo(t *testing.T) {
+	policy := testRetryPolicy
+	policy.timeout = 20 * time.Millisecond
+	mock := &mockS3ClientLento{}
+	client := newResilientS3Client(mock, policy, nil)
+
+	// ~50ms de envio, além do prazo de 20ms
+	if _, err := client.PutObject(context.Background(), &s3.PutObjectInput{Body: &slowReader{r: strings.NewReader("conteudo")}}); err != nil {
+		t.Errorf("Esperado upload lento concluído, obtido %v", err)
+	}
+	if _, err := client.CopyObject(context.Background(), &s3.CopyObjectInput{}); err != nil {
+		t.Errorf("Esperado cópia lenta concluída, obtido %v", err)
+	}
+	if mock.calls != 2 {
+		t.Errorf("Esperado 1 chamada por operação, obtido %d", mock.calls)
+	}
+}
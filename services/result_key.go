@@ -0,0 +1,142 @@
+package services
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"video-processor/models"
+)
+
+// Template padrão da chave do ZIP (equivale ao formato anterior)
+const defaultResultKeyTemplate = "{prefix}{processId}_frames_{timestamp}.zip"
+
+// Placeholders aceitos em RESULT_KEY_TEMPLATE
+var resultKeyPlaceholders = []string{
+	"prefix", "processId", "tenant", "sourceKey", "basename", "format",
+	"timestamp", "year", "month", "day", "hour",
+}
+
+var placeholderRe = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// Validar o template: apenas placeholders conhecidos e terminado em .zip
+func validateResultKeyTemplate(template string) error {
+	if template == "" {
+		return nil
+	}
+	for _, match := range placeholderRe.FindAllStringSubmatch(template, -1) {
+		if !slices.Contains(resultKeyPlaceholders, match[1]) {
+			return fmt.Errorf("placeholder desconhecido no template de chave: {%s}", match[1])
+		}
+	}
+	if !strings.HasSuffix(template, ".zip") {
+		return fmt.Errorf("template de chave deve terminar em .zip: %s", template)
+	}
+	return nil
+}
+
+// Preencher o template da chave do ZIP. O resultado é validado como chave
+// relativa, sem segmentos vazios, "." ou ".." (o sourceKey vem de fora)
+func renderResultKey(template string, values map[string]string) (string, error) {
+	if template == "" {
+		template = defaultResultKeyTemplate
+	}
+	key := placeholderRe.ReplaceAllStringFunc(template, func(match string) string {
+		return values[match[1:len(match)-1]]
+	})
+	if strings.HasPrefix(key, "/") {
+		return "", fmt.Errorf("chave de resultado inválida: %s", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", fmt.Errorf("chave de resultado inválida: %s", key)
+		}
+	}
+	return key, nil
+}
+
+// Valores dos placeholders de um job
+func resultKeyValues(t JobTargets, processID, sourceKey string, at time.Time) map[string]string {
+	at = at.UTC()
+	base := path.Base(sourceKey)
+	return map[string]string{
+		"prefix":    t.OutputPrefix,
+		"processId": processID,
+		"tenant":    t.Tenant,
+		"sourceKey": sourceKey,
+		"basename":  strings.TrimSuffix(base, path.Ext(base)),
+		"format":    frameFormat,
+		"timestamp": at.Format("20060102_150405"),
+		"year":      at.Format("2006"),
+		"month":     at.Format("01"),
+		"day":       at.Format("02"),
+		"hour":      at.Format("15"),
+	}
+}
+
+// Opções de gravação do ZIP: tipo, nome sugerido para download, metadados e
+// tags com a origem, os dados do processamento e o SHA-256 do ZIP
+func resultObjectOptions(key, sourceKey string, result models.ProcessingResult) PutOptions {
+	details := map[string]string{
+		"source-key":  sourceKey,
+		"frame-count": strconv.Itoa(result.FrameCount),
+	}
+	if result.Duration > 0 {
+		details["duration-seconds"] = strconv.FormatFloat(result.Duration, 'f', 3, 64)
+	}
+	if result.ToolVersion != "" {
+		details["tool-version"] = result.ToolVersion
+	}
+	if result.SHA256 != "" {
+		details["sha256"] = result.SHA256
+	}
+
+	opts := PutOptions{
+		ContentType:        "application/zip",
+		ContentDisposition: attachmentDisposition(path.Base(key)),
+		Metadata:           make(map[string]string, len(details)),
+		Tags:               make(map[string]string, len(details)),
+	}
+	for name, value := range details {
+		opts.Metadata[name] = metadataValue(value)
+		opts.Tags[name] = tagValue(value)
+	}
+	return opts
+}
+
+// Content-Disposition de download (nomes não ASCII usam filename*, RFC 2231)
+func attachmentDisposition(filename string) string {
+	return mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+}
+
+// Metadados do S3 viajam em cabeçalhos HTTP: valores não ASCII são codificados
+func metadataValue(value string) string {
+	for _, r := range value {
+		if r < 0x20 || r > 0x7e {
+			return url.PathEscape(value)
+		}
+	}
+	return value
+}
+
+// Tags do S3 aceitam letras, números, espaço e + - = . _ : / @, até 256 caracteres
+func tagValue(value string) string {
+	var sb strings.Builder
+	for _, r := range value {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune(" +-=._:/@", r):
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+		if sb.Len() == 256 {
+			break
+		}
+	}
+	return sb.String()
+}
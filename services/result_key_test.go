@@ -0,0 +1,91 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"video-processor/models"
+)
+
+func TestValidateResultKeyTemplate(t *testing.T) {
+	if err := validateResultKeyTemplate("{tenant}/{year}/{month}/{basename}_{processId}.zip"); err != nil {
+		t.Errorf("Erro inesperado: %v", err)
+	}
+	if err := validateResultKeyTemplate("{prefix}{usuario}.zip"); err == nil || !strings.Contains(err.Error(), "{usuario}") {
+		t.Errorf("Esperado erro citando o placeholder desconhecido, obtido %v", err)
+	}
+	if err := validateResultKeyTemplate("{prefix}{processId}.tar"); err == nil {
+		t.Error("Esperado erro para template sem extensão .zip")
+	}
+}
+
+func TestZipKey_Template(t *testing.T) {
+	targets := JobTargets{
+		OutputPrefix: "processed/",
+		Tenant:       "acme",
+		KeyTemplate:  "{prefix}{tenant}/{year}/{month}/{day}/{hour}/{basename}_{processId}_{format}.zip",
+	}
+	at := time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC)
+	key, err := targets.ZipKey("proc-1", "uploads/aula 01.mp4", at)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if key != "processed/acme/2024/03/05/14/aula 01_proc-1_png.zip" {
+		t.Errorf("Chave inesperada: %s", key)
+	}
+}
+
+func TestZipKey_SourceKeyPreservaPastas(t *testing.T) {
+	targets := JobTargets{KeyTemplate: "frames/{sourceKey}.zip"}
+	key, err := targets.ZipKey("proc-1", "clientes/a/video.mp4", time.Now())
+	if err != nil || key != "frames/clientes/a/video.mp4.zip" {
+		t.Errorf("Esperado 'frames/clientes/a/video.mp4.zip', obtido '%s' (%v)", key, err)
+	}
+}
+
+func TestZipKey_ChaveInvalida(t *testing.T) {
+	casos := []struct{ template, sourceKey string }{
+		{"frames/{sourceKey}.zip", "../../outro-tenant/video.mp4"},
+		{"{tenant}/{processId}.zip", "video.mp4"}, // tenant vazio gera segmento vazio
+		{"/{processId}.zip", "video.mp4"},
+	}
+	for _, caso := range casos {
+		targets := JobTargets{KeyTemplate: caso.template}
+		if key, err := targets.ZipKey("proc-1", caso.sourceKey, time.Now()); err == nil {
+			t.Errorf("Esperado erro para %s com %s, obtido '%s'", caso.template, caso.sourceKey, key)
+		}
+	}
+}
+
+func TestResultObjectOptions(t *testing.T) {
+	result := models.ProcessingResult{FrameCount: 12, Duration: 12.5, ToolVersion: "ffmpeg 6.1.1"}
+	opts := resultObjectOptions("processed/aula_proc-1.zip", "uploads/aula çã.mp4", result)
+
+	if opts.ContentType != "application/zip" {
+		t.Errorf("Esperado 'application/zip', obtido '%s'", opts.ContentType)
+	}
+	if opts.ContentDisposition != `attachment; filename=aula_proc-1.zip` {
+		t.Errorf("Content-Disposition inesperado: %s", opts.ContentDisposition)
+	}
+	if opts.Metadata["frame-count"] != "12" || opts.Metadata["duration-seconds"] != "12.500" || opts.Metadata["tool-version"] != "ffmpeg 6.1.1" {
+		t.Errorf("Metadados inesperados: %v", opts.Metadata)
+	}
+	if opts.Metadata["source-key"] != "uploads%2Faula%20%C3%A7%C3%A3.mp4" {
+		t.Errorf("Esperado source-key codificado em ASCII, obtido '%s'", opts.Metadata["source-key"])
+	}
+	if opts.Tags["source-key"] != "uploads/aula __.mp4" || opts.Tags["frame-count"] != "12" {
+		t.Errorf("Tags inesperadas: %v", opts.Tags)
+	}
+}
+
+func TestAttachmentDisposition_NomeNaoASCII(t *testing.T) {
+	if got := attachmentDisposition("aula ç.zip"); got != "attachment; filename*=utf-8''aula%20%C3%A7.zip" {
+		t.Errorf("Esperado filename* codificado, obtido '%s'", got)
+	}
+}
+
+func TestTagValue_Limite(t *testing.T) {
+	if got := tagValue(strings.Repeat("a", 300)); len(got) != 256 {
+		t.Errorf("Esperado valor truncado em 256, obtido %d", len(got))
+	}
+}
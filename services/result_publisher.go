@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Destinos de resultados disponíveis (RESULT_SINKS)
+const (
+	ResultSinkQueue   = "queue"
+	ResultSinkSNS     = "sns"
+	ResultSinkWebhook = "webhook"
+	ResultSinkLog     = "log"
+)
+
+// Destinos usados quando RESULT_SINKS não é informado. Cada um só é ativado
+// se estiver configurado (fila de resultados, tópico SNS, URL de callback)
+var defaultResultSinks = []string{ResultSinkQueue, ResultSinkSNS, ResultSinkWebhook}
+
+// Retornado por destinos que não se aplicam ao evento (ex: job sem callback)
+var errResultSkipped = errors.New("destino não se aplica ao evento")
+
+// Evento de status de um job, entregue a cada destino
+type ResultEvent struct {
+	Result   VideoProcessingResult
+	Body     []byte // JSON do resultado
+	Sequence int64  // Posição do evento no job (ver nextResultSequence)
+}
+
+// ResultPublisher entrega eventos de status a um destino
+type ResultPublisher interface {
+	Name() string
+	PublishResult(ctx context.Context, event ResultEvent) error
+}
+
+// Distribui cada evento para vários destinos. Os destinos rodam em paralelo
+// e isolados: erro, lentidão ou panic em um não impede a entrega nos demais
+type compositeResultPublisher struct {
+	sinks []ResultPublisher
+}
+
+func newCompositeResultPublisher(sinks ...ResultPublisher) *compositeResultPublisher {
+	return &compositeResultPublisher{sinks: sinks}
+}
+
+func (c *compositeResultPublisher) Name() string { return "composite" }
+
+func (c *compositeResultPublisher) PublishResult(ctx context.Context, event ResultEvent) error {
+	return joinPublishErrors(c.publishTo(ctx, event, nil))
+}
+
+// Entregar aos destinos fora de skip; retorna o resultado de cada destino
+// tentado, por nome (nil = confirmado)
+func (c *compositeResultPublisher) publishTo(ctx context.Context, event ResultEvent, skip []string) map[string]error {
+	errs := make([]error, len(c.sinks))
+	attempted := make([]bool, len(c.sinks))
+	var wg sync.WaitGroup
+	for i, sink := range c.sinks {
+		if slices.Contains(skip, sink.Name()) {
+			continue
+		}
+		attempted[i] = true
+		wg.Add(1)
+		go func(i int, sink ResultPublisher) {
+			defer wg.Done()
+			errs[i] = publishToSink(ctx, sink, event)
+		}(i, sink)
+	}
+	wg.Wait()
+
+	results := make(map[string]error)
+	for i, sink := range c.sinks {
+		if attempted[i] {
+			results[sink.Name()] = errs[i]
+		}
+	}
+	return results
+}
+
+// Erros dos destinos que falharam, em ordem de nome
+func joinPublishErrors(results map[string]error) error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(results)) {
+		errs = append(errs, results[name])
+	}
+	return errors.Join(errs...)
+}
+
+// Falha que não se resolve com novas tentativas (ex: 4xx do webhook)
+func isPermanentPublishError(err error) bool {
+	var permanent *permanentWebhookError
+	return errors.As(err, &permanent)
+}
+
+// Entregar em um destino registrando métricas e convertendo panic em erro
+func publishToSink(ctx context.Context, sink ResultPublisher, event ResultEvent) (err error) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+		if errors.Is(err, errResultSkipped) {
+			resultPublishTotal.WithLabelValues(sink.Name(), "skipped").Inc()
+			err = nil
+			return
+		}
+		resultPublishDuration.WithLabelValues(sink.Name()).Observe(time.Since(start).Seconds())
+		if err != nil {
+			resultPublishTotal.WithLabelValues(sink.Name(), "failed").Inc()
+			logf(ctx, "❌ Erro ao publicar resultado em %s: %v", sink.Name(), err)
+			err = fmt.Errorf("%s: %w", sink.Name(), err)
+			return
+		}
+		resultPublishTotal.WithLabelValues(sink.Name(), "published").Inc()
+	}()
+	return sink.PublishResult(ctx, event)
+}
+
+// Validar os destinos de resultados configurados
+func (c MessageProcessorConfig) validateResultSinks() error {
+	for _, sink := range c.ResultSinks {
+		switch strings.ToLower(sink) {
+		case ResultSinkQueue, ResultSinkSNS, ResultSinkWebhook, ResultSinkLog:
+		default:
+			return fmt.Errorf("destino de resultados desconhecido: %s", sink)
+		}
+	}
+	return nil
+}
+
+// Publicador de resultados com os destinos configurados, criado sob demanda.
+// Retorna nil se nenhum destino estiver ativo
+func (mp *MessageProcessor) resultPublisher() ResultPublisher {
+	mp.mu.Lock()
+	publisher := mp.publisher
+	mp.mu.Unlock()
+	if publisher != nil {
+		return publisher
+	}
+
+	names := mp.config.ResultSinks
+	if len(names) == 0 {
+		names = defaultResultSinks
+	}
+
+	var sinks []ResultPublisher
+	for _, name := range names {
+		switch strings.ToLower(name) {
+		case ResultSinkQueue:
+			if results := mp.resultsQueue(); results != nil {
+				sinks = append(sinks, &queueResultPublisher{queue: results, groupTemplate: mp.config.ResultsGroupID, confirm: mp.config.OutboxDir != ""})
+			}
+		case ResultSinkSNS:
+			if mp.config.ResultsTopicARN != "" && mp.snsClient != nil {
+				sinks = append(sinks, &snsResultPublisher{client: mp.snsClient, topicARN: mp.config.ResultsTopicARN, groupTemplate: mp.config.ResultsGroupID})
+			}
+		case ResultSinkWebhook:
+			// Sem WEBHOOK_URL nem hosts permitidos, nenhuma mensagem pode ter callback
+			if mp.config.WebhookURL == "" && len(mp.config.WebhookAllowedHosts) == 0 {
+				continue
+			}
+			sinks = append(sinks, &webhookResultPublisher{webhooks: mp.webhooks(), defaultURL: mp.config.WebhookURL})
+		case ResultSinkLog:
+			sinks = append(sinks, newLogResultPublisher())
+		}
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if mp.publisher == nil {
+		mp.publisher = newCompositeResultPublisher(sinks...)
+	}
+	return mp.publisher
+}
+
+// Fila de resultados (SQS ou o backend de filas configurado)
+type queueResultPublisher struct {
+	queue         Queue
+	groupTemplate string
+	confirm       bool // Aguardar a confirmação de envios em lote (usado com o outbox)
+}
+
+func (p *queueResultPublisher) Name() string { return ResultSinkQueue }
+
+func (p *queueResultPublisher) PublishResult(ctx context.Context, event ResultEvent) error {
+	logf(ctx, "📨 Enviando resultado para fila: %s", string(event.Body))
+
+	// GroupID e DeduplicationID só são usados se a fila for FIFO
+	msg := OutgoingMessage{
+		Body:            string(event.Body),
+		GroupID:         resultsGroupID(ctx, p.groupTemplate, event.Result.ProcessID),
+		DeduplicationID: resultDeduplicationID(event.Result.ProcessID, event.Result.Status, event.Sequence),
+	}
+	if tc, ok := traceFrom(ctx); ok {
+		msg.Attributes = tc.messageAttributes()
+	}
+
+	// Com lotes habilitados o envio é assíncrono; falhas são tratadas por entrada no lote,
+	// a menos que o outbox precise da confirmação para remover a entrada
+	publish := p.queue.Publish
+	if confirming, ok := p.queue.(confirmingQueue); ok && p.confirm {
+		publish = confirming.PublishConfirmed
+	}
+	if err := publish(ctx, msg); err != nil {
+		return fmt.Errorf("erro ao enviar mensagem para fila de resultados: %w", err)
+	}
+	logf(ctx, "✅ Resultado enviado com sucesso para fila de resultados")
+	return nil
+}
+
+// Callback HTTP do job (callbackUrl da mensagem) ou o WEBHOOK_URL global
+type webhookResultPublisher struct {
+	webhooks   *webhookPublisher
+	defaultURL string
+}
+
+func (p *webhookResultPublisher) Name() string { return ResultSinkWebhook }
+
+func (p *webhookResultPublisher) PublishResult(ctx context.Context, event ResultEvent) error {
+	callbackURL := callbackURLFrom(ctx)
+	if callbackURL == "" {
+		callbackURL = p.defaultURL
+	}
+	if callbackURL == "" {
+		return errResultSkipped
+	}
+	return p.webhooks.Publish(ctx, callbackURL, event.Body)
+}
+
+// Log estruturado (JSON em stdout), útil para coletores de log e auditoria
+type logResultPublisher struct {
+	logger *slog.Logger
+}
+
+func newLogResultPublisher() *logResultPublisher {
+	return &logResultPublisher{logger: slog.New(slog.NewJSONHandler(os.Stdout, nil))}
+}
+
+func (p *logResultPublisher) Name() string { return ResultSinkLog }
+
+func (p *logResultPublisher) PublishResult(ctx context.Context, event ResultEvent) error {
+	attrs := []any{
+		"processId", event.Result.ProcessID,
+		"zipKey", event.Result.ZipKey,
+		"status", event.Result.Status,
+		"timestamp", event.Result.Timestamp,
+		"sequence", event.Sequence,
+	}
+	if tc, ok := traceFrom(ctx); ok {
+		attrs = append(attrs, "correlationId", tc.CorrelationID, "traceId", tc.TraceID)
+	}
+	p.logger.InfoContext(ctx, "video_processing_result", attrs...)
+	return nil
+}
+
+// Destinos ativos, para o log de inicialização
+func describeResultSinks(publisher ResultPublisher) string {
+	composite, ok := publisher.(*compositeResultPublisher)
+	if !ok {
+		return "nenhum"
+	}
+	names := make([]string, len(composite.sinks))
+	for i, sink := range composite.sinks {
+		names[i] = sink.Name()
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Destino de teste que registra os eventos recebidos
+type recordingSink struct {
+	name  string
+	err   error
+	panic bool
+
+	mu     sync.Mutex
+	events []ResultEvent
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) PublishResult(ctx context.Context, event ResultEvent) error {
+	if s.panic {
+		panic("destino quebrado")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return s.err
+}
+
+func TestCompositeResultPublisher_IsolaFalhas(t *testing.T) {
+	ok := &recordingSink{name: "ok"}
+	failing := &recordingSink{name: "falha", err: errors.New("indisponível")}
+	broken := &recordingSink{name: "quebrado", panic: true}
+	publisher := newCompositeResultPublisher(failing, broken, ok)
+
+	err := publisher.PublishResult(context.Background(), ResultEvent{Result: VideoProcessingResult{ProcessID: "proc-1", Status: "COMPLETED"}})
+	if err == nil {
+		t.Fatal("Esperado erro dos destinos com falha")
+	}
+	if !strings.Contains(err.Error(), "falha: indisponível") || !strings.Contains(err.Error(), "quebrado: panic") {
+		t.Errorf("Esperado erro identificando os destinos, obtido %v", err)
+	}
+	if len(ok.events) != 1 {
+		t.Errorf("Esperado 1 evento no destino saudável, obtido %d", len(ok.events))
+	}
+}
+
+func TestCompositeResultPublisher_DestinoIgnorado(t *testing.T) {
+	skipped := &recordingSink{name: "ignorado", err: errResultSkipped}
+	if err := newCompositeResultPublisher(skipped).PublishResult(context.Background(), ResultEvent{}); err != nil {
+		t.Errorf("Esperado nenhum erro para destino ignorado, obtido %v", err)
+	}
+}
+
+func TestValidateResultSinks(t *testing.T) {
+	if err := (MessageProcessorConfig{ResultSinks: []string{"queue", "SNS", "webhook", "log"}}).validateResultSinks(); err != nil {
+		t.Errorf("Erro inesperado: %v", err)
+	}
+	if err := (MessageProcessorConfig{ResultSinks: []string{"kafka"}}).validateResultSinks(); err == nil {
+		t.Error("Esperado erro para destino desconhecido")
+	}
+}
+
+func TestResultPublisher_DestinosConfigurados(t *testing.T) {
+	mp := &MessageProcessor{config: MessageProcessorConfig{WebhookAllowedHosts: []string{"hooks.exemplo.com"}}}
+	if got := describeResultSinks(mp.resultPublisher()); got != "webhook" {
+		t.Errorf("Esperado destinos 'webhook', obtido '%s'", got)
+	}
+
+	mp = &MessageProcessor{config: MessageProcessorConfig{ResultsTopicARN: "arn:aws:sns:us-east-1:000000000000:results", ResultSinks: []string{"sns", "log"}}, snsClient: &mockSNSClient{}}
+	if got := describeResultSinks(mp.resultPublisher()); got != "sns, log" {
+		t.Errorf("Esperado destinos 'sns, log', obtido '%s'", got)
+	}
+
+	mp = &MessageProcessor{}
+	if publisher := mp.resultPublisher(); publisher != nil {
+		t.Errorf("Esperado nenhum destino sem configuração, obtido %s", describeResultSinks(publisher))
+	}
+}
+
+func TestWebhookResultPublisher_SemCallback(t *testing.T) {
+	p := &webhookResultPublisher{}
+	if err := p.PublishResult(context.Background(), ResultEvent{}); !errors.Is(err, errResultSkipped) {
+		t.Errorf("Esperado errResultSkipped sem URL de callback, obtido %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Criptografia no servidor dos ZIPs (RESULT_SSE): SSE-S3 ou SSE-KMS
+const (
+	EncryptionS3  = "AES256"
+	EncryptionKMS = "aws:kms"
+)
+
+// Algoritmos de checksum aceitos pelo S3 (RESULT_CHECKSUM_ALGORITHM)
+var checksumAlgorithms = []string{"CRC32", "CRC32C", "SHA1", "SHA256"}
+
+// Como o ZIP é gravado: criptografia (com a chave KMS, opcional), classe de
+// armazenamento e algoritmo do checksum. Vazio = padrão do bucket
+type StorageOptions struct {
+	Encryption        string
+	KMSKeyID          string
+	StorageClass      string
+	ChecksumAlgorithm string
+}
+
+// Como o ZIP foi efetivamente armazenado, publicado no resultado
+type StoredObject struct {
+	Encryption        string `json:"encryption,omitempty"`
+	KMSKeyID          string `json:"kmsKeyId,omitempty"`
+	StorageClass      string `json:"storageClass,omitempty"`
+	ChecksumAlgorithm string `json:"checksumAlgorithm,omitempty"`
+	Checksum          string `json:"checksum,omitempty"`
+	ETag              string `json:"etag,omitempty"`
+}
+
+// Opções configuradas (RESULT_SSE, RESULT_KMS_KEY_ID, RESULT_STORAGE_CLASS,
+// RESULT_CHECKSUM_ALGORITHM)
+func (c MessageProcessorConfig) resultStorage() StorageOptions {
+	return StorageOptions{
+		Encryption:        c.ResultEncryption,
+		KMSKeyID:          c.ResultKMSKeyID,
+		StorageClass:      c.ResultStorageClass,
+		ChecksumAlgorithm: c.ResultChecksum,
+	}
+}
+
+// Validar as opções configuradas
+func (c MessageProcessorConfig) validateResultStorage() error {
+	_, err := normalizeStorageOptions(c.resultStorage())
+	return err
+}
+
+// Opções de um job: as da mensagem sobrescrevem as configuradas. Trocar a
+// criptografia descarta a chave KMS configurada
+func (c MessageProcessorConfig) storageFor(videoMsg VideoProcessingMessage) (StorageOptions, error) {
+	opts := c.resultStorage()
+	if videoMsg.Encryption != "" {
+		opts.Encryption = videoMsg.Encryption
+		opts.KMSKeyID = videoMsg.KMSKeyID
+	} else if videoMsg.KMSKeyID != "" {
+		opts.KMSKeyID = videoMsg.KMSKeyID
+	}
+	if videoMsg.StorageClass != "" {
+		opts.StorageClass = videoMsg.StorageClass
+	}
+	if videoMsg.ChecksumAlgorithm != "" {
+		opts.ChecksumAlgorithm = videoMsg.ChecksumAlgorithm
+	}
+	return normalizeStorageOptions(opts)
+}
+
+// Converter para os valores da API do S3, recusando combinações inválidas
+func normalizeStorageOptions(opts StorageOptions) (StorageOptions, error) {
+	switch strings.ToLower(opts.Encryption) {
+	case "", "none":
+		opts.Encryption = ""
+	case "aes256", "sse-s3":
+		opts.Encryption = EncryptionS3
+	case "aws:kms", "sse-kms", "kms":
+		opts.Encryption = EncryptionKMS
+	default:
+		return opts, fmt.Errorf("criptografia desconhecida: %s", opts.Encryption)
+	}
+	if opts.KMSKeyID != "" && opts.Encryption != EncryptionKMS {
+		return opts, fmt.Errorf("chave KMS exige criptografia aws:kms")
+	}
+
+	opts.StorageClass = strings.ToUpper(opts.StorageClass)
+	if opts.StorageClass != "" && !slices.Contains(types.StorageClass("").Values(), types.StorageClass(opts.StorageClass)) {
+		return opts, fmt.Errorf("classe de armazenamento desconhecida: %s", opts.StorageClass)
+	}
+
+	opts.ChecksumAlgorithm = strings.ToUpper(opts.ChecksumAlgorithm)
+	if opts.ChecksumAlgorithm != "" && !slices.Contains(checksumAlgorithms, opts.ChecksumAlgorithm) {
+		return opts, fmt.Errorf("algoritmo de checksum desconhecido: %s", opts.ChecksumAlgorithm)
+	}
+	return opts, nil
+}
+
+// Hash do algoritmo; os CRCs são codificados em big-endian, como no S3
+func newChecksumHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case "CRC32":
+		return crc32.NewIEEE()
+	case "CRC32C":
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	case "SHA1":
+		return sha1.New()
+	case "SHA256":
+		return sha256.New()
+	default:
+		return nil
+	}
+}
+
+// Checksum em base64 (formato dos cabeçalhos x-amz-checksum-*)
+func encodeChecksum(h hash.Hash) string {
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// Checksum de um arquivo local
+func fileChecksum(path, algorithm string) (string, error) {
+	h := newChecksumHash(algorithm)
+	if h == nil {
+		return "", fmt.Errorf("algoritmo de checksum desconhecido: %s", algorithm)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return encodeChecksum(h), nil
+}
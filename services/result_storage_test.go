@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestNormalizeStorageOptions(t *testing.T) {
+	opts, err := normalizeStorageOptions(StorageOptions{Encryption: "sse-kms", KMSKeyID: "alias/zips", StorageClass: "standard_ia", ChecksumAlgorithm: "crc32c"})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if opts.Encryption != EncryptionKMS || opts.StorageClass != "STANDARD_IA" || opts.ChecksumAlgorithm != "CRC32C" {
+		t.Errorf("Esperado valores da API do S3, obtido %+v", opts)
+	}
+	if opts, _ := normalizeStorageOptions(StorageOptions{Encryption: "sse-s3"}); opts.Encryption != EncryptionS3 {
+		t.Errorf("Esperado AES256, obtido %s", opts.Encryption)
+	}
+}
+
+func TestNormalizeStorageOptions_Invalidas(t *testing.T) {
+	for _, opts := range []StorageOptions{
+		{Encryption: "rot13"},
+		{Encryption: "AES256", KMSKeyID: "alias/zips"},
+		{StorageClass: "BARATINHO"},
+		{ChecksumAlgorithm: "MD5"},
+	} {
+		if _, err := normalizeStorageOptions(opts); err == nil {
+			t.Errorf("Esperado erro para %+v", opts)
+		}
+	}
+}
+
+func TestStorageFor_MensagemSobrescreve(t *testing.T) {
+	config := MessageProcessorConfig{ResultEncryption: "aws:kms", ResultKMSKeyID: "alias/padrao", ResultChecksum: "SHA256"}
+
+	opts, err := config.storageFor(VideoProcessingMessage{Encryption: "AES256", StorageClass: "GLACIER_IR"})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if opts.Encryption != EncryptionS3 || opts.KMSKeyID != "" || opts.StorageClass != "GLACIER_IR" || opts.ChecksumAlgorithm != "SHA256" {
+		t.Errorf("Esperado AES256 sem chave KMS, obtido %+v", opts)
+	}
+
+	opts, _ = config.storageFor(VideoProcessingMessage{KMSKeyID: "alias/tenant"})
+	if opts.Encryption != EncryptionKMS || opts.KMSKeyID != "alias/tenant" {
+		t.Errorf("Esperado chave KMS da mensagem, obtido %+v", opts)
+	}
+}
+
+func TestFileChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "abc")
+	os.WriteFile(path, []byte("abc"), 0644)
+
+	cases := map[string]string{
+		"CRC32":  "NSRBwg==",
+		"SHA256": "ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=",
+	}
+	for algorithm, expected := range cases {
+		if sum, err := fileChecksum(path, algorithm); err != nil || sum != expected {
+			t.Errorf("Esperado %s '%s', obtido '%s' (%v)", algorithm, expected, sum, err)
+		}
+	}
+	if _, err := fileChecksum(path, "MD5"); err == nil {
+		t.Error("Esperado erro para algoritmo desconhecido")
+	}
+}
+
+func TestUploadResult_RegistraArmazenamento(t *testing.T) {
+	mp := &MessageProcessor{config: MessageProcessorConfig{BlobBackend: BlobBackendDir, BlobDir: t.TempDir()}}
+	zipPath := filepath.Join(t.TempDir(), "frames.zip")
+	os.WriteFile(zipPath, []byte("abc"), 0644)
+
+	opts := PutOptions{StorageOptions: StorageOptions{Encryption: EncryptionKMS, KMSKeyID: "alias/zips", StorageClass: "STANDARD_IA", ChecksumAlgorithm: "SHA256"}}
+	stored, err := mp.uploadResult(context.TODO(), "resultados", "frames.zip", zipPath, opts)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if stored.Encryption != EncryptionKMS || stored.KMSKeyID != "alias/zips" || stored.StorageClass != "STANDARD_IA" {
+		t.Errorf("Esperado criptografia e classe registradas, obtido %+v", stored)
+	}
+	if stored.Checksum != "ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=" || stored.ETag == "" {
+		t.Errorf("Esperado checksum e ETag registrados, obtido %+v", stored)
+	}
+}
+
+// Mock S3Client que retorna um checksum diferente do enviado
+type mockS3ClientChecksumDivergente struct {
+	mockS3Client
+}
+
+func (m *mockS3ClientChecksumDivergente) PutObject(ctx context.Context, input *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	return &s3.PutObjectOutput{ChecksumSHA256: aws.String("outro")}, nil
+}
+
+func TestUploadResult_ChecksumDivergente(t *testing.T) {
+	mp := &MessageProcessor{s3Client: &mockS3ClientChecksumDivergente{}}
+	zipPath := filepath.Join(t.TempDir(), "frames.zip")
+	os.WriteFile(zipPath, []byte("abc"), 0644)
+
+	_, err := mp.uploadResult(context.TODO(), "bucket", "frames.zip", zipPath, PutOptions{StorageOptions: StorageOptions{ChecksumAlgorithm: "SHA256"}})
+	if err == nil || !strings.Contains(err.Error(), "divergente") {
+		t.Errorf("Esperado erro de checksum divergente, obtido %v", err)
+	}
+}
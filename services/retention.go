@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Políticas de retenção do vídeo original após o processamento (SOURCE_RETENTION)
+const (
+	RetentionDelete  = "delete"  // Excluir o original (padrão)
+	RetentionKeep    = "keep"    // Manter o original intocado
+	RetentionTag     = "tag"     // Manter e marcar com tags de processado
+	RetentionArchive = "archive" // Copiar para o arquivo (bucket/prefixo) e excluir o original
+)
+
+// Prefixo padrão do arquivo de originais
+const defaultArchivePrefix = "archive/"
+
+// Resultado da política aplicada, enviado na mensagem de resultado
+type RetentionOutcome struct {
+	Policy        string `json:"policy"`
+	Status        string `json:"status"` // applied ou failed
+	ArchiveBucket string `json:"archiveBucket,omitempty"`
+	ArchiveKey    string `json:"archiveKey,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// Validar e normalizar o nome da política
+func parseRetentionPolicy(policy string) (string, error) {
+	switch p := strings.ToLower(strings.TrimSpace(policy)); p {
+	case RetentionDelete, RetentionKeep, RetentionTag, RetentionArchive:
+		return p, nil
+	default:
+		return "", fmt.Errorf("política de retenção desconhecida: %s", policy)
+	}
+}
+
+// Validar as políticas configuradas (padrão e por tenant)
+func (c MessageProcessorConfig) validateRetention() error {
+	if c.SourceRetention != "" {
+		if _, err := parseRetentionPolicy(c.SourceRetention); err != nil {
+			return err
+		}
+	}
+	for tenant, policy := range c.RetentionByTenant {
+		if _, err := parseRetentionPolicy(policy); err != nil {
+			return fmt.Errorf("tenant %s: %w", tenant, err)
+		}
+	}
+	return nil
+}
+
+// Política do job: a da mensagem, senão a do tenant, senão a padrão
+func (c MessageProcessorConfig) retentionFor(tenant, requested string) (string, error) {
+	if requested != "" {
+		return parseRetentionPolicy(requested)
+	}
+	if policy, ok := c.RetentionByTenant[tenant]; ok {
+		return parseRetentionPolicy(policy)
+	}
+	if c.SourceRetention != "" {
+		return parseRetentionPolicy(c.SourceRetention)
+	}
+	return RetentionDelete, nil
+}
+
+// Destino do original arquivado: mesmo bucket (ou RETENTION_ARCHIVE_BUCKET)
+// com a chave original sob RETENTION_ARCHIVE_PREFIX
+func (c MessageProcessorConfig) archiveLocation(sourceBucket, key string) (string, string) {
+	bucket := c.RetentionArchiveBucket
+	if bucket == "" {
+		bucket = sourceBucket
+	}
+	prefix := c.RetentionArchivePrefix
+	if prefix == "" {
+		prefix = defaultArchivePrefix
+	}
+	return bucket, path.Join(prefix, key)
+}
+
+// Aplicar a política de retenção ao vídeo original. Falhas não invalidam o job:
+// são registradas e informadas no resultado
+func (mp *MessageProcessor) applyRetention(ctx context.Context, policy, bucket, key, processID string) RetentionOutcome {
+	outcome := RetentionOutcome{Policy: policy, Status: "applied"}
+	store := mp.BlobStore()
+
+	var err error
+	switch policy {
+	case RetentionKeep:
+		logf(ctx, "📦 Arquivo original mantido: s3://%s/%s", bucket, key)
+	case RetentionTag:
+		err = store.Tag(ctx, bucket, key, map[string]string{"processed": "true", "processId": processID})
+		if err == nil {
+			logf(ctx, "🏷️  Arquivo original marcado como processado: s3://%s/%s", bucket, key)
+		}
+	case RetentionArchive:
+		outcome.ArchiveBucket, outcome.ArchiveKey = mp.config.archiveLocation(bucket, key)
+		// Só exclui o original depois que a cópia foi confirmada
+		if err = store.Copy(ctx, bucket, key, outcome.ArchiveBucket, outcome.ArchiveKey); err == nil {
+			err = store.Delete(ctx, bucket, key)
+		}
+		if err == nil {
+			logf(ctx, "🗄️  Arquivo original arquivado: s3://%s/%s", outcome.ArchiveBucket, outcome.ArchiveKey)
+		}
+	default:
+		err = store.Delete(ctx, bucket, key)
+		if err == nil {
+			logf(ctx, "🗑️ Arquivo original excluído do S3: s3://%s/%s", bucket, key)
+		}
+	}
+
+	if err != nil {
+		logf(ctx, "⚠️ Aviso: Erro ao aplicar retenção %s ao arquivo original: %v", policy, err)
+		outcome.Status = "failed"
+		outcome.Error = err.Error()
+	}
+	return outcome
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseRetentionPolicy(t *testing.T) {
+	if policy, err := parseRetentionPolicy(" Archive "); err != nil || policy != RetentionArchive {
+		t.Errorf("Esperado 'archive', obtido '%s' (%v)", policy, err)
+	}
+	if _, err := parseRetentionPolicy("shred"); err == nil {
+		t.Error("Esperado erro para política desconhecida")
+	}
+}
+
+func TestValidateRetention_TenantInvalido(t *testing.T) {
+	config := MessageProcessorConfig{SourceRetention: RetentionKeep, RetentionByTenant: map[string]string{"acme": "guardar"}}
+	if err := config.validateRetention(); err == nil || !strings.Contains(err.Error(), "acme") {
+		t.Errorf("Esperado erro citando o tenant, obtido %v", err)
+	}
+}
+
+func TestRetentionFor_PadraoDelete(t *testing.T) {
+	policy, err := MessageProcessorConfig{}.retentionFor("acme", "")
+	if err != nil || policy != RetentionDelete {
+		t.Errorf("Esperado 'delete', obtido '%s' (%v)", policy, err)
+	}
+}
+
+func TestArchiveLocation(t *testing.T) {
+	bucket, key := MessageProcessorConfig{}.archiveLocation("videos", "clientes/a.mp4")
+	if bucket != "videos" || key != "archive/clientes/a.mp4" {
+		t.Errorf("Esperado videos/archive/clientes/a.mp4, obtido %s/%s", bucket, key)
+	}
+
+	config := MessageProcessorConfig{RetentionArchiveBucket: "frio", RetentionArchivePrefix: "originais"}
+	if bucket, key := config.archiveLocation("videos", "a.mp4"); bucket != "frio" || key != "originais/a.mp4" {
+		t.Errorf("Esperado frio/originais/a.mp4, obtido %s/%s", bucket, key)
+	}
+}
+
+// MessageProcessor com backend em diretório e um vídeo original em videos/a.mp4
+func newRetentionProcessor(t *testing.T) (*MessageProcessor, BlobStore) {
+	store := NewDirBlobStore(t.TempDir())
+	store.Put(context.TODO(), "videos", "a.mp4", strings.NewReader("video"), PutOptions{})
+	mp := &MessageProcessor{config: MessageProcessorConfig{BlobBackend: BlobBackendDir}, blobs: store}
+	return mp, store
+}
+
+func TestApplyRetention_Delete(t *testing.T) {
+	mp, store := newRetentionProcessor(t)
+	outcome := mp.applyRetention(context.TODO(), RetentionDelete, "videos", "a.mp4", "proc-1")
+	if outcome.Status != "applied" {
+		t.Errorf("Esperado 'applied', obtido %+v", outcome)
+	}
+	if _, err := store.Head(context.TODO(), "videos", "a.mp4"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Esperado original excluído, obtido %v", err)
+	}
+}
+
+func TestApplyRetention_Keep(t *testing.T) {
+	mp, store := newRetentionProcessor(t)
+	outcome := mp.applyRetention(context.TODO(), RetentionKeep, "videos", "a.mp4", "proc-1")
+	if outcome.Status != "applied" || outcome.Policy != RetentionKeep {
+		t.Errorf("Esperado keep aplicado, obtido %+v", outcome)
+	}
+	if _, err := store.Head(context.TODO(), "videos", "a.mp4"); err != nil {
+		t.Errorf("Esperado original mantido, obtido %v", err)
+	}
+}
+
+func TestApplyRetention_Tag(t *testing.T) {
+	mp, store := newRetentionProcessor(t)
+	outcome := mp.applyRetention(context.TODO(), RetentionTag, "videos", "a.mp4", "proc-1")
+	if outcome.Status != "applied" {
+		t.Errorf("Esperado 'applied', obtido %+v", outcome)
+	}
+	sidecar, _ := store.(*dirBlobStore).readSidecar("videos", "a.mp4")
+	if sidecar.Tags["processed"] != "true" || sidecar.Tags["processId"] != "proc-1" {
+		t.Errorf("Esperado tags de processado, obtido %v", sidecar.Tags)
+	}
+}
+
+func TestApplyRetention_Archive(t *testing.T) {
+	mp, store := newRetentionProcessor(t)
+	outcome := mp.applyRetention(context.TODO(), RetentionArchive, "videos", "a.mp4", "proc-1")
+	if outcome.Status != "applied" || outcome.ArchiveBucket != "videos" || outcome.ArchiveKey != "archive/a.mp4" {
+		t.Errorf("Esperado arquivado em videos/archive/a.mp4, obtido %+v", outcome)
+	}
+	if _, err := store.Head(context.TODO(), "videos", "archive/a.mp4"); err != nil {
+		t.Errorf("Esperado cópia no arquivo, obtido %v", err)
+	}
+	if _, err := store.Head(context.TODO(), "videos", "a.mp4"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Esperado original removido, obtido %v", err)
+	}
+}
+
+func TestApplyRetention_FalhaInformada(t *testing.T) {
+	mp, store := newRetentionProcessor(t)
+	outcome := mp.applyRetention(context.TODO(), RetentionArchive, "videos", "nada.mp4", "proc-1")
+	if outcome.Status != "failed" || outcome.Error == "" {
+		t.Errorf("Esperado falha informada no resultado, obtido %+v", outcome)
+	}
+	if _, err := store.Head(context.TODO(), "videos", "a.mp4"); err != nil {
+		t.Errorf("Esperado outros objetos intocados, obtido %v", err)
+	}
+}
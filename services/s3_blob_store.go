@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Armazenamento no S3 (ou LocalStack, via endpoint do client)
+type s3BlobStore struct {
+	client S3Client
+}
+
+func NewS3BlobStore(client S3Client) BlobStore {
+	return &s3BlobStore{client: client}
+}
+
+func (s *s3BlobStore) Get(ctx context.Context, bucket, key string) (io.ReadCloser, ObjectInfo, error) {
+	resp, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		ChecksumMode: types.ChecksumModeEnabled,
+	})
+	if err != nil {
+		return nil, ObjectInfo{}, s3Error(err)
+	}
+	info := ObjectInfo{
+		Key:         key,
+		Size:        aws.ToInt64(resp.ContentLength),
+		ETag:        aws.ToString(resp.ETag),
+		ContentType: aws.ToString(resp.ContentType),
+		Metadata:    resp.Metadata,
+	}
+	info.ContentDisposition = aws.ToString(resp.ContentDisposition)
+	info.Encryption = string(resp.ServerSideEncryption)
+	info.ChecksumAlgorithm, info.Checksum = objectChecksum(resp.ChecksumCRC32, resp.ChecksumCRC32C, resp.ChecksumSHA1, resp.ChecksumSHA256)
+	if resp.LastModified != nil {
+		info.LastModified = *resp.LastModified
+	}
+	return resp.Body, info, nil
+}
+
+func (s *s3BlobStore) Put(ctx context.Context, bucket, key string, body io.Reader, opts PutOptions) (ObjectInfo, error) {
+	input := &s3.PutObjectInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		Body:     body,
+		Metadata: opts.Metadata,
+	}
+	if opts.ContentType != "" {
+		input.ContentType = aws.String(opts.ContentType)
+	}
+	if opts.ContentDisposition != "" {
+		input.ContentDisposition = aws.String(opts.ContentDisposition)
+	}
+	if len(opts.Tags) > 0 {
+		// Tags no upload vão no cabeçalho x-amz-tagging, em formato de query string
+		tags := url.Values{}
+		for key, value := range opts.Tags {
+			tags.Set(key, value)
+		}
+		input.Tagging = aws.String(tags.Encode())
+	}
+	if opts.Encryption != "" {
+		input.ServerSideEncryption = types.ServerSideEncryption(opts.Encryption)
+	}
+	if opts.KMSKeyID != "" {
+		input.SSEKMSKeyId = aws.String(opts.KMSKeyID)
+	}
+	if opts.StorageClass != "" {
+		input.StorageClass = types.StorageClass(opts.StorageClass)
+	}
+	if opts.ChecksumAlgorithm != "" {
+		// Com o valor informado o S3 confere o conteúdo recebido (BadDigest se divergir);
+		// sem ele, o SDK calcula o checksum durante o envio
+		input.ChecksumAlgorithm = types.ChecksumAlgorithm(opts.ChecksumAlgorithm)
+		if opts.Checksum != "" {
+			switch opts.ChecksumAlgorithm {
+			case "CRC32":
+				input.ChecksumCRC32 = aws.String(opts.Checksum)
+			case "CRC32C":
+				input.ChecksumCRC32C = aws.String(opts.Checksum)
+			case "SHA1":
+				input.ChecksumSHA1 = aws.String(opts.Checksum)
+			case "SHA256":
+				input.ChecksumSHA256 = aws.String(opts.Checksum)
+			}
+		}
+	}
+	resp, err := s.client.PutObject(ctx, input)
+	if err != nil {
+		return ObjectInfo{}, s3Error(err)
+	}
+
+	info := ObjectInfo{
+		Key:               key,
+		ETag:              aws.ToString(resp.ETag),
+		ContentType:       opts.ContentType,
+		Metadata:          opts.Metadata,
+		Encryption:        string(resp.ServerSideEncryption),
+		KMSKeyID:          aws.ToString(resp.SSEKMSKeyId),
+		StorageClass:      opts.StorageClass,
+		ChecksumAlgorithm: opts.ChecksumAlgorithm,
+	}
+	info.ContentDisposition = opts.ContentDisposition
+	if algorithm, checksum := objectChecksum(resp.ChecksumCRC32, resp.ChecksumCRC32C, resp.ChecksumSHA1, resp.ChecksumSHA256); algorithm == opts.ChecksumAlgorithm {
+		info.Checksum = checksum
+	}
+	return info, nil
+}
+
+func (s *s3BlobStore) Delete(ctx context.Context, bucket, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	return s3Error(err)
+}
+
+func (s *s3BlobStore) Head(ctx context.Context, bucket, key string) (ObjectInfo, error) {
+	resp, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		ChecksumMode: types.ChecksumModeEnabled,
+	})
+	if err != nil {
+		return ObjectInfo{}, s3Error(err)
+	}
+	info := ObjectInfo{
+		Key:         key,
+		Size:        aws.ToInt64(resp.ContentLength),
+		ETag:        aws.ToString(resp.ETag),
+		ContentType: aws.ToString(resp.ContentType),
+		Metadata:    resp.Metadata,
+	}
+	info.ContentDisposition = aws.ToString(resp.ContentDisposition)
+	info.Encryption = string(resp.ServerSideEncryption)
+	info.KMSKeyID = aws.ToString(resp.SSEKMSKeyId)
+	info.StorageClass = string(resp.StorageClass)
+	info.ChecksumAlgorithm, info.Checksum = objectChecksum(resp.ChecksumCRC32, resp.ChecksumCRC32C, resp.ChecksumSHA1, resp.ChecksumSHA256)
+	if resp.LastModified != nil {
+		info.LastModified = *resp.LastModified
+	}
+	return info, nil
+}
+
+func (s *s3BlobStore) List(ctx context.Context, bucket, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}
+	for {
+		resp, err := s.client.ListObjectsV2(ctx, input)
+		if err != nil {
+			return nil, s3Error(err)
+		}
+		for _, obj := range resp.Contents {
+			info := ObjectInfo{
+				Key:  aws.ToString(obj.Key),
+				Size: aws.ToInt64(obj.Size),
+				ETag: aws.ToString(obj.ETag),
+			}
+			if obj.LastModified != nil {
+				info.LastModified = *obj.LastModified
+			}
+			objects = append(objects, info)
+		}
+		if !aws.ToBool(resp.IsTruncated) || resp.NextContinuationToken == nil {
+			return objects, nil
+		}
+		input.ContinuationToken = resp.NextContinuationToken
+	}
+}
+
+func (s *s3BlobStore) Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error {
+	_, err := s.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(dstBucket),
+		Key:        aws.String(dstKey),
+		CopySource: aws.String(url.PathEscape(srcBucket + "/" + srcKey)),
+	})
+	return s3Error(err)
+}
+
+func (s *s3BlobStore) Tag(ctx context.Context, bucket, key string, tags map[string]string) error {
+	tagSet := make([]types.Tag, 0, len(tags))
+	for name, value := range tags {
+		tagSet = append(tagSet, types.Tag{Key: aws.String(name), Value: aws.String(value)})
+	}
+	_, err := s.client.PutObjectTagging(ctx, &s3.PutObjectTaggingInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		Tagging: &types.Tagging{TagSet: tagSet},
+	})
+	return s3Error(err)
+}
+
+func (s *s3BlobStore) Tags(ctx context.Context, bucket, key string) (map[string]string, error) {
+	resp, err := s.client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, s3Error(err)
+	}
+	tags := make(map[string]string, len(resp.TagSet))
+	for _, tag := range resp.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
+
+// Checksum gravado no objeto e seu algoritmo (Get e Head só o retornam com
+// ChecksumMode). Objetos multipart podem ter checksum composto ("<valor>-<partes>")
+func objectChecksum(crc32, crc32c, sha1, sha256 *string) (string, string) {
+	switch {
+	case sha256 != nil:
+		return "SHA256", *sha256
+	case sha1 != nil:
+		return "SHA1", *sha1
+	case crc32c != nil:
+		return "CRC32C", *crc32c
+	case crc32 != nil:
+		return "CRC32", *crc32
+	default:
+		return "", ""
+	}
+}
+
+// Converter "objeto inexistente" do S3 em ErrBlobNotFound
+func s3Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	var noSuchKey *types.NoSuchKey
+	var notFound *types.NotFound
+	// Operações como GetObjectTagging não têm o erro tipado, só o código
+	var apiErr interface{ ErrorCode() string }
+	if errors.As(err, &noSuchKey) || errors.As(err, &notFound) || (errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchKey") {
+		return fmt.Errorf("%w: %v", ErrBlobNotFound, err)
+	}
+	return err
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Mock S3Client com objetos inexistentes e listagem paginada
+type mockS3ClientListagem struct {
+	mockS3Client
+	pages [][]string
+}
+
+func (m *mockS3ClientListagem) HeadObject(ctx context.Context, input *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	return nil, &s3types.NotFound{}
+}
+
+func (m *mockS3ClientListagem) ListObjectsV2(ctx context.Context, input *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	page := 0
+	if input.ContinuationToken != nil {
+		page = 1
+	}
+	out := &s3.ListObjectsV2Output{IsTruncated: aws.Bool(page == 0)}
+	if page == 0 {
+		out.NextContinuationToken = aws.String("proxima")
+	}
+	for _, key := range m.pages[page] {
+		out.Contents = append(out.Contents, s3types.Object{Key: aws.String(key), Size: aws.Int64(1)})
+	}
+	return out, nil
+}
+
+func TestS3BlobStore_HeadInexistente(t *testing.T) {
+	store := NewS3BlobStore(&mockS3ClientListagem{})
+	if _, err := store.Head(context.TODO(), "bucket", "nada"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Esperado ErrBlobNotFound, obtido %v", err)
+	}
+}
+
+func TestS3BlobStore_ListPaginado(t *testing.T) {
+	store := NewS3BlobStore(&mockS3ClientListagem{pages: [][]string{{"a", "b"}, {"c"}}})
+	objects, err := store.List(context.TODO(), "bucket", "")
+	if err != nil || len(objects) != 3 || objects[2].Key != "c" {
+		t.Errorf("Esperado 3 objetos de 2 páginas, obtido %v (%v)", objects, err)
+	}
+}
+
+func TestS3BlobStore_PutMetadados(t *testing.T) {
+	mockS3 := &mockS3ClientCaptura{}
+	store := NewS3BlobStore(mockS3)
+	store.Put(context.TODO(), "bucket", "key", nil, PutOptions{ContentType: "application/zip", Metadata: map[string]string{"a": "b"}})
+	if aws.ToString(mockS3.put.ContentType) != "application/zip" || mockS3.put.Metadata["a"] != "b" {
+		t.Errorf("Esperado Content-Type e metadados repassados, obtido %+v", mockS3.put)
+	}
+}
+
+// Mock S3Client que captura as entradas de cópia e tagging
+type mockS3ClientCopia struct {
+	mockS3Client
+	copy    *s3.CopyObjectInput
+	tagging *s3.PutObjectTaggingInput
+}
+
+func (m *mockS3ClientCopia) CopyObject(ctx context.Context, input *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	m.copy = input
+	return &s3.CopyObjectOutput{}, nil
+}
+
+func (m *mockS3ClientCopia) PutObjectTagging(ctx context.Context, input *s3.PutObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.PutObjectTaggingOutput, error) {
+	m.tagging = input
+	return &s3.PutObjectTaggingOutput{}, nil
+}
+
+func TestS3BlobStore_CopyTagging(t *testing.T) {
+	mockS3 := &mockS3ClientCopia{}
+	store := NewS3BlobStore(mockS3)
+
+	if err := store.Copy(context.TODO(), "videos", "pasta/meu vídeo.mp4", "arquivo", "archive/pasta/meu vídeo.mp4"); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if src := aws.ToString(mockS3.copy.CopySource); src != "videos%2Fpasta%2Fmeu%20v%C3%ADdeo.mp4" {
+		t.Errorf("Esperado CopySource codificado, obtido '%s'", src)
+	}
+	if aws.ToString(mockS3.copy.Bucket) != "arquivo" || aws.ToString(mockS3.copy.Key) != "archive/pasta/meu vídeo.mp4" {
+		t.Errorf("Destino inesperado: %+v", mockS3.copy)
+	}
+
+	if err := store.Tag(context.TODO(), "videos", "a.mp4", map[string]string{"processed": "true"}); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	tags := mockS3.tagging.Tagging.TagSet
+	if len(tags) != 1 || aws.ToString(tags[0].Key) != "processed" || aws.ToString(tags[0].Value) != "true" {
+		t.Errorf("Esperado tag processed=true, obtido %+v", tags)
+	}
+}
+
+func TestS3BlobStore_PutTagsEDisposition(t *testing.T) {
+	mockS3 := &mockS3ClientCaptura{}
+	store := NewS3BlobStore(mockS3)
+	opts := PutOptions{ContentDisposition: `attachment; filename="a.zip"`, Tags: map[string]string{"source-key": "videos/a b.mp4", "frame-count": "3"}}
+	store.Put(context.TODO(), "bucket", "a.zip", nil, opts)
+
+	if aws.ToString(mockS3.put.ContentDisposition) != `attachment; filename="a.zip"` {
+		t.Errorf("Esperado Content-Disposition repassado, obtido %+v", mockS3.put.ContentDisposition)
+	}
+	if tagging := aws.ToString(mockS3.put.Tagging); tagging != "frame-count=3&source-key=videos%2Fa+b.mp4" {
+		t.Errorf("Esperado tags em query string, obtido '%s'", tagging)
+	}
+}
+
+func TestS3BlobStore_PutCriptografiaEChecksum(t *testing.T) {
+	mockS3 := &mockS3ClientCaptura{}
+	store := NewS3BlobStore(mockS3)
+	opts := PutOptions{
+		StorageOptions: StorageOptions{Encryption: EncryptionKMS, KMSKeyID: "alias/zips", StorageClass: "STANDARD_IA", ChecksumAlgorithm: "SHA256"},
+		Checksum:       "c29tYQ==",
+	}
+	info, err := store.Put(context.TODO(), "bucket", "key", nil, opts)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	put := mockS3.put
+	if put.ServerSideEncryption != s3types.ServerSideEncryptionAwsKms || aws.ToString(put.SSEKMSKeyId) != "alias/zips" {
+		t.Errorf("Esperado SSE-KMS com a chave, obtido %v %v", put.ServerSideEncryption, aws.ToString(put.SSEKMSKeyId))
+	}
+	if put.StorageClass != s3types.StorageClassStandardIa || put.ChecksumAlgorithm != s3types.ChecksumAlgorithmSha256 || aws.ToString(put.ChecksumSHA256) != "c29tYQ==" {
+		t.Errorf("Esperado classe e checksum repassados, obtido %+v", put)
+	}
+	if info.StorageClass != "STANDARD_IA" || info.ChecksumAlgorithm != "SHA256" {
+		t.Errorf("Esperado classe e algoritmo no retorno, obtido %+v", info)
+	}
+}
+
+// Erro de API só com o código, como o SDK retorna em operações sem erros tipados
+type apiCodeError struct{ code string }
+
+func (e *apiCodeError) Error() string     { return e.code }
+func (e *apiCodeError) ErrorCode() string { return e.code }
+
+// Mock S3Client com as tags de um único objeto
+type mockS3ClientTags struct {
+	mockS3Client
+	key string
+}
+
+func (m *mockS3ClientTags) GetObjectTagging(ctx context.Context, input *s3.GetObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.GetObjectTaggingOutput, error) {
+	if aws.ToString(input.Key) != m.key {
+		return nil, &apiCodeError{code: "NoSuchKey"}
+	}
+	return &s3.GetObjectTaggingOutput{TagSet: []s3types.Tag{{Key: aws.String("processId"), Value: aws.String("proc-1")}}}, nil
+}
+
+func TestS3BlobStore_Tags(t *testing.T) {
+	store := NewS3BlobStore(&mockS3ClientTags{key: "uploads/proc-1/a.mp4"})
+
+	tags, err := store.Tags(context.TODO(), "videos", "uploads/proc-1/a.mp4")
+	if err != nil || tags["processId"] != "proc-1" {
+		t.Errorf("Esperado tag processId=proc-1, obtido %v (%v)", tags, err)
+	}
+	if _, err := store.Tags(context.TODO(), "videos", "inexistente.mp4"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Esperado ErrBlobNotFound, obtido %v", err)
+	}
+}
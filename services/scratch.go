@@ -0,0 +1,55 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"video-processor/utils"
+)
+
+// Diretórios de trabalho locais: vídeos recebidos ou baixados, ZIPs gerados e
+// frames temporários. Configuráveis para apontar para um volume dedicado
+type ScratchDirs struct {
+	Uploads string
+	Outputs string
+	Temp    string
+}
+
+var (
+	scratchMu sync.RWMutex
+	scratch   = ScratchDirs{Uploads: "uploads", Outputs: "outputs", Temp: "temp"}
+)
+
+// LoadScratchDirs lê os diretórios das variáveis UPLOADS_DIR, OUTPUTS_DIR e TEMP_DIR
+func LoadScratchDirs() ScratchDirs {
+	return ScratchDirs{
+		Uploads: filepath.Clean(utils.GetEnv("UPLOADS_DIR", "uploads")),
+		Outputs: filepath.Clean(utils.GetEnv("OUTPUTS_DIR", "outputs")),
+		Temp:    filepath.Clean(utils.GetEnv("TEMP_DIR", "temp")),
+	}
+}
+
+// InitScratchDirs cria os diretórios e passa a usá-los nos processamentos
+func InitScratchDirs(dirs ScratchDirs) error {
+	for _, dir := range dirs.roots() {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("erro ao criar diretório de trabalho %s: %w", dir, err)
+		}
+	}
+	scratchMu.Lock()
+	defer scratchMu.Unlock()
+	scratch = dirs
+	return nil
+}
+
+// Scratch retorna os diretórios de trabalho em uso
+func Scratch() ScratchDirs {
+	scratchMu.RLock()
+	defer scratchMu.RUnlock()
+	return scratch
+}
+
+func (d ScratchDirs) roots() []string {
+	return []string{d.Uploads, d.Outputs, d.Temp}
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadScratchDirs(t *testing.T) {
+	os.Setenv("OUTPUTS_DIR", "/scratch/outputs/")
+	defer os.Unsetenv("OUTPUTS_DIR")
+
+	dirs := LoadScratchDirs()
+	if dirs.Outputs != "/scratch/outputs" || dirs.Uploads != "uploads" || dirs.Temp != "temp" {
+		t.Errorf("Esperado OUTPUTS_DIR normalizado e padrões, obtido %+v", dirs)
+	}
+}
+
+func TestInitScratchDirs(t *testing.T) {
+	original := Scratch()
+	defer InitScratchDirs(original)
+
+	root := t.TempDir()
+	dirs := ScratchDirs{
+		Uploads: filepath.Join(root, "u"),
+		Outputs: filepath.Join(root, "o"),
+		Temp:    filepath.Join(root, "t"),
+	}
+	if err := InitScratchDirs(dirs); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	for _, dir := range dirs.roots() {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			t.Errorf("Esperado diretório %s criado", dir)
+		}
+	}
+	if Scratch() != dirs {
+		t.Errorf("Esperado %+v em uso, obtido %+v", dirs, Scratch())
+	}
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+)
+
+// Interface do client SNS para facilitar mocks nos testes
+type SNSClient interface {
+	Publish(ctx context.Context, input *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+// Tópico SNS de resultados (RESULTS_TOPIC_ARN). O status também vai como
+// atributo, permitindo filter policies nas assinaturas
+type snsResultPublisher struct {
+	client        SNSClient
+	topicARN      string
+	groupTemplate string
+}
+
+func (p *snsResultPublisher) Name() string { return ResultSinkSNS }
+
+func (p *snsResultPublisher) PublishResult(ctx context.Context, event ResultEvent) error {
+	attrs := map[string]snstypes.MessageAttributeValue{
+		"status": {DataType: aws.String("String"), StringValue: aws.String(event.Result.Status)},
+	}
+	if tc, ok := traceFrom(ctx); ok {
+		for name, value := range tc.messageAttributes() {
+			attrs[name] = snstypes.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+		}
+	}
+
+	input := &sns.PublishInput{
+		TopicArn:          aws.String(p.topicARN),
+		Message:           aws.String(string(event.Body)),
+		MessageAttributes: attrs,
+	}
+	// Tópicos FIFO exigem grupo e deduplicação, como as filas FIFO
+	if strings.HasSuffix(p.topicARN, ".fifo") {
+		input.MessageGroupId = aws.String(resultsGroupID(ctx, p.groupTemplate, event.Result.ProcessID))
+		input.MessageDeduplicationId = aws.String(resultDeduplicationID(event.Result.ProcessID, event.Result.Status, event.Sequence))
+	}
+
+	if _, err := p.client.Publish(ctx, input); err != nil {
+		return fmt.Errorf("erro ao publicar no tópico SNS: %w", err)
+	}
+	logf(ctx, "✅ Resultado publicado no tópico SNS")
+	return nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+type mockSNSClient struct {
+	inputs []*sns.PublishInput
+	err    error
+}
+
+func (m *mockSNSClient) Publish(ctx context.Context, input *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	m.inputs = append(m.inputs, input)
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &sns.PublishOutput{MessageId: aws.String("msg-1")}, nil
+}
+
+func TestSNSResultPublisher_Publica(t *testing.T) {
+	client := &mockSNSClient{}
+	p := &snsResultPublisher{client: client, topicARN: "arn:aws:sns:us-east-1:000000000000:results"}
+	ctx := withTrace(context.Background(), traceFromAttributes(map[string]string{CorrelationIDAttribute: "corr-1"}))
+
+	err := p.PublishResult(ctx, ResultEvent{Result: VideoProcessingResult{ProcessID: "proc-1", Status: "COMPLETED"}, Body: []byte(`{"status":"COMPLETED"}`)})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(client.inputs) != 1 {
+		t.Fatalf("Esperado 1 publicação, obtido %d", len(client.inputs))
+	}
+	input := client.inputs[0]
+	if aws.ToString(input.Message) != `{"status":"COMPLETED"}` {
+		t.Errorf("Corpo inesperado: %s", aws.ToString(input.Message))
+	}
+	if aws.ToString(input.MessageAttributes["status"].StringValue) != "COMPLETED" {
+		t.Error("Esperado atributo status")
+	}
+	if aws.ToString(input.MessageAttributes[CorrelationIDAttribute].StringValue) != "corr-1" {
+		t.Error("Esperado atributo de correlation id")
+	}
+	if input.MessageGroupId != nil {
+		t.Error("Esperado nenhum MessageGroupId em tópico padrão")
+	}
+}
+
+func TestSNSResultPublisher_TopicoFIFO(t *testing.T) {
+	client := &mockSNSClient{}
+	p := &snsResultPublisher{client: client, topicARN: "arn:aws:sns:us-east-1:000000000000:results.fifo"}
+	p.PublishResult(context.Background(), ResultEvent{Result: VideoProcessingResult{ProcessID: "proc-1", Status: "IN_PROGRESS"}, Sequence: 1})
+
+	input := client.inputs[0]
+	if aws.ToString(input.MessageGroupId) != "proc-1" {
+		t.Errorf("Esperado MessageGroupId 'proc-1', obtido '%s'", aws.ToString(input.MessageGroupId))
+	}
+	if aws.ToString(input.MessageDeduplicationId) != resultDeduplicationID("proc-1", "IN_PROGRESS", 1) {
+		t.Error("Esperado MessageDeduplicationId determinístico")
+	}
+}
+
+func TestSNSResultPublisher_Erro(t *testing.T) {
+	p := &snsResultPublisher{client: &mockSNSClient{err: errors.New("throttled")}, topicARN: "arn"}
+	if err := p.PublishResult(context.Background(), ResultEvent{}); err == nil {
+		t.Error("Esperado erro ao publicar no SNS")
+	}
+}
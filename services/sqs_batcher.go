@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// Limite de entradas por chamada *Batch do SQS
+const sqsMaxBatchSize = 10
+
+// Número de tentativas para entradas que falharam por erro do lado do SQS
+const sqsBatchMaxAttempts = 3
+
+// Espera antes de reenviar entradas que falharam, dobrada a cada tentativa:
+// evita insistir imediatamente em uma fila com throttling
+var sqsBatchRetryBackoff = 200 * time.Millisecond
+
+// Resultado de uma entrada enviada em lote: nil em caso de sucesso
+type batchEntryError struct {
+	err       error
+	retryable bool
+}
+
+// Agrupa operações do SQS e as envia em lote quando atinge o tamanho
+// máximo ou quando o intervalo de flush expira, o que ocorrer primeiro
+type sqsBatcher[T any] struct {
+	name     string
+	maxSize  int
+	interval time.Duration
+	send     func(ctx context.Context, entries []T) []*batchEntryError
+
+	mu      sync.Mutex
+	pending []*pendingEntry[T]
+	timer   *time.Timer
+	flushes sync.WaitGroup
+}
+
+type pendingEntry[T any] struct {
+	value    T
+	attempts int
+	done     chan error
+}
+
+func newSQSBatcher[T any](name string, maxSize int, interval time.Duration, send func(ctx context.Context, entries []T) []*batchEntryError) *sqsBatcher[T] {
+	if maxSize <= 0 || maxSize > sqsMaxBatchSize {
+		maxSize = sqsMaxBatchSize
+	}
+	return &sqsBatcher[T]{
+		name:     name,
+		maxSize:  maxSize,
+		interval: interval,
+		send:     send,
+	}
+}
+
+// Add enfileira uma entrada. O canal retornado recebe o resultado final
+// da entrada (após as novas tentativas) e pode ser ignorado pelo chamador.
+func (b *sqsBatcher[T]) Add(value T) <-chan error {
+	entry := &pendingEntry[T]{value: value, done: make(chan error, 1)}
+	b.enqueue(false, entry)
+	return entry.done
+}
+
+// Adiciona entradas ao fim da fila pendente ou, para novas tentativas, ao início,
+// para que sejam enviadas antes das entradas mais recentes
+func (b *sqsBatcher[T]) enqueue(front bool, entries ...*pendingEntry[T]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if front {
+		b.pending = append(append([]*pendingEntry[T](nil), entries...), b.pending...)
+	} else {
+		b.pending = append(b.pending, entries...)
+	}
+	for len(b.pending) >= b.maxSize {
+		batch := b.pending[:b.maxSize]
+		b.pending = append([]*pendingEntry[T](nil), b.pending[b.maxSize:]...)
+		b.dispatch(batch)
+	}
+
+	if len(b.pending) > 0 && b.timer == nil {
+		b.timer = time.AfterFunc(b.interval, b.flushPending)
+	}
+}
+
+// Flush do que estiver pendente quando o intervalo expira
+func (b *sqsBatcher[T]) flushPending() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.timer = nil
+	if len(b.pending) == 0 {
+		return
+	}
+	batch := b.pending
+	b.pending = nil
+	b.dispatch(batch)
+}
+
+// Envia o lote em background; deve ser chamado com b.mu travado
+func (b *sqsBatcher[T]) dispatch(batch []*pendingEntry[T]) {
+	b.flushes.Add(1)
+	go func() {
+		defer b.flushes.Done()
+		b.flush(batch)
+	}()
+}
+
+func (b *sqsBatcher[T]) flush(batch []*pendingEntry[T]) {
+	values := make([]T, len(batch))
+	for i, entry := range batch {
+		values[i] = entry.value
+	}
+
+	results := b.send(context.Background(), values)
+
+	var retry []*pendingEntry[T]
+	attempts := 0
+	for i, entry := range batch {
+		entry.attempts++
+		result := results[i]
+		if result == nil {
+			entry.done <- nil
+			continue
+		}
+		if result.retryable && entry.attempts < sqsBatchMaxAttempts {
+			retry = append(retry, entry)
+			attempts = max(attempts, entry.attempts)
+			continue
+		}
+		log.Printf("❌ Falha definitiva em entrada do lote %s: %v", b.name, result.err)
+		entry.done <- result.err
+	}
+
+	if len(retry) > 0 {
+		delay := sqsBatchRetryBackoff << (attempts - 1)
+		log.Printf("🔁 Reenfileirando %d entrada(s) do lote %s em %v", len(retry), b.name, delay)
+		time.Sleep(delay)
+		b.enqueue(true, retry...)
+	}
+}
+
+// Close envia as entradas pendentes e aguarda os lotes em andamento até o prazo do ctx
+func (b *sqsBatcher[T]) Close(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		// Novas tentativas podem reenfileirar entradas durante o flush
+		for {
+			b.mu.Lock()
+			if b.timer != nil {
+				b.timer.Stop()
+				b.timer = nil
+			}
+			if len(b.pending) > 0 {
+				batch := b.pending
+				b.pending = nil
+				b.dispatch(batch)
+			}
+			b.mu.Unlock()
+
+			b.flushes.Wait()
+
+			b.mu.Lock()
+			empty := len(b.pending) == 0
+			b.mu.Unlock()
+			if empty {
+				close(done)
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("lote %s não finalizado: %w", b.name, ctx.Err())
+	}
+}
+
+// Converte a resposta de uma operação *Batch em resultados por entrada
+func batchResults(size int, failed []types.BatchResultErrorEntry, callErr error) []*batchEntryError {
+	results := make([]*batchEntryError, size)
+	if callErr != nil {
+		for i := range results {
+			results[i] = &batchEntryError{err: callErr, retryable: true}
+		}
+		return results
+	}
+	for _, f := range failed {
+		i, err := strconv.Atoi(aws.ToString(f.Id))
+		if err != nil || i < 0 || i >= size {
+			continue
+		}
+		results[i] = &batchEntryError{
+			err:       fmt.Errorf("%s: %s", aws.ToString(f.Code), aws.ToString(f.Message)),
+			retryable: !f.SenderFault,
+		}
+	}
+	return results
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// Mock SQSClient que registra as chamadas em lote
+type mockSQSClientBatch struct {
+	mockSQSClient
+	mu          sync.Mutex
+	deleteCalls [][]types.DeleteMessageBatchRequestEntry
+	sendCalls   [][]types.SendMessageBatchRequestEntry
+	failFirst   map[string]bool // entradas (por corpo) que falham na primeira tentativa
+	senderFault bool
+	failCalls   bool // toda chamada falha com erro do serviço
+}
+
+func (m *mockSQSClientBatch) DeleteMessageBatch(ctx context.Context, input *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleteCalls = append(m.deleteCalls, input.Entries)
+	return &sqs.DeleteMessageBatchOutput{}, nil
+}
+
+func (m *mockSQSClientBatch) SendMessageBatch(ctx context.Context, input *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sendCalls = append(m.sendCalls, input.Entries)
+	if m.failCalls {
+		return nil, &httpStatusError{code: 503}
+	}
+
+	out := &sqs.SendMessageBatchOutput{}
+	for _, entry := range input.Entries {
+		body := aws.ToString(entry.MessageBody)
+		if m.failFirst[body] {
+			delete(m.failFirst, body)
+			out.Failed = append(out.Failed, types.BatchResultErrorEntry{
+				Id:          entry.Id,
+				Code:        aws.String("InternalError"),
+				SenderFault: m.senderFault,
+			})
+		}
+	}
+	return out, nil
+}
+
+// Instala um lote de exclusão na fila de entrada padrão do processador
+func newTestDeleteBatcher(mp *MessageProcessor, size int, interval time.Duration) *sqsBatcher[types.Message] {
+	queue := mp.getQueues()[0]
+	backend := &sqsQueue{client: mp.sqsClient, url: queue.url}
+	backend.deleteBatcher = newSQSBatcher("delete", size, interval, backend.sendDeleteBatch)
+	queue.backend = backend
+	return backend.deleteBatcher
+}
+
+func TestSQSBatcher_FlushPorTamanho(t *testing.T) {
+	mockSQS := &mockSQSClientBatch{}
+	mp := &MessageProcessor{config: MessageProcessorConfig{SQSQueueURL: "url"}, sqsClient: mockSQS}
+	batcher := newTestDeleteBatcher(mp, 2, time.Hour)
+
+	first := batcher.Add(types.Message{MessageId: ptr("id1"), ReceiptHandle: ptr("rh1")})
+	second := batcher.Add(types.Message{MessageId: ptr("id2"), ReceiptHandle: ptr("rh2")})
+
+	if err := <-first; err != nil {
+		t.Errorf("Esperado nil, obtido %v", err)
+	}
+	if err := <-second; err != nil {
+		t.Errorf("Esperado nil, obtido %v", err)
+	}
+	if len(mockSQS.deleteCalls) != 1 || len(mockSQS.deleteCalls[0]) != 2 {
+		t.Errorf("Esperado 1 chamada com 2 entradas, obtido %v", mockSQS.deleteCalls)
+	}
+}
+
+func TestSQSBatcher_FlushPorTempo(t *testing.T) {
+	mockSQS := &mockSQSClientBatch{}
+	mp := &MessageProcessor{config: MessageProcessorConfig{SQSQueueURL: "url"}, sqsClient: mockSQS}
+	batcher := newTestDeleteBatcher(mp, 10, 10*time.Millisecond)
+
+	done := batcher.Add(types.Message{MessageId: ptr("id1"), ReceiptHandle: ptr("rh1")})
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Esperado nil, obtido %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Esperado flush após o intervalo")
+	}
+}
+
+func TestSQSBatcher_FalhaParcialReenviaEntrada(t *testing.T) {
+	mockSQS := &mockSQSClientBatch{failFirst: map[string]bool{"b": true}}
+	q := &sqsQueue{client: mockSQS, url: "url"}
+	batcher := newSQSBatcher("results", 2, time.Millisecond, q.sendMessageBatch)
+
+	a := batcher.Add(types.SendMessageBatchRequestEntry{MessageBody: aws.String("a")})
+	b := batcher.Add(types.SendMessageBatchRequestEntry{MessageBody: aws.String("b")})
+
+	if err := <-a; err != nil {
+		t.Errorf("Esperado nil para entrada 'a', obtido %v", err)
+	}
+	if err := <-b; err != nil {
+		t.Errorf("Esperado sucesso na nova tentativa da entrada 'b', obtido %v", err)
+	}
+	if len(mockSQS.sendCalls) != 2 {
+		t.Errorf("Esperado 2 chamadas (lote + nova tentativa), obtido %d", len(mockSQS.sendCalls))
+	}
+}
+
+func TestSQSBatcher_FalhaDoRemetenteNaoReenvia(t *testing.T) {
+	mockSQS := &mockSQSClientBatch{failFirst: map[string]bool{"a": true}, senderFault: true}
+	q := &sqsQueue{client: mockSQS, url: "url"}
+	batcher := newSQSBatcher("results", 1, time.Hour, q.sendMessageBatch)
+
+	if err := <-batcher.Add(types.SendMessageBatchRequestEntry{MessageBody: aws.String("a")}); err == nil {
+		t.Error("Esperado erro definitivo para falha do remetente")
+	}
+}
+
+func TestSQSBatcher_CloseEnviaPendentes(t *testing.T) {
+	mockSQS := &mockSQSClientBatch{}
+	mp := &MessageProcessor{config: MessageProcessorConfig{SQSQueueURL: "url"}, sqsClient: mockSQS}
+	newTestDeleteBatcher(mp, 10, time.Hour)
+
+	mp.deleteMessage(context.TODO(), QueueMessage{ID: "id1", Handle: "rh1"})
+	if err := mp.flushBatches(context.TODO()); err != nil {
+		t.Errorf("Esperado nil, obtido %v", err)
+	}
+	if len(mockSQS.deleteCalls) != 1 {
+		t.Errorf("Esperado 1 chamada de exclusão em lote, obtido %d", len(mockSQS.deleteCalls))
+	}
+}
+
+func TestBatchResults_ErroNaChamada(t *testing.T) {
+	results := batchResults(2, nil, errors.New("erro simulado"))
+	for i, result := range results {
+		if result == nil || !result.retryable {
+			t.Errorf("Esperado erro reenviável na entrada %d", i)
+		}
+	}
+}
+
+func TestSQSBatcher_NovaTentativaNoInicioComEspera(t *testing.T) {
+	original := sqsBatchRetryBackoff
+	sqsBatchRetryBackoff = 50 * time.Millisecond
+	defer func() { sqsBatchRetryBackoff = original }()
+
+	mockSQS := &mockSQSClientBatch{failFirst: map[string]bool{"a": true}}
+	q := &sqsQueue{client: mockSQS, url: "url"}
+	batcher := newSQSBatcher("results", 2, time.Hour, q.sendMessageBatch)
+
+	start := time.Now()
+	a := batcher.Add(types.SendMessageBatchRequestEntry{MessageBody: aws.String("a")})
+	batcher.Add(types.SendMessageBatchRequestEntry{MessageBody: aws.String("b")})
+	c := batcher.Add(types.SendMessageBatchRequestEntry{MessageBody: aws.String("c")})
+
+	if err := <-a; err != nil {
+		t.Fatalf("Esperado sucesso na nova tentativa, obtido %v", err)
+	}
+	<-c
+	if elapsed := time.Since(start); elapsed < sqsBatchRetryBackoff {
+		t.Errorf("Esperado espera de ao menos %v antes da nova tentativa, obtido %v", sqsBatchRetryBackoff, elapsed)
+	}
+
+	mockSQS.mu.Lock()
+	defer mockSQS.mu.Unlock()
+	if len(mockSQS.sendCalls) != 2 {
+		t.Fatalf("Esperado 2 chamadas, obtido %d", len(mockSQS.sendCalls))
+	}
+	retried := mockSQS.sendCalls[1]
+	if len(retried) != 2 || aws.ToString(retried[0].MessageBody) != "a" || aws.ToString(retried[1].MessageBody) != "c" {
+		t.Errorf("Esperado entrada reenviada antes das mais recentes, obtido %v", retried)
+	}
+}
+
+func TestSQSBatcher_UmaCamadaDeNovasTentativas(t *testing.T) {
+	original := sqsBatchRetryBackoff
+	sqsBatchRetryBackoff = time.Millisecond
+	defer func() { sqsBatchRetryBackoff = original }()
+
+	mockSQS := &mockSQSClientBatch{failCalls: true}
+	q := &sqsQueue{client: newResilientSQSClient(mockSQS, testRetryPolicy, nil), url: "url"}
+	batcher := newSQSBatcher("results", 1, time.Hour, q.sendMessageBatch)
+
+	if err := <-batcher.Add(types.SendMessageBatchRequestEntry{MessageBody: aws.String("a")}); err == nil {
+		t.Error("Esperado erro após esgotar as tentativas")
+	}
+	mockSQS.mu.Lock()
+	defer mockSQS.mu.Unlock()
+	if len(mockSQS.sendCalls) != sqsBatchMaxAttempts {
+		t.Errorf("Esperado %d chamadas (só as tentativas do lote), obtido %d", sqsBatchMaxAttempts, len(mockSQS.sendCalls))
+	}
+}
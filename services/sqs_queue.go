@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// Fila SQS. Com lotes habilitados (batchSize > 1), Ack e Publish são
+// assíncronos e as falhas são tratadas por entrada no lote. Em filas FIFO
+// o envio nunca usa lotes: lotes paralelos e novas tentativas poderiam
+// entregar fora de ordem os eventos de um mesmo MessageGroupId.
+type sqsQueue struct {
+	client     SQSClient
+	url        string
+	visibility time.Duration // Pedida no ReceiveMessage, para casar com a renovação (0 = a da fila)
+
+	deleteBatcher *sqsBatcher[types.Message]
+	sendBatcher   *sqsBatcher[types.SendMessageBatchRequestEntry]
+}
+
+func newSQSQueue(client SQSClient, url string, visibility time.Duration, batchSize int, flushInterval time.Duration) *sqsQueue {
+	q := &sqsQueue{client: client, url: url, visibility: visibility}
+	if batchSize > 1 {
+		name := queueName(url)
+		q.deleteBatcher = newSQSBatcher("delete:"+name, batchSize, flushInterval, q.sendDeleteBatch)
+		if !isFIFOQueue(url) {
+			q.sendBatcher = newSQSBatcher("send:"+name, batchSize, flushInterval, q.sendMessageBatch)
+		}
+	}
+	return q
+}
+
+func (q *sqsQueue) Receive(ctx context.Context, max int32, wait time.Duration) ([]QueueMessage, error) {
+	resp, err := q.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:              aws.String(q.url),
+		MaxNumberOfMessages:   max,
+		WaitTimeSeconds:       int32(wait / time.Second), // Long polling
+		MessageAttributeNames: []string{"All"},
+		VisibilityTimeout:     int32(q.visibility / time.Second),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]QueueMessage, len(resp.Messages))
+	for i, m := range resp.Messages {
+		messages[i] = QueueMessage{
+			ID:         aws.ToString(m.MessageId),
+			Body:       aws.ToString(m.Body),
+			Attributes: fromSQSAttributes(m.MessageAttributes),
+			Handle:     aws.ToString(m.ReceiptHandle),
+		}
+	}
+	return messages, nil
+}
+
+func (q *sqsQueue) Ack(ctx context.Context, msg QueueMessage) error {
+	message := types.Message{MessageId: aws.String(msg.ID), ReceiptHandle: aws.String(msg.Handle)}
+	if q.deleteBatcher != nil {
+		q.deleteBatcher.Add(message)
+		return nil
+	}
+
+	_, err := q.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(q.url),
+		ReceiptHandle: message.ReceiptHandle,
+	})
+	return err
+}
+
+func (q *sqsQueue) Nack(ctx context.Context, msg QueueMessage) error {
+	return q.ExtendVisibility(ctx, msg, 0)
+}
+
+func (q *sqsQueue) ExtendVisibility(ctx context.Context, msg QueueMessage, timeout time.Duration) error {
+	_, err := q.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(q.url),
+		ReceiptHandle:     aws.String(msg.Handle),
+		VisibilityTimeout: int32(timeout / time.Second),
+	})
+	return err
+}
+
+func (q *sqsQueue) Publish(ctx context.Context, msg OutgoingMessage) error {
+	_, err := q.publish(ctx, msg)
+	return err
+}
+
+// PublishConfirmed aguarda o resultado do lote (após as novas tentativas)
+func (q *sqsQueue) PublishConfirmed(ctx context.Context, msg OutgoingMessage) error {
+	done, err := q.publish(ctx, msg)
+	if err != nil || done == nil {
+		return err
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Envia a mensagem ou a adiciona ao lote; no segundo caso retorna o canal com o resultado
+func (q *sqsQueue) publish(ctx context.Context, msg OutgoingMessage) (<-chan error, error) {
+	input := &sqs.SendMessageInput{
+		QueueUrl:          aws.String(q.url),
+		MessageBody:       aws.String(msg.Body),
+		MessageAttributes: toSQSAttributes(msg.Attributes),
+	}
+	// Adiciona MessageGroupId e MessageDeduplicationId se a fila for FIFO
+	if isFIFOQueue(q.url) {
+		input.MessageGroupId = aws.String(msg.GroupID)
+		input.MessageDeduplicationId = aws.String(msg.DeduplicationID)
+	}
+
+	if q.sendBatcher != nil {
+		return q.sendBatcher.Add(types.SendMessageBatchRequestEntry{
+			MessageBody:            input.MessageBody,
+			MessageGroupId:         input.MessageGroupId,
+			MessageDeduplicationId: input.MessageDeduplicationId,
+			MessageAttributes:      input.MessageAttributes,
+		}), nil
+	}
+
+	_, err := q.client.SendMessage(ctx, input)
+	return nil, err
+}
+
+// Enviar exclusões e mensagens ainda pendentes nos lotes
+func (q *sqsQueue) Close(ctx context.Context) error {
+	if q.deleteBatcher != nil {
+		if err := q.deleteBatcher.Close(ctx); err != nil {
+			return err
+		}
+	}
+	if q.sendBatcher != nil {
+		if err := q.sendBatcher.Close(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Envio em lote de DeleteMessage
+func (q *sqsQueue) sendDeleteBatch(ctx context.Context, entries []types.Message) []*batchEntryError {
+	input := &sqs.DeleteMessageBatchInput{
+		QueueUrl: aws.String(q.url),
+		Entries:  make([]types.DeleteMessageBatchRequestEntry, len(entries)),
+	}
+	for i, message := range entries {
+		input.Entries[i] = types.DeleteMessageBatchRequestEntry{
+			Id:            aws.String(strconv.Itoa(i)),
+			ReceiptHandle: message.ReceiptHandle,
+		}
+	}
+
+	resp, err := q.client.DeleteMessageBatch(ctx, input)
+	var failed []types.BatchResultErrorEntry
+	if resp != nil {
+		failed = resp.Failed
+	}
+	results := batchResults(len(entries), failed, err)
+
+	deleted := 0
+	for i, result := range results {
+		if result == nil {
+			deleted++
+			continue
+		}
+		log.Printf("❌ Erro ao deletar mensagem %s: %v", aws.ToString(entries[i].MessageId), result.err)
+	}
+	log.Printf("🗑️  Lote de exclusão: %d/%d mensagem(s) deletada(s)", deleted, len(entries))
+	return results
+}
+
+// Envio em lote de SendMessage
+func (q *sqsQueue) sendMessageBatch(ctx context.Context, entries []types.SendMessageBatchRequestEntry) []*batchEntryError {
+	input := &sqs.SendMessageBatchInput{
+		QueueUrl: aws.String(q.url),
+		Entries:  make([]types.SendMessageBatchRequestEntry, len(entries)),
+	}
+	for i, entry := range entries {
+		entry.Id = aws.String(strconv.Itoa(i))
+		input.Entries[i] = entry
+	}
+
+	resp, err := q.client.SendMessageBatch(ctx, input)
+	var failed []types.BatchResultErrorEntry
+	if resp != nil {
+		failed = resp.Failed
+	}
+	results := batchResults(len(entries), failed, err)
+
+	sent := 0
+	for _, result := range results {
+		if result == nil {
+			sent++
+			continue
+		}
+		log.Printf("❌ Erro ao enviar mensagem em lote: %v", result.err)
+	}
+	log.Printf("📨 Lote de envio: %d/%d mensagem(s) enviada(s)", sent, len(entries))
+	return results
+}
+
+// Atributos String do SQS; atributos de outros tipos são ignorados
+func fromSQSAttributes(attrs map[string]types.MessageAttributeValue) map[string]string {
+	if len(attrs) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(attrs))
+	for name, value := range attrs {
+		if value.StringValue != nil {
+			values[name] = aws.ToString(value.StringValue)
+		}
+	}
+	return values
+}
+
+func toSQSAttributes(attrs map[string]string) map[string]types.MessageAttributeValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	values := make(map[string]types.MessageAttributeValue, len(attrs))
+	for name, value := range attrs {
+		values[name] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+	}
+	return values
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+func TestSQSQueue_ReceiveConverteMensagens(t *testing.T) {
+	mockSQS := &mockSQSClient{messages: []types.Message{{
+		MessageId:     ptr("id1"),
+		ReceiptHandle: ptr("rh1"),
+		Body:          ptr("corpo"),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			"correlationId": {DataType: aws.String("String"), StringValue: aws.String("c1")},
+			"binario":       {DataType: aws.String("Binary"), BinaryValue: []byte{1}},
+		},
+	}}}
+	q := newSQSQueue(mockSQS, "url", 0, 1, 0)
+
+	messages, err := q.Receive(context.TODO(), 10, time.Second)
+	if err != nil || len(messages) != 1 {
+		t.Fatalf("Esperado 1 mensagem, obtido %d (%v)", len(messages), err)
+	}
+	msg := messages[0]
+	if msg.ID != "id1" || msg.Handle != "rh1" || msg.Body != "corpo" || msg.Attributes["correlationId"] != "c1" {
+		t.Errorf("Mensagem inesperada: %+v", msg)
+	}
+	if _, ok := msg.Attributes["binario"]; ok {
+		t.Error("Esperado atributo não textual ignorado")
+	}
+}
+
+func TestSQSQueue_ReceivePedeVisibilidadeConfigurada(t *testing.T) {
+	mockSQS := &mockSQSClient{}
+	newSQSQueue(mockSQS, "url", 90*time.Second, 1, 0).Receive(context.TODO(), 10, time.Second)
+	if mockSQS.received == nil || mockSQS.received.VisibilityTimeout != 90 {
+		t.Errorf("Esperado VisibilityTimeout de 90s no ReceiveMessage, obtido %+v", mockSQS.received)
+	}
+
+	// Sem visibilidade configurada, vale a da fila
+	newSQSQueue(mockSQS, "url", 0, 1, 0).Receive(context.TODO(), 10, time.Second)
+	if mockSQS.received.VisibilityTimeout != 0 {
+		t.Errorf("Esperado VisibilityTimeout omitido, obtido %d", mockSQS.received.VisibilityTimeout)
+	}
+}
+
+func TestSQSQueue_NackZeraVisibilidade(t *testing.T) {
+	mockSQS := &mockSQSClient{}
+	q := newSQSQueue(mockSQS, "url", 0, 1, 0)
+	q.Nack(context.TODO(), QueueMessage{ID: "id1", Handle: "rh1"})
+	if len(mockSQS.released) != 1 || mockSQS.released[0] != "rh1" {
+		t.Errorf("Esperado rh1 devolvida, obtido %v", mockSQS.released)
+	}
+}
+
+func TestSQSQueue_PublishFIFO(t *testing.T) {
+	mockSQS := &mockSQSClientCaptura{}
+	q := newSQSQueue(mockSQS, "https://sqs/000/resultados.fifo", 0, 1, 0)
+	q.Publish(context.TODO(), OutgoingMessage{Body: "a", GroupID: "g", DeduplicationID: "d"})
+	if aws.ToString(mockSQS.sent.MessageGroupId) != "g" || aws.ToString(mockSQS.sent.MessageDeduplicationId) != "d" {
+		t.Errorf("Esperado group/dedup em fila FIFO, obtido %+v", mockSQS.sent)
+	}
+
+	q = newSQSQueue(mockSQS, "https://sqs/000/resultados", 0, 1, 0)
+	q.Publish(context.TODO(), OutgoingMessage{Body: "a", GroupID: "g", DeduplicationID: "d"})
+	if mockSQS.sent.MessageGroupId != nil {
+		t.Error("Esperado sem MessageGroupId em fila padrão")
+	}
+}
+
+func TestSQSQueue_FIFONaoEnviaEmLote(t *testing.T) {
+	q := newSQSQueue(&mockSQSClientCaptura{}, "https://sqs/000/resultados.fifo", 0, 10, time.Second)
+	if q.sendBatcher != nil {
+		t.Error("Esperado envio sem lote em fila FIFO")
+	}
+	if q.deleteBatcher == nil {
+		t.Error("Esperado exclusão em lote mantida em fila FIFO")
+	}
+}
+
+func TestSQSQueue_PublishConfirmedAguardaLote(t *testing.T) {
+	mockSQS := &mockSQSClientBatch{failFirst: map[string]bool{"falha": true}, senderFault: true}
+	q := newSQSQueue(mockSQS, "url", 0, 10, 10*time.Millisecond)
+
+	if err := q.PublishConfirmed(context.TODO(), OutgoingMessage{Body: "ok"}); err != nil {
+		t.Errorf("Esperado envio confirmado, obtido %v", err)
+	}
+	if err := q.PublishConfirmed(context.TODO(), OutgoingMessage{Body: "falha"}); err == nil {
+		t.Error("Esperado erro para entrada recusada pelo lote")
+	}
+}
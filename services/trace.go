@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// Nomes dos atributos de mensagem usados para propagar o contexto do job
+const (
+	CorrelationIDAttribute = "correlationId"
+	TraceParentAttribute   = "traceparent"
+)
+
+// Nomes alternativos aceitos para o correlation ID na mensagem de entrada
+var correlationIDAliases = []string{CorrelationIDAttribute, "correlation-id", "X-Correlation-Id"}
+
+// Formato W3C: versão-traceId-parentId-flags
+var traceParentPattern = regexp.MustCompile(`^([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+
+// Contexto de rastreamento de um job
+type TraceContext struct {
+	CorrelationID string
+	TraceID       string
+	SpanID        string
+	Flags         string
+}
+
+type traceContextKey struct{}
+
+// Lê correlation ID e traceparent dos atributos da mensagem, gerando os que
+// estiverem ausentes. O job recebe um novo span filho do traceparent recebido.
+func traceFromAttributes(attrs map[string]string) TraceContext {
+	tc := TraceContext{Flags: "01"}
+
+	for _, name := range correlationIDAliases {
+		if value := attrs[name]; value != "" {
+			tc.CorrelationID = value
+			break
+		}
+	}
+
+	if m := traceParentPattern.FindStringSubmatch(strings.ToLower(attrs[TraceParentAttribute])); m != nil && m[2] != strings.Repeat("0", 32) {
+		tc.TraceID = m[2]
+		tc.Flags = m[4]
+	}
+
+	if tc.TraceID == "" {
+		tc.TraceID = randomHex(16)
+	}
+	tc.SpanID = randomHex(8)
+	if tc.CorrelationID == "" {
+		tc.CorrelationID = newUUID()
+	}
+	return tc
+}
+
+// Valor do header/atributo traceparent para chamadas feitas pelo job
+func (tc TraceContext) TraceParent() string {
+	return fmt.Sprintf("00-%s-%s-%s", tc.TraceID, tc.SpanID, tc.Flags)
+}
+
+// Atributos anexados às mensagens de resultado
+func (tc TraceContext) messageAttributes() map[string]string {
+	return map[string]string{
+		CorrelationIDAttribute: tc.CorrelationID,
+		TraceParentAttribute:   tc.TraceParent(),
+	}
+}
+
+// Metadados anexados aos objetos enviados ao S3 (x-amz-meta-*)
+func (tc TraceContext) objectMetadata() map[string]string {
+	return map[string]string{
+		"correlation-id": tc.CorrelationID,
+		"traceparent":    tc.TraceParent(),
+	}
+}
+
+func withTrace(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, tc)
+}
+
+func traceFrom(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	return tc, ok
+}
+
+// logf registra a linha de log com o correlation ID e o trace ID do job, se houver
+func logf(ctx context.Context, format string, args ...interface{}) {
+	if tc, ok := traceFrom(ctx); ok {
+		format = fmt.Sprintf("[cid=%s trace=%s] ", tc.CorrelationID, tc.TraceID) + format
+	}
+	log.Printf(format, args...)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func newUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+func TestTraceFromAttributes_Propaga(t *testing.T) {
+	tc := traceFromAttributes(map[string]string{
+		"X-Correlation-Id": "corr-123",
+		"traceparent":      "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	})
+	if tc.CorrelationID != "corr-123" {
+		t.Errorf("Esperado 'corr-123', obtido '%s'", tc.CorrelationID)
+	}
+	if tc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Esperado trace ID propagado, obtido '%s'", tc.TraceID)
+	}
+	if tc.SpanID == "00f067aa0ba902b7" {
+		t.Error("Esperado novo span ID para o job")
+	}
+	if !traceParentPattern.MatchString(tc.TraceParent()) {
+		t.Errorf("traceparent inválido: %s", tc.TraceParent())
+	}
+}
+
+func TestTraceFromAttributes_GeraQuandoAusente(t *testing.T) {
+	tc := traceFromAttributes(map[string]string{
+		"traceparent": "invalido",
+	})
+	if tc.CorrelationID == "" || len(tc.TraceID) != 32 || len(tc.SpanID) != 16 {
+		t.Errorf("Esperado contexto gerado, obtido %+v", tc)
+	}
+	other := traceFromAttributes(nil)
+	if other.CorrelationID == tc.CorrelationID || other.TraceID == tc.TraceID {
+		t.Error("Esperado IDs diferentes para jobs diferentes")
+	}
+}
+
+// Mock SQSClient que captura a mensagem enviada
+type mockSQSClientCaptura struct {
+	mockSQSClient
+	sent *sqs.SendMessageInput
+}
+
+func (m *mockSQSClientCaptura) SendMessage(ctx context.Context, input *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	m.sent = input
+	return &sqs.SendMessageOutput{}, nil
+}
+
+func TestSendProcessingResult_AtributosDeRastreamento(t *testing.T) {
+	mockSQS := &mockSQSClientCaptura{}
+	mp := &MessageProcessor{config: MessageProcessorConfig{ResultsQueueURL: "url"}, sqsClient: mockSQS}
+	tc := traceFromAttributes(map[string]string{CorrelationIDAttribute: "corr-1"})
+
+	if err := mp.SendProcessingResult(withTrace(context.TODO(), tc), "proc-1", "", "IN_PROGRESS"); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	attrs := mockSQS.sent.MessageAttributes
+	if aws.ToString(attrs[CorrelationIDAttribute].StringValue) != "corr-1" {
+		t.Errorf("Esperado correlationId 'corr-1', obtido %v", attrs)
+	}
+	if aws.ToString(attrs[TraceParentAttribute].StringValue) != tc.TraceParent() {
+		t.Errorf("Esperado traceparent '%s', obtido %v", tc.TraceParent(), attrs)
+	}
+}
+
+// Mock S3Client que captura o objeto enviado
+type mockS3ClientCaptura struct {
+	mockS3Client
+	put *s3.PutObjectInput
+}
+
+func (m *mockS3ClientCaptura) PutObject(ctx context.Context, input *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	m.put = input
+	return &s3.PutObjectOutput{}, nil
+}
+
+func TestUploadZipToS3_MetadadosDeRastreamento(t *testing.T) {
+	mockS3 := &mockS3ClientCaptura{}
+	mp := &MessageProcessor{s3Client: mockS3}
+	zipPath := "outputs/teste_trace.zip"
+	os.MkdirAll("outputs", 0755)
+	os.WriteFile(zipPath, []byte("conteudo"), 0644)
+	defer os.Remove(zipPath)
+
+	tc := traceFromAttributes(nil)
+	if err := mp.UploadZipToS3(withTrace(context.TODO(), tc), "bucket", "key", zipPath); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if mockS3.put.Metadata["correlation-id"] != tc.CorrelationID {
+		t.Errorf("Esperado metadado correlation-id, obtido %v", mockS3.put.Metadata)
+	}
+	if !strings.HasPrefix(mockS3.put.Metadata["traceparent"], "00-"+tc.TraceID) {
+		t.Errorf("Esperado metadado traceparent, obtido %v", mockS3.put.Metadata)
+	}
+}
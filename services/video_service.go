@@ -2,24 +2,56 @@ package services
 
 import (
 	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"video-processor/models"
 )
 
-func ProcessVideo(videoPath, timestamp string) models.ProcessingResult {
+// Formato das imagens extraídas (placeholder {format} das chaves de resultado)
+const frameFormat = "png"
+
+func ProcessVideo(videoPath, jobID string) models.ProcessingResult {
+	return ProcessVideoContext(context.Background(), videoPath, jobID)
+}
+
+// ProcessVideoContext extrai os frames no workspace do job (ver NewWorkspace) e
+// grava o ZIP frames_<jobID>.zip em OUTPUTS_DIR ou, nos jobs do worker, no
+// próprio workspace até o upload. Interrompe o ffmpeg e descarta o ZIP parcial
+// se o ctx for cancelado
+func ProcessVideoContext(ctx context.Context, videoPath, jobID string) models.ProcessingResult {
 	fmt.Printf("Iniciando processamento: %s\n", videoPath)
 
-	tempDir := filepath.Join("temp", timestamp)
+	zipDir := Scratch().Outputs
+	ws, ok := workspaceFrom(ctx)
+	if ok {
+		zipDir = ws.Dir
+	} else {
+		var err error
+		if ws, err = NewWorkspace(jobID); err != nil {
+			return models.ProcessingResult{
+				Success: false,
+				Message: err.Error(),
+			}
+		}
+		defer ws.Cleanup()
+	}
+
+	tempDir := ws.Path("frames")
 	os.MkdirAll(tempDir, 0755)
 	defer os.RemoveAll(tempDir)
 
-	framePattern := filepath.Join(tempDir, "frame_%04d.png")
+	framePattern := filepath.Join(tempDir, "frame_%04d."+frameFormat)
 
-	cmd := exec.Command("ffmpeg",
+	cmd := exec.CommandContext(ctx, "ffmpeg",
 		"-i", videoPath,
 		"-vf", "fps=1",
 		"-y",
@@ -27,6 +59,12 @@ func ProcessVideo(videoPath, timestamp string) models.ProcessingResult {
 	)
 
 	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return models.ProcessingResult{
+			Success: false,
+			Message: "Processamento cancelado: " + context.Cause(ctx).Error(),
+		}
+	}
 	if err != nil {
 		return models.ProcessingResult{
 			Success: false,
@@ -34,7 +72,7 @@ func ProcessVideo(videoPath, timestamp string) models.ProcessingResult {
 		}
 	}
 
-	frames, err := filepath.Glob(filepath.Join(tempDir, "*.png"))
+	frames, err := filepath.Glob(filepath.Join(tempDir, "*."+frameFormat))
 	if err != nil || len(frames) == 0 {
 		return models.ProcessingResult{
 			Success: false,
@@ -44,10 +82,14 @@ func ProcessVideo(videoPath, timestamp string) models.ProcessingResult {
 
 	fmt.Printf("📸 Extraídos %d frames\n", len(frames))
 
-	zipFilename := fmt.Sprintf("frames_%s.zip", timestamp)
-	zipPath := filepath.Join("outputs", zipFilename)
+	zipFilename := fmt.Sprintf("frames_%s.zip", jobID)
+	zipPath := filepath.Join(zipDir, zipFilename)
 
-	err = CreateZipFile(frames, zipPath)
+	archiveSHA256, err := createZipFile(frames, zipPath)
+	if err == nil && ctx.Err() != nil {
+		os.Remove(zipPath)
+		err = context.Cause(ctx)
+	}
 	if err != nil {
 		return models.ProcessingResult{
 			Success: false,
@@ -62,53 +104,98 @@ func ProcessVideo(videoPath, timestamp string) models.ProcessingResult {
 		imageNames[i] = filepath.Base(frame)
 	}
 
+	duration, version := parseFFmpegOutput(string(output))
 	return models.ProcessingResult{
-		Success:    true,
-		Message:    fmt.Sprintf("Processamento concluído! %d frames extraídos.", len(frames)),
-		ZipPath:    zipFilename,
-		FrameCount: len(frames),
-		Images:     imageNames,
+		Success:     true,
+		Message:     fmt.Sprintf("Processamento concluído! %d frames extraídos.", len(frames)),
+		ZipPath:     zipFilename,
+		FrameCount:  len(frames),
+		Images:      imageNames,
+		Duration:    duration,
+		ToolVersion: version,
+		SHA256:      archiveSHA256,
+	}
+}
+
+var (
+	ffmpegVersionRe  = regexp.MustCompile(`ffmpeg version (\S+)`)
+	ffmpegDurationRe = regexp.MustCompile(`Duration: (\d+):(\d{2}):(\d{2}(?:\.\d+)?)`)
+)
+
+// Duração do vídeo (segundos) e versão do ffmpeg a partir da saída do ffmpeg
+func parseFFmpegOutput(output string) (float64, string) {
+	var version string
+	if match := ffmpegVersionRe.FindStringSubmatch(output); match != nil {
+		version = "ffmpeg " + match[1]
+	}
+	var duration float64
+	if match := ffmpegDurationRe.FindStringSubmatch(output); match != nil {
+		hours, _ := strconv.Atoi(match[1])
+		minutes, _ := strconv.Atoi(match[2])
+		seconds, _ := strconv.ParseFloat(match[3], 64)
+		duration = float64(hours*3600+minutes*60) + seconds
 	}
+	return duration, version
 }
 
 func CreateZipFile(files []string, zipPath string) error {
+	_, err := createZipFile(files, zipPath)
+	return err
+}
+
+// Gravar o ZIP com os arquivos e um SHA256SUMS; retorna o SHA-256 (hex) do
+// próprio ZIP, calculado enquanto ele é gravado
+func createZipFile(files []string, zipPath string) (string, error) {
 	if len(files) == 0 {
-		return fmt.Errorf("lista de arquivos vazia")
+		return "", fmt.Errorf("lista de arquivos vazia")
 	}
 	zipFile, err := os.Create(zipPath)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer zipFile.Close()
 
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
+	archiveHash := sha256.New()
+	zipWriter := zip.NewWriter(io.MultiWriter(zipFile, archiveHash))
 
+	var sums strings.Builder
 	for _, file := range files {
-		err := addFileToZip(zipWriter, file)
+		sum, err := addFileToZip(zipWriter, file)
 		if err != nil {
-			return err
+			return "", err
 		}
+		fmt.Fprintf(&sums, "%s  %s\n", sum, filepath.Base(file))
 	}
 
-	return nil
+	writer, err := zipWriter.Create(checksumsFilename)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(writer, sums.String()); err != nil {
+		return "", err
+	}
+	if err := zipWriter.Close(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(archiveHash.Sum(nil)), nil
 }
 
-func addFileToZip(zipWriter *zip.Writer, filename string) error {
+// Adicionar o arquivo ao ZIP e retornar seu SHA-256 (hex)
+func addFileToZip(zipWriter *zip.Writer, filename string) (string, error) {
 	file, err := os.Open(filename)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer file.Close()
 
 	info, err := file.Stat()
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	header, err := zip.FileInfoHeader(info)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	header.Name = filepath.Base(filename)
@@ -116,9 +203,12 @@ func addFileToZip(zipWriter *zip.Writer, filename string) error {
 
 	writer, err := zipWriter.CreateHeader(header)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	_, err = io.Copy(writer, file)
-	return err
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(writer, hash), file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
@@ -2,7 +2,11 @@ package services
 
 import (
 	"archive/zip"
+	"context"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -24,6 +28,19 @@ func TestProcessVideo_Sucesso(t *testing.T) {
 	}
 }
 
+func TestProcessVideoContext_Cancelado(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errJobCancelled)
+
+	result := ProcessVideoContext(ctx, "arquivo_invalido.mp4", "20220101_000001")
+	if result.Success {
+		t.Error("Esperado falha para processamento cancelado")
+	}
+	if !strings.Contains(result.Message, "cancelado") {
+		t.Errorf("Esperado mensagem de cancelamento, obtido '%s'", result.Message)
+	}
+}
+
 func TestCreateZipFile_Empty(t *testing.T) {
 	zipPath := "outputs/test_empty.zip"
 	files := []string{}
@@ -58,7 +75,7 @@ func TestAddFileToZip_InvalidFile(t *testing.T) {
 	zipWriter := zip.NewWriter(zipFile)
 	defer zipWriter.Close()
 
-	err = addFileToZip(zipWriter, "arquivo_inexistente.txt")
+	_, err = addFileToZip(zipWriter, "arquivo_inexistente.txt")
 	if err == nil {
 		t.Error("Esperado erro ao adicionar arquivo inexistente ao zip")
 	}
@@ -75,7 +92,7 @@ func TestAddFileToZip_ErroAoAbrir(t *testing.T) {
 	zipWriter := zip.NewWriter(zipFile)
 	defer zipWriter.Close()
 
-	err = addFileToZip(zipWriter, "arquivo_inexistente.txt")
+	_, err = addFileToZip(zipWriter, "arquivo_inexistente.txt")
 	if err == nil {
 		t.Error("Esperado erro ao adicionar arquivo inexistente ao zip")
 	}
@@ -97,7 +114,7 @@ func TestAddFileToZip_ErroAoCriarHeader(t *testing.T) {
 	os.WriteFile(file, []byte("conteudo"), 0000)
 	defer os.Remove(file)
 
-	err = addFileToZip(zipWriter, file)
+	_, err = addFileToZip(zipWriter, file)
 	if err == nil {
 		t.Error("Esperado erro ao criar header do arquivo no zip")
 	}
@@ -134,3 +151,50 @@ func TestCreateZipFile_ErroAoCriarArquivo(t *testing.T) {
 	}
 	os.Remove(zipPath)
 }
+
+func TestParseFFmpegOutput(t *testing.T) {
+	output := "ffmpeg version 6.1.1-3ubuntu5 Copyright (c) 2000-2023 the FFmpeg developers\n" +
+		"Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'video.mp4':\n" +
+		"  Duration: 00:01:02.50, start: 0.000000, bitrate: 1205 kb/s\n"
+	duration, version := parseFFmpegOutput(output)
+	if duration != 62.5 {
+		t.Errorf("Esperado 62.5s, obtido %v", duration)
+	}
+	if version != "ffmpeg 6.1.1-3ubuntu5" {
+		t.Errorf("Esperado 'ffmpeg 6.1.1-3ubuntu5', obtido '%s'", version)
+	}
+
+	if duration, version := parseFFmpegOutput("saída inesperada"); duration != 0 || version != "" {
+		t.Errorf("Esperado valores vazios, obtido %v '%s'", duration, version)
+	}
+}
+
+func TestCreateZipFile_SHA256SUMS(t *testing.T) {
+	dir := t.TempDir()
+	frame := filepath.Join(dir, "frame_0001.png")
+	os.WriteFile(frame, []byte("abc"), 0644)
+	zipPath := filepath.Join(dir, "frames.zip")
+
+	archiveSHA256, err := createZipFile([]string{frame}, zipPath)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if sum, _ := fileChecksum(zipPath, "SHA256"); sha256Checksum(archiveSHA256) != sum {
+		t.Errorf("Esperado SHA-256 do ZIP gravado, obtido %s", archiveSHA256)
+	}
+
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("Erro ao abrir ZIP: %v", err)
+	}
+	defer reader.Close()
+	sums, err := reader.Open(checksumsFilename)
+	if err != nil {
+		t.Fatalf("Esperado %s no ZIP: %v", checksumsFilename, err)
+	}
+	data, _ := io.ReadAll(sums)
+	expected := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad  frame_0001.png\n"
+	if string(data) != expected {
+		t.Errorf("Esperado '%s', obtido '%s'", expected, data)
+	}
+}
@@ -0,0 +1,357 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Headers das chamadas de webhook. A assinatura é
+// "sha256=" + hex(HMAC-SHA256(WEBHOOK_SECRET, timestamp + "." + corpo)),
+// onde timestamp é o valor de X-Webhook-Timestamp (unix, segundos).
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+)
+
+// Espera entre tentativas de entrega
+const (
+	webhookBackoffMin = time.Second
+	webhookBackoffMax = 30 * time.Second
+)
+
+// ErrReplayInProgress indica que já há um reenvio de webhooks em andamento
+var ErrReplayInProgress = errors.New("reenvio de webhooks já em andamento")
+
+// Entrega de resultados via POST assinado para uma URL de callback
+type webhookPublisher struct {
+	client      *http.Client
+	secret      string
+	maxAttempts int
+	backoffMin  time.Duration
+	backoffMax  time.Duration
+	failedDir   string
+	storeFailed bool // Guardar em failedDir ao esgotar as tentativas (com outbox, ver storeAbandonedWebhook)
+
+	pending   sync.WaitGroup // Novas tentativas em background (ver Publish)
+	stopping  chan struct{}  // Fechado por wait para interromper as novas tentativas
+	stopOnce  sync.Once
+	replaying sync.Mutex // Um reenvio de failedDir por vez
+}
+
+// Callback que falhou definitivamente, guardado para reenvio (ver ReplayWebhooks)
+type failedWebhook struct {
+	URL           string          `json:"url"`
+	Body          json.RawMessage `json:"body"`
+	CorrelationID string          `json:"correlationId,omitempty"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"lastError"`
+	FailedAt      string          `json:"failedAt"`
+}
+
+// Erro que não deve ser repetido (ex: 4xx do destino)
+type permanentWebhookError struct {
+	err error
+}
+
+func (e *permanentWebhookError) Error() string { return e.err.Error() }
+
+type callbackURLKey struct{}
+
+// URL de callback do job em processamento
+func withCallbackURL(ctx context.Context, callbackURL string) context.Context {
+	return context.WithValue(ctx, callbackURLKey{}, callbackURL)
+}
+
+// URL de callback indicada na mensagem do job (vazia se não houver)
+func callbackURLFrom(ctx context.Context) string {
+	callbackURL, _ := ctx.Value(callbackURLKey{}).(string)
+	return callbackURL
+}
+
+// Callbacks por mensagem só podem apontar para o host do WEBHOOK_URL ou
+// para hosts da allow-list, evitando que mensagens disparem requisições arbitrárias
+func (c MessageProcessorConfig) isCallbackAllowed(callbackURL string) error {
+	u, err := url.Parse(callbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("URL de callback inválida: %s", callbackURL)
+	}
+	if global, err := url.Parse(c.WebhookURL); err == nil && c.WebhookURL != "" && strings.EqualFold(global.Host, u.Host) {
+		return nil
+	}
+	for _, host := range c.WebhookAllowedHosts {
+		if strings.EqualFold(host, u.Host) || strings.EqualFold(host, u.Hostname()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("host de callback não permitido: %s", u.Host)
+}
+
+// Publicador de webhooks, criado sob demanda a partir da configuração
+func (mp *MessageProcessor) webhooks() *webhookPublisher {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if mp.webhook == nil {
+		attempts := mp.config.WebhookMaxAttempts
+		if attempts <= 0 {
+			attempts = 1
+		}
+		mp.webhook = &webhookPublisher{
+			client:      newWebhookClient(mp.config.WebhookTimeout),
+			secret:      mp.config.WebhookSecret,
+			maxAttempts: attempts,
+			backoffMin:  webhookBackoffMin,
+			backoffMax:  webhookBackoffMax,
+			failedDir:   mp.config.WebhookFailedDir,
+			storeFailed: mp.config.OutboxDir == "",
+			stopping:    make(chan struct{}),
+		}
+	}
+	return mp.webhook
+}
+
+// Cliente HTTP que não segue redirecionamentos, que poderiam levar o
+// callback para um host fora da allow-list
+func newWebhookClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Assinatura HMAC-SHA256 do corpo
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Publish faz uma única tentativa de entrega, sem segurar o job com esperas.
+// Com o outbox habilitado, as novas tentativas são do relay; sem ele, seguem em
+// background até maxAttempts e, se todas falharem, o callback é guardado em
+// failedDir para reenvio
+func (w *webhookPublisher) Publish(ctx context.Context, callbackURL string, body []byte) error {
+	err := w.send(ctx, callbackURL, body)
+	if err == nil {
+		logf(ctx, "✅ Webhook entregue: %s", callbackURL)
+		return nil
+	}
+	var permanent *permanentWebhookError
+	if errors.As(err, &permanent) || !w.storeFailed || w.maxAttempts <= 1 {
+		return w.fail(ctx, callbackURL, body, 1, err)
+	}
+
+	logf(ctx, "🔁 Webhook falhou (tentativa 1/%d), novas tentativas em background: %v", w.maxAttempts, err)
+	w.pending.Add(1)
+	go func() {
+		defer w.pending.Done()
+		// Mantém o trace do job, mas não é interrompido com ele (só por wait)
+		retryCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		defer cancel()
+		go func() {
+			select {
+			case <-w.stopping:
+				cancel()
+			case <-retryCtx.Done():
+			}
+		}()
+
+		attempts, err := w.deliver(retryCtx, callbackURL, body, 2)
+		if err == nil {
+			logf(ctx, "✅ Webhook entregue: %s", callbackURL)
+			return
+		}
+		w.fail(ctx, callbackURL, body, attempts, err)
+	}()
+	return nil
+}
+
+// Registrar a falha definitiva da entrega
+func (w *webhookPublisher) fail(ctx context.Context, callbackURL string, body []byte, attempts int, err error) error {
+	logf(ctx, "❌ Webhook não entregue após %d tentativa(s): %v", attempts, err)
+	if w.storeFailed {
+		w.store(ctx, callbackURL, body, attempts, err.Error())
+	}
+	return fmt.Errorf("erro ao entregar webhook: %w", err)
+}
+
+// Guardar o callback em failedDir para reenvio (ver Replay)
+func (w *webhookPublisher) store(ctx context.Context, callbackURL string, body []byte, attempts int, lastError string) {
+	correlationID := ""
+	if tc, ok := traceFrom(ctx); ok {
+		correlationID = tc.CorrelationID
+	}
+	if err := w.saveFailed(failedWebhook{
+		URL:           callbackURL,
+		Body:          body,
+		CorrelationID: correlationID,
+		Attempts:      attempts,
+		LastError:     lastError,
+		FailedAt:      time.Now().UTC().Format(time.RFC3339),
+	}); err != nil {
+		logf(ctx, "❌ Erro ao guardar webhook para reenvio: %v", err)
+	}
+}
+
+// Aguardar as novas tentativas em background. Se ctx expirar, elas são
+// interrompidas e os callbacks não entregues vão para failedDir
+func (w *webhookPublisher) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		w.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		if w.stopping != nil {
+			w.stopOnce.Do(func() { close(w.stopping) })
+		}
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Tentar a entrega da tentativa first até maxAttempts, com espera antes de cada
+// nova tentativa; retorna o número de tentativas feitas
+func (w *webhookPublisher) deliver(ctx context.Context, callbackURL string, body []byte, first int) (int, error) {
+	wait := newBackoff(w.backoffMin, w.backoffMax)
+	err := fmt.Errorf("nenhuma tentativa restante para %s", callbackURL)
+	for attempt := first; attempt <= w.maxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return attempt - 1, ctx.Err()
+			case <-time.After(wait.Next()):
+			}
+		}
+		err = w.send(ctx, callbackURL, body)
+		if err == nil {
+			return attempt, nil
+		}
+		if _, permanent := err.(*permanentWebhookError); permanent || attempt == w.maxAttempts {
+			return attempt, err
+		}
+		logf(ctx, "🔁 Webhook falhou (tentativa %d/%d): %v", attempt, w.maxAttempts, err)
+	}
+	return w.maxAttempts, err
+}
+
+func (w *webhookPublisher) send(ctx context.Context, callbackURL string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return &permanentWebhookError{err: err}
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	if w.secret != "" {
+		req.Header.Set(WebhookSignatureHeader, signWebhook(w.secret, timestamp, body))
+	}
+	if tc, ok := traceFrom(ctx); ok {
+		req.Header.Set("X-Correlation-Id", tc.CorrelationID)
+		req.Header.Set(TraceParentAttribute, tc.TraceParent())
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("status %d de %s", resp.StatusCode, callbackURL)
+	// 4xx indica problema no destino ou no payload: repetir não resolve
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return &permanentWebhookError{err: err}
+	}
+	return err
+}
+
+func (w *webhookPublisher) saveFailed(failed failedWebhook) error {
+	if err := os.MkdirAll(w.failedDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(failed, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%020d_%s.json", time.Now().UnixNano(), randomHex(4))
+	return os.WriteFile(filepath.Join(w.failedDir, name), data, 0644)
+}
+
+// Replay reenvia os callbacks guardados; os entregues são removidos. Só um
+// reenvio roda por vez, para que o mesmo callback não seja enviado em dobro
+func (w *webhookPublisher) Replay(ctx context.Context) (delivered, failed int, err error) {
+	if !w.replaying.TryLock() {
+		return 0, 0, ErrReplayInProgress
+	}
+	defer w.replaying.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(w.failedDir, "*.json"))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var entry failedWebhook
+		if err := json.Unmarshal(data, &entry); err != nil {
+			log.Printf("⚠️ Webhook guardado inválido ignorado: %s", file)
+			continue
+		}
+
+		jobCtx := ctx
+		if entry.CorrelationID != "" {
+			jobCtx = withTrace(ctx, traceFromAttributes(map[string]string{CorrelationIDAttribute: entry.CorrelationID}))
+		}
+		attempts, err := w.deliver(jobCtx, entry.URL, entry.Body, 1)
+		entry.Attempts += attempts
+		if err != nil {
+			failed++
+			entry.LastError = err.Error()
+			entry.FailedAt = time.Now().UTC().Format(time.RFC3339)
+			if data, err := json.MarshalIndent(entry, "", "  "); err == nil {
+				os.WriteFile(file, data, 0644)
+			}
+			continue
+		}
+		delivered++
+		os.Remove(file)
+	}
+
+	log.Printf("🔁 Reenvio de webhooks: %d entregue(s), %d com falha", delivered, failed)
+	return delivered, failed, nil
+}
+
+// ReplayWebhooks reenvia os callbacks que falharam definitivamente
+func (mp *MessageProcessor) ReplayWebhooks(ctx context.Context) (delivered, failed int, err error) {
+	return mp.webhooks().Replay(ctx)
+}
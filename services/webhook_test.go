@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestWebhookPublisher(t *testing.T, attempts int) *webhookPublisher {
+	return &webhookPublisher{
+		client:      newWebhookClient(time.Second),
+		secret:      "segredo",
+		maxAttempts: attempts,
+		backoffMin:  time.Millisecond,
+		backoffMax:  time.Millisecond,
+		failedDir:   filepath.Join(t.TempDir(), "failed"),
+		storeFailed: true,
+		stopping:    make(chan struct{}),
+	}
+}
+
+func TestWebhookPublisher_AssinaCorpo(t *testing.T) {
+	var signature, timestamp, correlation string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp = r.Header.Get(WebhookTimestampHeader)
+		correlation = r.Header.Get("X-Correlation-Id")
+		if r.Header.Get(WebhookSignatureHeader) == signWebhook("segredo", timestamp, body) {
+			signature = "ok"
+		}
+	}))
+	defer server.Close()
+
+	w := newTestWebhookPublisher(t, 1)
+	ctx := withTrace(context.Background(), traceFromAttributes(map[string]string{CorrelationIDAttribute: "corr-1"}))
+	if err := w.Publish(ctx, server.URL, []byte(`{"status":"COMPLETED"}`)); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if signature != "ok" {
+		t.Error("Esperado assinatura válida no header")
+	}
+	if timestamp == "" {
+		t.Error("Esperado header de timestamp")
+	}
+	if correlation != "corr-1" {
+		t.Errorf("Esperado correlation id 'corr-1', obtido '%s'", correlation)
+	}
+}
+
+func TestWebhookPublisher_RepeteAposErro5xx(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	w := newTestWebhookPublisher(t, 5)
+	if err := w.Publish(context.Background(), server.URL, []byte(`{}`)); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	w.wait(context.Background())
+	if calls != 3 {
+		t.Errorf("Esperado 3 tentativas, obtido %d", calls)
+	}
+}
+
+func TestWebhookPublisher_Erro4xxNaoRepete(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	w := newTestWebhookPublisher(t, 5)
+	if err := w.Publish(context.Background(), server.URL, []byte(`{}`)); err == nil {
+		t.Fatal("Esperado erro para status 400")
+	}
+	if calls != 1 {
+		t.Errorf("Esperado 1 tentativa, obtido %d", calls)
+	}
+	files, _ := filepath.Glob(filepath.Join(w.failedDir, "*.json"))
+	if len(files) != 1 {
+		t.Errorf("Esperado 1 webhook guardado para reenvio, obtido %d", len(files))
+	}
+}
+
+func TestWebhookPublisher_ReplayEntregaERemove(t *testing.T) {
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	w := newTestWebhookPublisher(t, 2)
+	w.Publish(context.Background(), server.URL, []byte(`{}`))
+	w.wait(context.Background())
+
+	delivered, failed, err := w.Replay(context.Background())
+	if err != nil || delivered != 0 || failed != 1 {
+		t.Errorf("Esperado 0 entregue(s) e 1 falha, obtido %d/%d (%v)", delivered, failed, err)
+	}
+
+	healthy.Store(true)
+	delivered, failed, err = w.Replay(context.Background())
+	if err != nil || delivered != 1 || failed != 0 {
+		t.Errorf("Esperado 1 entregue e 0 falhas, obtido %d/%d (%v)", delivered, failed, err)
+	}
+	files, _ := filepath.Glob(filepath.Join(w.failedDir, "*.json"))
+	if len(files) != 0 {
+		t.Errorf("Esperado diretório de falhas vazio, obtido %d arquivo(s)", len(files))
+	}
+}
+
+func TestWebhookPublisher_UmaTentativaNoJob(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	w := newTestWebhookPublisher(t, 5)
+	w.backoffMin, w.backoffMax = time.Hour, time.Hour
+	if err := w.Publish(context.Background(), server.URL, []byte(`{}`)); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Esperado 1 tentativa antes de liberar o job, obtido %d", n)
+	}
+
+	// No shutdown, as novas tentativas são interrompidas e o callback é guardado
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := w.wait(ctx); err == nil {
+		t.Error("Esperado erro ao interromper as novas tentativas")
+	}
+	files, _ := filepath.Glob(filepath.Join(w.failedDir, "*.json"))
+	if len(files) != 1 {
+		t.Errorf("Esperado 1 webhook guardado para reenvio, obtido %d", len(files))
+	}
+}
+
+func TestWebhookPublisher_NaoSegueRedirecionamento(t *testing.T) {
+	var followed atomic.Bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed.Store(true)
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	w := newTestWebhookPublisher(t, 1)
+	if err := w.Publish(context.Background(), server.URL, []byte(`{}`)); err == nil {
+		t.Error("Esperado erro com redirecionamento do destino")
+	}
+	if followed.Load() {
+		t.Error("Esperado redirecionamento não seguido")
+	}
+}
+
+func TestWebhookPublisher_ReplayConcorrenteRecusado(t *testing.T) {
+	w := newTestWebhookPublisher(t, 1)
+	w.replaying.Lock()
+	defer w.replaying.Unlock()
+
+	if _, _, err := w.Replay(context.Background()); !errors.Is(err, ErrReplayInProgress) {
+		t.Errorf("Esperado ErrReplayInProgress, obtido %v", err)
+	}
+}
+
+func TestIsCallbackAllowed(t *testing.T) {
+	config := MessageProcessorConfig{
+		WebhookURL:          "https://api.exemplo.com/callback",
+		WebhookAllowedHosts: []string{"hooks.cliente.com"},
+	}
+	allowed := []string{"https://api.exemplo.com/outro", "https://hooks.cliente.com/x", "http://HOOKS.cliente.com:8080/x"}
+	for _, u := range allowed {
+		if err := config.isCallbackAllowed(u); err != nil {
+			t.Errorf("Esperado callback permitido para '%s', obtido %v", u, err)
+		}
+	}
+	denied := []string{"https://outro.com/x", "ftp://hooks.cliente.com/x", "hooks.cliente.com/x", "http://169.254.169.254/"}
+	for _, u := range denied {
+		if err := config.isCallbackAllowed(u); err == nil {
+			t.Errorf("Esperado callback recusado para '%s'", u)
+		}
+	}
+}
+
+func TestSendProcessingResult_SomenteWebhook(t *testing.T) {
+	var received int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+	}))
+	defer server.Close()
+
+	mp := &MessageProcessor{config: MessageProcessorConfig{WebhookURL: server.URL, WebhookMaxAttempts: 1, WebhookTimeout: time.Second, WebhookFailedDir: t.TempDir()}}
+	if err := mp.SendProcessingResult(context.Background(), "proc-1", "processed/proc-1.zip", "COMPLETED"); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if received != 1 {
+		t.Errorf("Esperado 1 chamada ao webhook, obtido %d", received)
+	}
+}
+
+func TestWebhooks_ComOutboxNaoGuardaFalhas(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	failedDir := t.TempDir()
+	mp := &MessageProcessor{config: MessageProcessorConfig{
+		WebhookURL:         server.URL,
+		WebhookMaxAttempts: 5,
+		WebhookTimeout:     time.Second,
+		WebhookFailedDir:   failedDir,
+		OutboxDir:          filepath.Join(t.TempDir(), "outbox"),
+	}}
+	mp.SendProcessingResult(context.Background(), "proc-1", "", "IN_PROGRESS")
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Esperado 1 tentativa no job (o relay repete), obtido %d", n)
+	}
+	mp.relayOutbox(context.Background(), mp.outbox())
+	mp.webhooks().wait(context.Background())
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("Esperado 1 tentativa por passada do relay, obtido %d", n)
+	}
+	if files, _ := filepath.Glob(filepath.Join(failedDir, "*.json")); len(files) != 0 {
+		t.Errorf("Esperado reenvio apenas pelo outbox, obtido %d webhook(s) guardado(s)", len(files))
+	}
+}
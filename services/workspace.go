@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Prefixo dos diretórios de workspace dentro de TEMP_DIR
+const workspacePrefix = "job-"
+
+// Arquivo de lock do workspace: o job mantém um lock exclusivo enquanto roda,
+// liberado pelo sistema operacional se o processo morrer (ver workspace_janitor.go)
+const workspaceLockFile = ".lock"
+
+// errWorkspaceLocked indica que o lock do workspace pertence a um job em andamento
+var errWorkspaceLocked = errors.New("workspace em uso")
+
+// Jobs com workspace aberto neste processo
+var (
+	activeMu   sync.Mutex
+	activeJobs = make(map[string]bool)
+)
+
+// NewJobID gera o identificador único de um job: data/hora (UTC, para manter
+// os nomes ordenáveis) e um sufixo aleatório que evita colisões entre jobs
+// iniciados no mesmo segundo
+func NewJobID() string {
+	return time.Now().UTC().Format("20060102_150405") + "_" + randomHex(4)
+}
+
+// Diretório de trabalho isolado de um job (<TEMP_DIR>/job-<jobID>): vídeo
+// baixado, frames extraídos e ZIP ainda não enviado. Removido inteiro ao fim do job
+type Workspace struct {
+	JobID string
+	Dir   string
+
+	lock *os.File
+}
+
+// Dono do workspace, gravado no arquivo de lock para diagnóstico
+type workspaceOwner struct {
+	JobID     string    `json:"jobId"`
+	Hostname  string    `json:"hostname"`
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// Caminho do workspace de um job
+func workspaceDir(jobID string) string {
+	return filepath.Join(Scratch().Temp, workspacePrefix+jobID)
+}
+
+// NewWorkspace cria o workspace do job e adquire seu lock; falha se o diretório
+// já existir, para que dois jobs nunca compartilhem arquivos
+func NewWorkspace(jobID string) (*Workspace, error) {
+	dir := workspaceDir(jobID)
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar workspace: %w", err)
+	}
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar workspace: %w", err)
+	}
+
+	lock, err := os.OpenFile(filepath.Join(dir, workspaceLockFile), os.O_CREATE|os.O_RDWR, 0644)
+	if err == nil {
+		err = lockFile(lock)
+		if errors.Is(err, errors.ErrUnsupported) {
+			err = nil
+		}
+	}
+	if err != nil {
+		if lock != nil {
+			lock.Close()
+		}
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("erro ao adquirir lock do workspace: %w", err)
+	}
+	hostname, _ := os.Hostname()
+	json.NewEncoder(lock).Encode(workspaceOwner{JobID: jobID, Hostname: hostname, PID: os.Getpid(), StartedAt: time.Now().UTC()})
+
+	activeMu.Lock()
+	activeJobs[jobID] = true
+	activeMu.Unlock()
+	return &Workspace{JobID: jobID, Dir: dir, lock: lock}, nil
+}
+
+// Path retorna o caminho de um arquivo dentro do workspace
+func (w *Workspace) Path(name string) string {
+	return filepath.Join(w.Dir, filepath.Base(name))
+}
+
+// Cleanup remove o workspace e tudo o que estiver nele, liberando o lock só
+// depois da remoção. Chamado com defer, também roda quando o job entra em panic
+func (w *Workspace) Cleanup() {
+	if err := os.RemoveAll(w.Dir); err != nil {
+		log.Printf("⚠️ Erro ao remover workspace %s: %v", w.Dir, err)
+	}
+	if w.lock != nil {
+		w.lock.Close()
+		w.lock = nil
+	}
+	activeMu.Lock()
+	delete(activeJobs, w.JobID)
+	activeMu.Unlock()
+}
+
+// Job com workspace aberto neste processo
+func isJobActive(jobID string) bool {
+	activeMu.Lock()
+	defer activeMu.Unlock()
+	return activeJobs[jobID]
+}
+
+type workspaceKey struct{}
+
+// Workspace do job em processamento
+func withWorkspace(ctx context.Context, ws *Workspace) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, ws)
+}
+
+func workspaceFrom(ctx context.Context) (*Workspace, bool) {
+	ws, ok := ctx.Value(workspaceKey{}).(*Workspace)
+	return ws, ok
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"video-processor/utils"
+)
+
+// Arquivos de UPLOADS_DIR criados por um job: <jobID>_<arquivo> (ver NewJobID)
+var uploadJobIDRe = regexp.MustCompile(`^(\d{8}_\d{6}_[0-9a-f]{8})_`)
+
+// Limpeza de arquivos órfãos: jobs interrompidos (ex: pod encerrado por OOM)
+// deixam frames, vídeos baixados e ZIPs não enviados nos diretórios de trabalho
+type WorkspaceJanitorConfig struct {
+	Interval time.Duration // Intervalo entre as limpezas (0 = apenas na inicialização)
+	MinAge   time.Duration // Idade mínima do que é removido: mais novo, pode ser de um job começando
+}
+
+// LoadWorkspaceJanitorConfig lê JANITOR_INTERVAL e JANITOR_MIN_AGE
+func LoadWorkspaceJanitorConfig() WorkspaceJanitorConfig {
+	return WorkspaceJanitorConfig{
+		Interval: utils.GetEnvDuration("JANITOR_INTERVAL", 10*time.Minute),
+		MinAge:   utils.GetEnvDuration("JANITOR_MIN_AGE", 15*time.Minute),
+	}
+}
+
+// StartWorkspaceJanitor limpa os órfãos imediatamente (recuperação após uma
+// queda) e depois a cada Interval, até o ctx ser cancelado
+func StartWorkspaceJanitor(ctx context.Context, config WorkspaceJanitorConfig) {
+	reclaimOrphans(config.MinAge)
+	if config.Interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reclaimOrphans(config.MinAge)
+			}
+		}
+	}()
+}
+
+// Uma passada pelos diretórios de trabalho. Um workspace é órfão se for mais
+// antigo que minAge e seu lock puder ser adquirido (o processo dono terminou)
+// ou não existir. Um arquivo <jobID>_* em UPLOADS_DIR é órfão se for mais
+// antigo que minAge e o workspace do job não estiver em uso.
+// Retorna os itens e bytes recuperados
+func reclaimOrphans(minAge time.Duration) (removed int, reclaimed int64) {
+	dirs := Scratch()
+	cutoff := time.Now().Add(-minAge)
+
+	reclaim := func(kind, path string, size int64) {
+		if err := os.RemoveAll(path); err != nil {
+			log.Printf("⚠️ Erro ao remover órfão %s: %v", path, err)
+			return
+		}
+		removed++
+		reclaimed += size
+		janitorReclaimedTotal.WithLabelValues(kind).Inc()
+		janitorReclaimedBytes.WithLabelValues(kind).Add(float64(size))
+	}
+
+	entries, _ := os.ReadDir(dirs.Temp)
+	for _, entry := range entries {
+		jobID, ok := strings.CutPrefix(entry.Name(), workspacePrefix)
+		if !ok || !entry.IsDir() || isJobActive(jobID) {
+			continue
+		}
+		dir := filepath.Join(dirs.Temp, entry.Name())
+		if workspaceOrphaned(dir, cutoff) {
+			reclaim("temp", dir, pathSize(dir))
+		}
+	}
+
+	entries, _ = os.ReadDir(dirs.Uploads)
+	for _, entry := range entries {
+		match := uploadJobIDRe.FindStringSubmatch(entry.Name())
+		if match == nil || entry.IsDir() || isJobActive(match[1]) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) || workspaceLocked(workspaceDir(match[1])) {
+			continue
+		}
+		reclaim("uploads", filepath.Join(dirs.Uploads, entry.Name()), info.Size())
+	}
+
+	if removed > 0 {
+		log.Printf("🧹 Limpeza de órfãos: %d item(ns) removido(s), %s recuperados", removed, formatBytes(uint64(reclaimed)))
+	}
+	return removed, reclaimed
+}
+
+// Workspace sem dono: anterior ao cutoff (NewWorkspace só trava após criá-lo)
+// e com o lock livre, sem arquivo de lock ou sem suporte a lock na plataforma
+func workspaceOrphaned(dir string, cutoff time.Time) bool {
+	info, err := os.Stat(dir)
+	if err != nil || !info.ModTime().Before(cutoff) {
+		return false
+	}
+	lock, err := os.OpenFile(filepath.Join(dir, workspaceLockFile), os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		return true
+	}
+	if err != nil {
+		return false
+	}
+	defer lock.Close()
+	err = lockFile(lock)
+	return err == nil || errors.Is(err, errors.ErrUnsupported)
+}
+
+// Workspace existente com o lock mantido por um job em andamento
+func workspaceLocked(dir string) bool {
+	lock, err := os.OpenFile(filepath.Join(dir, workspaceLockFile), os.O_RDWR, 0)
+	if err != nil {
+		return false
+	}
+	defer lock.Close()
+	return lockFile(lock) == errWorkspaceLocked
+}
+
+// Tamanho total de um arquivo ou diretório
+func pathSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Valor atual de um contador
+func counterValue(c prometheus.Counter) float64 {
+	var m dto.Metric
+	c.Write(&m)
+	return m.GetCounter().GetValue()
+}
+
+// Deixar o arquivo ou diretório com a idade indicada
+func age(path string, d time.Duration) {
+	old := time.Now().Add(-d)
+	os.Chtimes(path, old, old)
+}
+
+func TestReclaimOrphans_WorkspaceEmUsoPreservado(t *testing.T) {
+	useScratchDirs(t)
+	ws, _ := NewWorkspace(NewJobID())
+	defer ws.Cleanup()
+	age(ws.Dir, time.Hour)
+
+	if removed, _ := reclaimOrphans(time.Minute); removed != 0 {
+		t.Errorf("Esperado workspace em uso preservado, obtido %d removido(s)", removed)
+	}
+	if _, err := os.Stat(ws.Dir); err != nil {
+		t.Errorf("Workspace em uso não deveria ser removido: %v", err)
+	}
+}
+
+func TestReclaimOrphans_LockLiberado(t *testing.T) {
+	dirs := useScratchDirs(t)
+	// Workspace de um processo que morreu: arquivo de lock sem lock mantido
+	dir := filepath.Join(dirs.Temp, workspacePrefix+"20260101_000000_deadbeef")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, workspaceLockFile), []byte("{}"), 0644)
+	os.WriteFile(filepath.Join(dir, "frame_0001.png"), make([]byte, 100), 0644)
+	age(dir, 2*time.Hour)
+
+	before := counterValue(janitorReclaimedBytes.WithLabelValues("temp"))
+	removed, reclaimed := reclaimOrphans(time.Hour)
+	if removed != 1 || reclaimed != 102 {
+		t.Errorf("Esperado 1 workspace e 102 bytes recuperados, obtido %d e %d", removed, reclaimed)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Esperado workspace órfão removido, obtido %v", err)
+	}
+	if got := counterValue(janitorReclaimedBytes.WithLabelValues("temp")) - before; got != 102 {
+		t.Errorf("Esperado métrica com 102 bytes, obtido %v", got)
+	}
+}
+
+func TestReclaimOrphans_LockLiberadoRecentePreservado(t *testing.T) {
+	dirs := useScratchDirs(t)
+	// Workspace de outro processo entre o Mkdir e o flock de NewWorkspace
+	dir := filepath.Join(dirs.Temp, workspacePrefix+"20260101_000000_0badf00d")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, workspaceLockFile), nil, 0644)
+
+	if removed, _ := reclaimOrphans(time.Hour); removed != 0 {
+		t.Errorf("Esperado workspace recente preservado, obtido %d removido(s)", removed)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("Workspace recente não deveria ser removido: %v", err)
+	}
+}
+
+func TestReclaimOrphans_SemLockRespeitaIdade(t *testing.T) {
+	dirs := useScratchDirs(t)
+	dir := filepath.Join(dirs.Temp, workspacePrefix+"20260101_000000_cafebabe")
+	os.MkdirAll(dir, 0755)
+
+	if removed, _ := reclaimOrphans(time.Hour); removed != 0 {
+		t.Error("Workspace recente sem lock pode ser de um job começando")
+	}
+	age(dir, 2*time.Hour)
+	if removed, _ := reclaimOrphans(time.Hour); removed != 1 {
+		t.Error("Esperado workspace antigo sem lock removido")
+	}
+}
+
+func TestReclaimOrphans_Uploads(t *testing.T) {
+	dirs := useScratchDirs(t)
+	orphan := filepath.Join(dirs.Uploads, "20260101_000000_deadbeef_video.mp4")
+	recent := filepath.Join(dirs.Uploads, "20260101_000000_cafebabe_video.mp4")
+	other := filepath.Join(dirs.Uploads, "manual.mp4")
+	for _, path := range []string{orphan, recent, other} {
+		os.WriteFile(path, []byte("video"), 0644)
+	}
+	age(orphan, 2*time.Hour)
+	age(other, 2*time.Hour)
+
+	// Vídeo antigo de um job em andamento (workspace com lock mantido)
+	ws, _ := NewWorkspace(NewJobID())
+	defer ws.Cleanup()
+	active := filepath.Join(dirs.Uploads, ws.JobID+"_video.mp4")
+	os.WriteFile(active, []byte("video"), 0644)
+	age(active, 2*time.Hour)
+
+	removed, reclaimed := reclaimOrphans(time.Hour)
+	if removed != 1 || reclaimed != 5 {
+		t.Errorf("Esperado apenas o vídeo órfão removido, obtido %d (%d bytes)", removed, reclaimed)
+	}
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Error("Esperado vídeo órfão removido")
+	}
+	for _, path := range []string{recent, other, active} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Esperado %s preservado", path)
+		}
+	}
+}
+
+func TestStartWorkspaceJanitor_LimpaNaInicializacao(t *testing.T) {
+	dirs := useScratchDirs(t)
+	dir := filepath.Join(dirs.Temp, workspacePrefix+"20260101_000000_deadbeef")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, workspaceLockFile), nil, 0644)
+
+	StartWorkspaceJanitor(context.TODO(), WorkspaceJanitorConfig{})
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Esperado órfão removido na inicialização, obtido %v", err)
+	}
+}
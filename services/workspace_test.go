@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Usar diretórios de trabalho temporários durante o teste
+func useScratchDirs(t *testing.T) ScratchDirs {
+	original := Scratch()
+	root := t.TempDir()
+	dirs := ScratchDirs{
+		Uploads: filepath.Join(root, "uploads"),
+		Outputs: filepath.Join(root, "outputs"),
+		Temp:    filepath.Join(root, "temp"),
+	}
+	InitScratchDirs(dirs)
+	t.Cleanup(func() { InitScratchDirs(original) })
+	return dirs
+}
+
+func TestNewJobID_Unico(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := NewJobID()
+		if !regexp.MustCompile(`^\d{8}_\d{6}_[0-9a-f]{8}$`).MatchString(id) {
+			t.Fatalf("Formato inesperado: %s", id)
+		}
+		if seen[id] {
+			t.Fatalf("ID repetido no mesmo segundo: %s", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestNewWorkspace_Isolado(t *testing.T) {
+	dirs := useScratchDirs(t)
+	ws, err := NewWorkspace("job-a")
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	defer ws.Cleanup()
+	if ws.Dir != filepath.Join(dirs.Temp, "job-job-a") {
+		t.Errorf("Esperado workspace em TEMP_DIR, obtido %s", ws.Dir)
+	}
+	if _, err := NewWorkspace("job-a"); err == nil {
+		t.Error("Esperado erro ao reutilizar o workspace de outro job")
+	}
+	if ws.Path("../../fora.mp4") != filepath.Join(ws.Dir, "fora.mp4") {
+		t.Errorf("Esperado arquivo dentro do workspace, obtido %s", ws.Path("../../fora.mp4"))
+	}
+}
+
+func TestWorkspace_CleanupEmPanic(t *testing.T) {
+	useScratchDirs(t)
+	ws, _ := NewWorkspace(NewJobID())
+
+	func() {
+		defer func() { recover() }()
+		defer ws.Cleanup()
+		os.WriteFile(ws.Path("video.mp4"), []byte("video"), 0644)
+		panic("falha no job")
+	}()
+
+	if _, err := os.Stat(ws.Dir); !os.IsNotExist(err) {
+		t.Errorf("Esperado workspace removido após panic, obtido %v", err)
+	}
+}
+
+func TestDownloadFromS3_DentroDoWorkspace(t *testing.T) {
+	useScratchDirs(t)
+	root := t.TempDir()
+	mp := &MessageProcessor{config: MessageProcessorConfig{BlobBackend: BlobBackendDir, BlobDir: root}}
+	NewDirBlobStore(root).Put(context.TODO(), "videos", "a.mp4", strings.NewReader("video"), PutOptions{})
+	ws, _ := NewWorkspace(NewJobID())
+	defer ws.Cleanup()
+
+	localPath, err := mp.DownloadFromS3(withWorkspace(context.TODO(), ws), "videos", "a.mp4")
+	if err != nil || localPath != ws.Path("a.mp4") {
+		t.Errorf("Esperado download no workspace, obtido %s (%v)", localPath, err)
+	}
+}
+
+func TestDownloadFromS3_SemWorkspaceNomeUnico(t *testing.T) {
+	dirs := useScratchDirs(t)
+	root := t.TempDir()
+	mp := &MessageProcessor{config: MessageProcessorConfig{BlobBackend: BlobBackendDir, BlobDir: root}}
+	NewDirBlobStore(root).Put(context.TODO(), "videos", "a.mp4", strings.NewReader("video"), PutOptions{})
+
+	first, _ := mp.DownloadFromS3(context.TODO(), "videos", "a.mp4")
+	second, _ := mp.DownloadFromS3(context.TODO(), "videos", "a.mp4")
+	if first == second || filepath.Dir(first) != dirs.Uploads {
+		t.Errorf("Esperado nomes distintos em UPLOADS_DIR, obtido %s e %s", first, second)
+	}
+}
+
+func TestProcessVideo_RemoveWorkspace(t *testing.T) {
+	dirs := useScratchDirs(t)
+	ProcessVideo("arquivo_invalido.mp4", "job-teste")
+	if entries, _ := os.ReadDir(dirs.Temp); len(entries) != 0 {
+		t.Errorf("Esperado TEMP_DIR vazio após o processamento, obtido %v", entries)
+	}
+}
@@ -100,3 +100,17 @@ func GetEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	
 	return defaultValue
 }
+
+// GetEnvFloat retorna o valor decimal da variável de ambiente
+func GetEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+		return floatVal
+	}
+
+	return defaultValue
+}
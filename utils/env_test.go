@@ -105,3 +105,15 @@ func TestLoadEnv_FileComConteudo(t *testing.T) {
 		t.Errorf("Esperado '123', obtido '%s'", os.Getenv("OUTRO_ENV"))
 	}
 }
+
+func TestGetEnvFloat(t *testing.T) {
+	os.Setenv("FLOAT_ENV", "2.5")
+	if GetEnvFloat("FLOAT_ENV", 1) != 2.5 {
+		t.Errorf("Esperado 2.5, obtido %v", GetEnvFloat("FLOAT_ENV", 1))
+	}
+	os.Setenv("FLOAT_ENV", "abc")
+	if GetEnvFloat("FLOAT_ENV", 3) != 3 {
+		t.Errorf("Esperado default 3 para valor inválido, obtido %v", GetEnvFloat("FLOAT_ENV", 3))
+	}
+	os.Unsetenv("FLOAT_ENV")
+}